@@ -0,0 +1,112 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"unsafe"
+)
+
+// Allocator supplies the slices of the maps and arrays decoded by
+// UnmarshalJSON, such as from an arena.  Key strings, and the Go maps of maps
+// with more than 16 keys, are allocated by Go.
+type Allocator interface {
+	// Strings returns a []string of length 0 and capacity at least n.
+	Strings(n int) []string
+	// Values returns a []any of length 0 and capacity at least n.
+	Values(n int) []any
+}
+
+// WithAllocator makes UnmarshalJSON allocate the slices of decoded maps and
+// arrays, at any depth, with a.  Slices grown by later modifications are
+// allocated by Go.
+func WithAllocator(a Allocator) Option {
+	return func(opts *options) { opts.alloc = a }
+}
+
+// allocator returns the Allocator of o, or nil.
+func (o *OrderedMap) allocator() Allocator {
+	if o.opts == nil {
+		return nil
+	}
+	return o.opts.alloc
+}
+
+// reserve makes room with a for a key of o to be appended by set.
+func (o *OrderedMap) reserve(a Allocator) {
+	if len(o.keys) < cap(o.keys) || len(o.keys) >= smallSize || o.values != nil {
+		return
+	}
+	n := max(4, 2*cap(o.keys))
+	o.keys = append(a.Strings(n), o.keys...)
+	o.vals = append(a.Values(n), o.vals...)
+}
+
+// appendValue appends v to s, growing s with a if it is not nil.
+func appendValue(a Allocator, s []any, v any) []any {
+	if a != nil && len(s) == cap(s) {
+		s = append(a.Values(max(4, 2*cap(s))), s...)
+	}
+	return append(s, v)
+}
+
+// Estimated sizes of decoded values, for Limits.MaxMemory.
+const (
+	sizeofString = int64(unsafe.Sizeof(""))
+	sizeofAny    = int64(unsafe.Sizeof(any(nil)))
+	sizeofMap    = int64(unsafe.Sizeof(OrderedMap{}))
+	sizeofSlice  = int64(unsafe.Sizeof([]any(nil)))
+)
+
+// keyMemory returns the estimated memory of a decoded key and its value slot.
+func keyMemory(key string) int64 {
+	return sizeofString + int64(len(key)) + sizeofAny
+}
+
+// valueMemory returns the estimated memory of the decoded value of token t,
+// or for a delimiter, of its map or slice, excluding its members.
+func valueMemory(t json.Token) int64 {
+	switch v := t.(type) {
+	case string:
+		return sizeofString + int64(len(v))
+	case float64:
+		return 8
+	case json.Delim:
+		switch v {
+		case '{':
+			return sizeofMap
+		case '[':
+			return sizeofSlice
+		}
+	}
+	return 0
+}
+
+// account adds n bytes to the estimated memory of the decoded value.
+func (c *dupChecker) account(d *json.Decoder, n int64) error {
+	c.memory += n
+	if c.limits.MaxMemory > 0 && c.memory > c.limits.MaxMemory {
+		return &LimitError{Limit: "memory", Max: c.limits.MaxMemory, Offset: d.InputOffset()}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// countingAllocator counts the elements allocated.
+type countingAllocator struct {
+	strings, values int
+}
+
+func (a *countingAllocator) Strings(n int) []string {
+	a.strings += n
+	return make([]string, 0, n)
+}
+
+func (a *countingAllocator) Values(n int) []any {
+	a.values += n
+	return make([]any, 0, n)
+}
+
+func TestWithAllocator(t *testing.T) {
+	a := &countingAllocator{}
+	o := New(WithAllocator(a))
+	src := `{"a":1,"b":[1,2,3,4,5],"c":{"d":true},"e":[]}`
+	if err := o.UnmarshalJSON([]byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != src {
+		t.Errorf("got %s", b)
+	}
+	// Keys of o (4) and c (4), values of o and c (8) and b (4, then 8).
+	if a.strings != 8 || a.values != 20 {
+		t.Errorf("allocated %d strings and %d values", a.strings, a.values)
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := range smallSize * 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(`"` + strconv.Itoa(i) + `":` + strconv.Itoa(i))
+	}
+	sb.WriteByte('}')
+	o = New(WithAllocator(a))
+	if err := o.UnmarshalJSON([]byte(sb.String())); err != nil {
+		t.Fatal(err)
+	}
+	if o.Len() != smallSize*2 || o.Get("31") != 31.0 {
+		t.Errorf("large map %v", o.Keys())
+	}
+}
+
+func TestLimits_MaxMemory(t *testing.T) {
+	src := []byte(`{"a":"` + strings.Repeat("x", 1000) + `","b":[1,2,3]}`)
+	o := New(WithLimits(Limits{MaxMemory: 1000}))
+	var le *LimitError
+	if err := o.UnmarshalJSON(src); !errors.As(err, &le) || le.Limit != "memory" {
+		t.Fatalf("got %v, want memory LimitError", err)
+	}
+	if o.Len() != 0 {
+		t.Error("map built despite memory limit")
+	}
+	o = New(WithLimits(Limits{MaxMemory: 2000}))
+	if err := o.UnmarshalJSON(src); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,45 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build goexperiment.arenas
+
+package orderedmap
+
+import "arena"
+
+// ArenaAllocator returns an Allocator allocating from a.  Maps decoded with it
+// must not be used after a is freed.
+func ArenaAllocator(a *arena.Arena) Allocator {
+	return arenaAllocator{a}
+}
+
+type arenaAllocator struct {
+	a *arena.Arena
+}
+
+func (a arenaAllocator) Strings(n int) []string {
+	return arena.MakeSlice[string](a.a, 0, n)
+}
+
+func (a arenaAllocator) Values(n int) []any {
+	return arena.MakeSlice[any](a.a, 0, n)
+}
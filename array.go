@@ -0,0 +1,96 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// OrderedArrays makes UnmarshalJSON decode arrays, at any depth, as
+// OrderedArray instead of []any, so that a decoded document is traversable with
+// the typed accessors of OrderedMap and OrderedArray alone.
+func OrderedArrays() Option {
+	return func(opts *options) { opts.arrays = true }
+}
+
+// OrderedArray is a JSON array, as decoded by UnmarshalJSON with the
+// OrderedArrays option.  Elements are in input order, and objects are OrderedMap
+// values.  It is a []any, so it can be ranged over and indexed directly, and
+// encodes as a JSON array.  The accessors return false for an index out of
+// range or an element of a different type.
+type OrderedArray []any
+
+// Len returns the number of elements of a.
+func (a OrderedArray) Len() int {
+	return len(a)
+}
+
+// Get returns the element at i, or nil if i is out of range.
+func (a OrderedArray) Get(i int) any {
+	if i < 0 || i >= len(a) {
+		return nil
+	}
+	return a[i]
+}
+
+// StringValue returns the element at i if it is a string.
+func (a OrderedArray) StringValue(i int) (string, bool) {
+	s, ok := a.Get(i).(string)
+	return s, ok
+}
+
+// Float64 returns the element at i if it is a number.
+func (a OrderedArray) Float64(i int) (float64, bool) {
+	f, ok := a.Get(i).(float64)
+	return f, ok
+}
+
+// Bool returns the element at i if it is a bool.
+func (a OrderedArray) Bool(i int) (bool, bool) {
+	b, ok := a.Get(i).(bool)
+	return b, ok
+}
+
+// Map returns the element at i if it is an object.  An OrderedMap element is
+// returned as a pointer to a copy, as by GetMapSlice, so modifying it does not
+// modify a.
+func (a OrderedArray) Map(i int) (*OrderedMap, bool) {
+	if m, ok := a.Get(i).(OrderedMap); ok {
+		m.shared = true
+		return &m, true
+	}
+	return asMap(a.Get(i))
+}
+
+// Array returns the element at i if it is an array.
+func (a OrderedArray) Array(i int) (OrderedArray, bool) {
+	s, ok := asSlice(a.Get(i))
+	return s, ok
+}
+
+// asSlice returns v as an OrderedArray if v is a []any or an OrderedArray.
+func asSlice(v any) (OrderedArray, bool) {
+	switch s := v.(type) {
+	case []any:
+		return s, true
+	case OrderedArray:
+		return s, true
+	}
+	return nil, false
+}
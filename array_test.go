@@ -0,0 +1,81 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "testing"
+
+func TestOrderedArrays(t *testing.T) {
+	in := `{"a":[1,"s",true,{"y":1,"x":[null]},[2]]}`
+	for _, opts := range [][]Option{{OrderedArrays()}, {OrderedArrays(), Lazy()}} {
+		o := New(opts...)
+		if err := o.UnmarshalJSON([]byte(in)); err != nil {
+			t.Fatal(err)
+		}
+		a, ok := o.Get("a").(OrderedArray)
+		if !ok || a.Len() != 5 {
+			t.Fatalf("got %#v", o.Get("a"))
+		}
+		if f, ok := a.Float64(0); !ok || f != 1 {
+			t.Errorf("Float64(0) = %v, %v", f, ok)
+		}
+		if s, ok := a.StringValue(1); !ok || s != "s" {
+			t.Errorf("StringValue(1) = %v, %v", s, ok)
+		}
+		if b, ok := a.Bool(2); !ok || !b {
+			t.Errorf("Bool(2) = %v, %v", b, ok)
+		}
+		m, ok := a.Map(3)
+		if !ok {
+			t.Fatalf("Map(3) not ok")
+		}
+		if x, ok := m.Get("x").(OrderedArray); !ok || x.Len() != 1 || x.Get(0) != nil {
+			t.Errorf("nested array %#v", m.Get("x"))
+		}
+		if n, ok := a.Array(4); !ok || n.Get(0) != 2.0 {
+			t.Errorf("Array(4) = %v, %v", n, ok)
+		}
+		if _, ok := a.StringValue(0); ok {
+			t.Error("StringValue(0) ok")
+		}
+		if a.Get(5) != nil || a.Get(-1) != nil {
+			t.Error("Get out of range not nil")
+		}
+		if b, _ := o.MarshalJSON(); string(b) != in {
+			t.Errorf("got %s, want %s", b, in)
+		}
+		snap := o.Snapshot()
+		m.Set("y", 2)
+		if b, _ := o.MarshalJSON(); string(b) != in {
+			t.Errorf("Map modified the array: %s", b)
+		}
+		if b, _ := snap.MarshalJSON(); string(b) != in {
+			t.Errorf("Map modified the snapshot: %s", b)
+		}
+
+		plain := New()
+		plain.UnmarshalJSON([]byte(in))
+		if !o.EqualOrdered(plain) {
+			t.Error("not equal to []any decoding")
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Backend parses JSON for UnmarshalJSON in place of encoding/json, such as a
+// faster or SIMD parser.  See WithBackend.
+type Backend interface {
+	// Parse parses the JSON value b, reporting its tokens to h in input order,
+	// and returns the first error returned by h.  Every member of every object
+	// must be reported, including duplicates, which h rejects.
+	Parse(b []byte, h Handler) error
+}
+
+// Handler receives the tokens of a JSON value from a Backend, or of a map from
+// Emit, such as for an encoder of another format.  An error returned by a
+// method stops parsing or emitting.
+type Handler interface {
+	BeginObject() error
+	// Key reports the key of the next member of the current object.
+	Key(key string) error
+	EndObject() error
+	BeginArray() error
+	EndArray() error
+	// Value reports a scalar: from a Backend nil, a bool, a float64 or a
+	// string, and from Emit any value other than a map or slice.
+	Value(v any) error
+}
+
+// WithBackend makes UnmarshalJSON parse input with b instead of encoding/json.
+// Decoded maps are order preserving, duplicate checked, validated and limited
+// as with encoding/json, except that error offsets are 0.  RegisterType,
+// Lazy, KeyOffsets, KeepRaw and Faithful need the encoding/json token stream
+// and do not apply to a map decoded by a Backend.
+func WithBackend(b Backend) Option {
+	return func(opts *options) { opts.backend = b }
+}
+
+// errExpectedObject is returned when the top-level value of a Backend or of
+// ZeroCopy input is not an object.
+var errExpectedObject = errors.New("orderedmap: cannot unmarshal JSON into OrderedMap, expected object")
+
+// unmarshalBackend is unmarshalStrict with opts.backend.
+func (o *OrderedMap) unmarshalBackend(b []byte) error {
+	// By convention, null is a no-op.
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return nil
+	}
+	return o.replace(func() error {
+		t := &treeBuilder{root: o}
+		if o.opts != nil {
+			t.limits = o.opts.limits
+		}
+		if err := o.opts.backend.Parse(b, t); err != nil {
+			return err
+		}
+		if !t.done {
+			return errExpectedObject
+		}
+		return nil
+	})
+}
+
+// treeBuilder is the Handler building a map from the tokens of a Backend.
+type treeBuilder struct {
+	root   *OrderedMap
+	limits Limits
+	// stack holds the objects and arrays being built, outermost first.
+	stack []frame
+	// done is set once the top-level object is complete.
+	done         bool
+	keys, memory int64
+	// offset returns the offset of the current token, or is nil if unknown.
+	offset func() int64
+	// typed accepts the other scalar types of ReadFrom.
+	typed bool
+}
+
+// frame is an object or array being built by a treeBuilder.
+type frame struct {
+	m   *OrderedMap // nil for an array
+	arr []any
+	// key is the key of the member whose value is being decoded.
+	key string
+}
+
+func (t *treeBuilder) BeginObject() error {
+	var m *OrderedMap
+	switch {
+	case t.done:
+		return fmt.Errorf("orderedmap: invalid data after top-level JSON object")
+	case len(t.stack) == 0:
+		m = t.root
+	default:
+		m = t.root.empty()
+	}
+	return t.begin(frame{m: m}, sizeofMap)
+}
+
+func (t *treeBuilder) BeginArray() error {
+	if len(t.stack) == 0 {
+		return errExpectedObject
+	}
+	return t.begin(frame{}, sizeofSlice)
+}
+
+func (t *treeBuilder) begin(f frame, n int64) error {
+	if t.limits.MaxDepth > 0 && len(t.stack) >= t.limits.MaxDepth {
+		return &LimitError{Limit: "depth", Max: int64(t.limits.MaxDepth), Offset: t.at()}
+	}
+	if err := t.account(n); err != nil {
+		return err
+	}
+	t.stack = append(t.stack, f)
+	return nil
+}
+
+func (t *treeBuilder) Key(key string) error {
+	if len(t.stack) == 0 || t.top().m == nil {
+		return fmt.Errorf("orderedmap: invalid JSON object key %q", key)
+	}
+	f := t.top()
+	if f.m.opts != nil && f.m.opts.intern {
+		key = intern(key)
+	}
+	if _, dup := f.m.get(key); dup {
+		return &DuplicateError{Key: key, First: f.m.keys[f.m.index(key)], Path: t.path(key), Offset: t.at()}
+	}
+	if t.keys++; t.limits.MaxKeys > 0 && t.keys > int64(t.limits.MaxKeys) {
+		return &LimitError{Limit: "keys", Max: int64(t.limits.MaxKeys), Offset: t.at()}
+	}
+	if err := t.limits.checkString(key, t.at()); err != nil {
+		return err
+	}
+	if err := t.limits.checkObject(len(f.m.keys)+1, t.at()); err != nil {
+		return err
+	}
+	if a := f.m.allocator(); a != nil {
+		f.m.reserve(a)
+	}
+	f.key = key
+	return t.account(keyMemory(key))
+}
+
+func (t *treeBuilder) EndObject() error {
+	if len(t.stack) == 0 || t.top().m == nil {
+		return fmt.Errorf("orderedmap: unexpected end of JSON object")
+	}
+	m := t.pop().m
+	if len(t.stack) == 0 {
+		t.done = true
+		return nil
+	}
+	return t.add(*m)
+}
+
+func (t *treeBuilder) EndArray() error {
+	if len(t.stack) == 0 || t.top().m != nil {
+		return fmt.Errorf("orderedmap: unexpected end of JSON array")
+	}
+	arr := t.pop().arr
+	if arr == nil {
+		arr = []any{}
+	}
+	if t.root.opts != nil && t.root.opts.arrays {
+		return t.add(OrderedArray(arr))
+	}
+	return t.add(arr)
+}
+
+func (t *treeBuilder) Value(v any) error {
+	if len(t.stack) == 0 {
+		return errExpectedObject
+	}
+	var n int64
+	switch v := v.(type) {
+	case nil, bool:
+	case float64:
+		n = 8
+	case string:
+		if err := t.limits.checkString(v, t.at()); err != nil {
+			return err
+		}
+		n = sizeofString + int64(len(v))
+	case int64, uint64, json.Number, []byte, time.Time:
+		if !t.typed {
+			return fmt.Errorf("orderedmap: backend value of unsupported type %T", v)
+		}
+		n = binaryMemory(v)
+	default:
+		return fmt.Errorf("orderedmap: backend value of unsupported type %T", v)
+	}
+	if err := t.account(n); err != nil {
+		return err
+	}
+	return t.add(v)
+}
+
+// add adds decoded value v to the current object or array.
+func (t *treeBuilder) add(v any) error {
+	f := t.top()
+	if f.m == nil {
+		if err := t.account(sizeofAny); err != nil {
+			return err
+		}
+		if err := t.limits.checkArray(len(f.arr)+1, t.at()); err != nil {
+			return err
+		}
+		f.arr = appendValue(t.root.allocator(), f.arr, v)
+		return nil
+	}
+	return f.m.store(f.key, v)
+}
+
+// at returns the offset of the current token, or 0 if unknown.
+func (t *treeBuilder) at() int64 {
+	if t.offset == nil {
+		return 0
+	}
+	return t.offset()
+}
+
+func (t *treeBuilder) top() *frame {
+	return &t.stack[len(t.stack)-1]
+}
+
+func (t *treeBuilder) pop() frame {
+	f := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	return f
+}
+
+// account adds n bytes to the estimated memory of the decoded value.
+func (t *treeBuilder) account(n int64) error {
+	t.memory += n
+	if t.limits.MaxMemory > 0 && t.memory > t.limits.MaxMemory {
+		return &LimitError{Limit: "memory", Max: t.limits.MaxMemory, Offset: t.at()}
+	}
+	return nil
+}
+
+// path returns the path of key in the current object, as in DuplicateError.
+func (t *treeBuilder) path(key string) string {
+	var path string
+	for i, f := range t.stack[:len(t.stack)-1] {
+		switch {
+		case f.m == nil:
+			path += "[" + strconv.Itoa(len(f.arr)) + "]"
+		case i == 0:
+			path = f.key
+		default:
+			path += "." + f.key
+		}
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,120 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+// tokenBackend is a Backend reporting the tokens of encoding/json, which
+// unlike OrderedMap does not reject duplicates.
+type tokenBackend struct{}
+
+func (tokenBackend) Parse(b []byte, h Handler) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	var objects []bool // whether each open delimiter is an object
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Token does not distinguish keys, so look for the colon.
+		rest := bytes.TrimLeft(b[dec.InputOffset():], " \t\r\n")
+		s, isString := t.(string)
+		switch {
+		case isString && len(objects) > 0 && objects[len(objects)-1] && len(rest) > 0 && rest[0] == ':':
+			err = h.Key(s)
+		case t == json.Delim('{'):
+			objects = append(objects, true)
+			err = h.BeginObject()
+		case t == json.Delim('['):
+			objects = append(objects, false)
+			err = h.BeginArray()
+		case t == json.Delim('}'):
+			objects = objects[:len(objects)-1]
+			err = h.EndObject()
+		case t == json.Delim(']'):
+			objects = objects[:len(objects)-1]
+			err = h.EndArray()
+		default:
+			err = h.Value(t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestWithBackend(t *testing.T) {
+	in := `{"b":1,"a":{"z":[1,"x",{"c":null}],"y":true},"e":[],"d":{}}`
+	want := New()
+	if err := want.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	o := New(WithBackend(tokenBackend{}))
+	o.Set("old", 1)
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	if !o.EqualOrdered(want) {
+		t.Errorf("got %v, want %v", o, want)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != in {
+		t.Errorf("got %s", b)
+	}
+	if err := o.UnmarshalJSON([]byte("null")); err != nil || o.Len() != 4 {
+		t.Errorf("null: %v, Len %d", err, o.Len())
+	}
+
+	for _, in := range []string{`{"a":1,"a":2}`, `{"a":[{"b":{"c":1,"c":2}}]}`} {
+		var de *DuplicateError
+		if err := o.UnmarshalJSON([]byte(in)); !errors.As(err, &de) {
+			t.Errorf("%s: got %v", in, err)
+		} else if in != `{"a":1,"a":2}` && de.Path != "a[0].b.c" {
+			t.Errorf("Path = %q", de.Path)
+		}
+	}
+	for _, in := range []string{`[1]`, `"s"`, `{}{}`} {
+		if err := o.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("%s: no error", in)
+		}
+	}
+
+	m := New(WithBackend(tokenBackend{}), OrderedArrays(), WithLimits(Limits{MaxDepth: 3}))
+	if err := m.UnmarshalJSON([]byte(`{"a":[[1]]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Get("a").(OrderedArray); !ok {
+		t.Errorf("got %T", m.Get("a"))
+	}
+	if err := m.UnmarshalJSON([]byte(`{"a":[[{}]]}`)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("depth: got %v", err)
+	}
+}
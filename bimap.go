@@ -0,0 +1,147 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrValueCollision is returned when setting an OrderedBiMap value that is
+// already the value of another key.
+var ErrValueCollision = errors.New("orderedmap: value already set for another key")
+
+// OrderedBiMap is an ordered map of unique values, with a reverse index for
+// looking up the key of a value in constant time, such as for names and codes.
+// Values must be comparable: strings, numbers, bools and the like, not maps
+// or slices.  The zero value is an empty map ready to use.  An OrderedBiMap is
+// not safe for concurrent use.
+type OrderedBiMap struct {
+	m OrderedMap
+	// keyOf is the reverse index, from value to key.
+	keyOf map[any]string
+}
+
+// NewBiMap returns an empty OrderedBiMap.
+func NewBiMap() *OrderedBiMap {
+	return new(OrderedBiMap)
+}
+
+// Set sets the value for key, keeping the position of an existing key.  It
+// returns an error matching ErrValueCollision if value is the value of another
+// key, or if value is not comparable, and the map is not modified.
+func (b *OrderedBiMap) Set(key string, value any) error {
+	if value != nil && !reflect.ValueOf(value).Comparable() {
+		return fmt.Errorf("orderedmap: OrderedBiMap value of key %q is not comparable: %T", key, value)
+	}
+	if k, ok := b.keyOf[value]; ok && k != key {
+		return fmt.Errorf("%w: %v is the value of %q", ErrValueCollision, value, k)
+	}
+	if old, ok := b.m.get(key); ok {
+		delete(b.keyOf, old)
+	}
+	if b.keyOf == nil {
+		b.keyOf = make(map[any]string)
+	}
+	b.m.set(key, value)
+	b.keyOf[value] = key
+	return nil
+}
+
+// TrySet is Set, for the Setter interface.
+func (b *OrderedBiMap) TrySet(key string, value any) error {
+	return b.Set(key, value)
+}
+
+// Get returns the value for key and whether it exists.
+func (b *OrderedBiMap) Get(key string) (any, bool) {
+	return b.m.get(key)
+}
+
+// GetKeyForValue returns the key of value and whether it exists.
+func (b *OrderedBiMap) GetKeyForValue(value any) (string, bool) {
+	if value != nil && !reflect.ValueOf(value).Comparable() {
+		return "", false
+	}
+	k, ok := b.keyOf[value]
+	return k, ok
+}
+
+// Delete deletes key and its value.
+func (b *OrderedBiMap) Delete(key string) {
+	if v, ok := b.m.get(key); ok {
+		delete(b.keyOf, v)
+		b.m.Delete(key)
+	}
+}
+
+// DeleteValue deletes value and its key.
+func (b *OrderedBiMap) DeleteValue(value any) {
+	if k, ok := b.GetKeyForValue(value); ok {
+		b.Delete(k)
+	}
+}
+
+// Len returns the number of pairs.
+func (b *OrderedBiMap) Len() int {
+	return b.m.Len()
+}
+
+// Keys returns the keys in order.  The slice must not be modified.
+func (b *OrderedBiMap) Keys() []string {
+	return b.m.Keys()
+}
+
+// Map returns a copy of the pairs as an OrderedMap.
+func (b *OrderedBiMap) Map() *OrderedMap {
+	return b.m.Snapshot()
+}
+
+// MarshalJSON encodes b as a JSON object in order.
+func (b OrderedBiMap) MarshalJSON() ([]byte, error) {
+	return b.m.MarshalJSON()
+}
+
+// UnmarshalJSON replaces the contents of b with the JSON object data, whose
+// values must be unique scalars.  Numbers are decoded as float64, so look
+// them up with GetKeyForValue as float64.  On error b is not modified.  By
+// convention, null is a no-op.
+func (b *OrderedBiMap) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	m := New()
+	if err := m.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n := new(OrderedBiMap)
+	for k, v := range m.All() {
+		if err := n.Set(k, v); err != nil {
+			return err
+		}
+	}
+	*b = *n
+	return nil
+}
@@ -0,0 +1,83 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestOrderedBiMap(t *testing.T) {
+	var b OrderedBiMap
+	for _, p := range []struct {
+		k string
+		v any
+	}{{"ES256", -7}, {"ES384", -35}, {"Ed25519", -8}} {
+		if err := b.Set(p.k, p.v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if k, ok := b.GetKeyForValue(-35); !ok || k != "ES384" {
+		t.Errorf("GetKeyForValue = %q, %v", k, ok)
+	}
+	if err := b.Set("X", -7); !errors.Is(err, ErrValueCollision) {
+		t.Errorf("got %v", err)
+	}
+	if err := b.Set("X", []any{1}); err == nil {
+		t.Error("accepted slice value")
+	}
+	if err := b.Set("ES256", -9); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.GetKeyForValue(-7); ok {
+		t.Error("old value still indexed")
+	}
+	if err := b.Set("X", -7); err != nil {
+		t.Errorf("reusing freed value: %v", err)
+	}
+	b.DeleteValue(-8)
+	b.Delete("X")
+	if !slices.Equal(b.Keys(), []string{"ES256", "ES384"}) || b.Len() != 2 {
+		t.Errorf("Keys = %v", b.Keys())
+	}
+	if v, _ := b.Get("ES256"); v != -9 {
+		t.Errorf("Get = %v", v)
+	}
+
+	out, err := json.Marshal(b)
+	if err != nil || string(out) != `{"ES256":-9,"ES384":-35}` {
+		t.Fatalf("got %s, %v", out, err)
+	}
+	var u OrderedBiMap
+	if err := json.Unmarshal(out, &u); err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := u.GetKeyForValue(-35.0); k != "ES384" {
+		t.Errorf("decoded GetKeyForValue = %q", k)
+	}
+	if err := u.UnmarshalJSON([]byte(`{"a":1,"b":1}`)); !errors.Is(err, ErrValueCollision) || u.Len() != 2 {
+		t.Errorf("got %v, Len %d", err, u.Len())
+	}
+}
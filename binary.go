@@ -0,0 +1,451 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// The binary format of WriteTo is the header binaryMagic followed by the
+// top-level object.  A value is a type byte followed by its data: nothing for
+// null and bools, 8 little-endian bytes for a float64, a zig-zag varint for an
+// int64 and a uvarint for a uint64, a uvarint length and the bytes for
+// strings, numbers, bytes and times in their MarshalBinary form, and the
+// members for objects and arrays.  An object member is a uvarint of the key
+// length plus one, the key and the value, and an array element is a value.
+// Objects and arrays end with a 0 byte.
+const (
+	binaryMagic   = "OMB\x01"
+	binaryVersion = 1
+)
+
+const (
+	binEnd byte = iota
+	binNull
+	binFalse
+	binTrue
+	binFloat
+	binInt
+	binUint
+	binString
+	binNumber
+	binBytes
+	binTime
+	binObject
+	binArray
+)
+
+// ErrBinary is returned for input not in the binary format of WriteTo.
+var ErrBinary = errors.New("orderedmap: invalid binary map")
+
+// WriteTo writes o to w in a compact binary format, read by ReadFrom, and
+// returns the number of bytes written.  Keys are length prefixed and values
+// are typed, so that reading is about twice as fast as UnmarshalJSON.
+// Values are written as for Emit, except that nil, bools, float64,
+// json.Number, strings, []byte and time.Time keep their types, other integers
+// are read back as int64 or uint64 and float32 as float64, and other scalars
+// are rejected.  The format is versioned, and later versions will read maps
+// written by earlier ones.
+func (o *OrderedMap) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := &binaryWriter{w: bufio.NewWriter(cw)}
+	bw.w.WriteString(binaryMagic)
+	err := o.Emit(bw)
+	if err == nil {
+		err = bw.w.Flush()
+	}
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// binaryWriter is the Handler writing the binary format.  Write errors are
+// returned by Flush.
+type binaryWriter struct {
+	w *bufio.Writer
+	b []byte
+}
+
+func (b *binaryWriter) BeginObject() error {
+	return b.w.WriteByte(binObject)
+}
+
+func (b *binaryWriter) Key(key string) error {
+	b.uvarint(uint64(len(key)) + 1)
+	_, err := b.w.WriteString(key)
+	return err
+}
+
+func (b *binaryWriter) EndObject() error {
+	return b.w.WriteByte(binEnd)
+}
+
+func (b *binaryWriter) BeginArray() error {
+	return b.w.WriteByte(binArray)
+}
+
+func (b *binaryWriter) EndArray() error {
+	return b.w.WriteByte(binEnd)
+}
+
+func (b *binaryWriter) Value(v any) error {
+	switch t := v.(type) {
+	case nil:
+		return b.w.WriteByte(binNull)
+	case bool:
+		if t {
+			return b.w.WriteByte(binTrue)
+		}
+		return b.w.WriteByte(binFalse)
+	case float64:
+		return b.float(t)
+	case float32:
+		return b.float(float64(t))
+	case string:
+		return b.string(binString, t)
+	case json.Number:
+		return b.string(binNumber, string(t))
+	case []byte:
+		return b.string(binBytes, string(t))
+	case time.Time:
+		data, err := t.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return b.string(binTime, string(data))
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.w.WriteByte(binInt)
+		b.b = binary.AppendVarint(b.b[:0], rv.Int())
+		_, err := b.w.Write(b.b)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.w.WriteByte(binUint)
+		return b.uvarint(rv.Uint())
+	}
+	return fmt.Errorf("orderedmap: cannot write %T in binary format", v)
+}
+
+func (b *binaryWriter) float(f float64) error {
+	b.w.WriteByte(binFloat)
+	b.b = binary.LittleEndian.AppendUint64(b.b[:0], math.Float64bits(f))
+	_, err := b.w.Write(b.b)
+	return err
+}
+
+func (b *binaryWriter) string(t byte, s string) error {
+	b.w.WriteByte(t)
+	b.uvarint(uint64(len(s)))
+	_, err := b.w.WriteString(s)
+	return err
+}
+
+func (b *binaryWriter) uvarint(n uint64) error {
+	b.b = binary.AppendUvarint(b.b[:0], n)
+	_, err := b.w.Write(b.b)
+	return err
+}
+
+// ReadFrom replaces the contents of o with the map read from r in the binary
+// format of WriteTo, and returns the number of bytes read.  Decoded maps are
+// duplicate checked, validated and limited as with UnmarshalJSON, with byte
+// offsets in errors, and o is not modified on error.  Unless r is an
+// io.ByteReader, such as a bufio.Reader, it is buffered, and reading may
+// consume input beyond the map.
+func (o *OrderedMap) ReadFrom(r io.Reader) (int64, error) {
+	if o.frozen {
+		return 0, ErrFrozen
+	}
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	d := &binaryReader{r: br}
+	root := o.empty()
+	t := &treeBuilder{root: root, typed: true, offset: func() int64 { return d.tok }}
+	if o.opts != nil {
+		t.limits = o.opts.limits
+	}
+	if err := d.read(t); err != nil {
+		return d.n, err
+	}
+	return d.n, o.replace(func() error {
+		o.keys, o.vals, o.values = root.keys, root.vals, root.values
+		return nil
+	})
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// binaryReader reads the binary format, counting the bytes read.
+type binaryReader struct {
+	r byteReader
+	n int64
+	// tok is the offset of the current key or value.
+	tok int64
+	// buf holds the bytes of the last string read.
+	buf []byte
+}
+
+func (d *binaryReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.n += int64(n)
+	return n, err
+}
+
+func (d *binaryReader) ReadByte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err == nil {
+		d.n++
+	}
+	return c, err
+}
+
+// read reports the map read to h.
+func (d *binaryReader) read(h Handler) error {
+	head, err := d.bytes(uint64(len(binaryMagic)))
+	if err != nil {
+		return err
+	}
+	if string(head[:3]) != binaryMagic[:3] {
+		return fmt.Errorf("%w: missing header", ErrBinary)
+	}
+	if head[3] != binaryVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrBinary, head[3])
+	}
+	off := d.n
+	t, err := d.byte()
+	if err != nil {
+		return err
+	}
+	if t != binObject {
+		return errExpectedObject
+	}
+	d.tok = off
+	return d.value(h, t, off)
+}
+
+// value reports the value of type t at offset off, with its members, to h.
+func (d *binaryReader) value(h Handler, t byte, off int64) error {
+	// objects holds, for each object and array being read, whether it is an
+	// object.
+	var objects []bool
+	for {
+		var err error
+		switch t {
+		case binEnd:
+			if len(objects) == 0 || objects[len(objects)-1] {
+				return fmt.Errorf("%w: missing value at offset %d", ErrBinary, off)
+			}
+			objects = objects[:len(objects)-1]
+			err = h.EndArray()
+		case binObject:
+			objects = append(objects, true)
+			err = h.BeginObject()
+		case binArray:
+			objects = append(objects, false)
+			err = h.BeginArray()
+		default:
+			var v any
+			if v, err = d.scalar(t, off); err == nil {
+				err = h.Value(v)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		// Read the key of the next member of the current object, ending the
+		// objects that have no more.
+		for len(objects) > 0 && objects[len(objects)-1] {
+			d.tok = d.n
+			n, err := d.uvarint()
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				key, err := d.string(n - 1)
+				if err != nil {
+					return err
+				}
+				if err := h.Key(key); err != nil {
+					return err
+				}
+				break
+			}
+			objects = objects[:len(objects)-1]
+			if err := h.EndObject(); err != nil {
+				return err
+			}
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+		d.tok, off = d.n, d.n
+		if t, err = d.byte(); err != nil {
+			return err
+		}
+	}
+}
+
+// scalar reads the data of a scalar of type t at offset off.
+func (d *binaryReader) scalar(t byte, off int64) (any, error) {
+	switch t {
+	case binNull:
+		return nil, nil
+	case binFalse:
+		return false, nil
+	case binTrue:
+		return true, nil
+	case binFloat:
+		b, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case binInt:
+		n, err := binary.ReadVarint(d)
+		return n, d.checkVarint(err)
+	case binUint:
+		return d.uvarint()
+	case binString, binNumber, binBytes, binTime:
+		n, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if t == binBytes {
+			return d.bytes(n)
+		}
+		s, err := d.string(n)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case binString:
+			return s, nil
+		case binNumber:
+			return json.Number(s), nil
+		}
+		var tm time.Time
+		if err := tm.UnmarshalBinary([]byte(s)); err != nil {
+			return nil, fmt.Errorf("%w: time at offset %d: %v", ErrBinary, off, err)
+		}
+		return tm, nil
+	}
+	return nil, fmt.Errorf("%w: unknown type %d at offset %d", ErrBinary, t, off)
+}
+
+func (d *binaryReader) byte() (byte, error) {
+	c, err := d.ReadByte()
+	return c, d.check(err)
+}
+
+func (d *binaryReader) uvarint() (uint64, error) {
+	n, err := binary.ReadUvarint(d)
+	return n, d.checkVarint(err)
+}
+
+// string reads a string of n bytes.
+func (d *binaryReader) string(n uint64) (string, error) {
+	if n > 1<<16 {
+		b, err := d.bytes(n)
+		return string(b), err
+	}
+	if uint64(cap(d.buf)) < n {
+		d.buf = make([]byte, max(n, 64))
+	}
+	b := d.buf[:n]
+	_, err := io.ReadFull(d, b)
+	return string(b), d.check(err)
+}
+
+// bytes reads n bytes, growing the result as they are read so that a corrupt
+// length cannot allocate more than the input.
+func (d *binaryReader) bytes(n uint64) ([]byte, error) {
+	if n <= 1<<16 {
+		b := make([]byte, n)
+		_, err := io.ReadFull(d, b)
+		return b, d.check(err)
+	}
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: length %d at offset %d", ErrBinary, n, d.n)
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, d, int64(n)); err != nil {
+		return nil, d.check(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// check returns err, reporting the end of input as truncation.
+func (d *binaryReader) check(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated at offset %d", ErrBinary, d.n)
+	}
+	return err
+}
+
+// checkVarint is check for a varint, whose overflow binary reports with an
+// unexported error.
+func (d *binaryReader) checkVarint(err error) error {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return d.check(err)
+	}
+	return fmt.Errorf("%w at offset %d: %w", ErrBinary, d.n, err)
+}
+
+// binaryMemory returns the estimated memory of a scalar of ReadFrom other
+// than those of JSON.
+func binaryMemory(v any) int64 {
+	switch v := v.(type) {
+	case json.Number:
+		return sizeofString + int64(len(v))
+	case []byte:
+		return sizeofSlice + int64(len(v))
+	case time.Time:
+		return int64(unsafe.Sizeof(time.Time{}))
+	}
+	return 8
+}
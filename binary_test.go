@@ -0,0 +1,216 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWriteTo(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"z":1.5,"a":[1,"x",null,true,false,{"":[]}],"m":{"k":{}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("X", 3600))
+	o.Set("t", when)
+	o.Set("i", -7)
+	o.Set("u", uint8(200))
+	o.Set("n", json.Number("1e400"))
+	o.Set("b", []byte{0, 1})
+	o.Set("nan", math.NaN())
+	o.Set("ints", []int{1, 2})
+
+	var buf bytes.Buffer
+	n, err := o.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d", n, buf.Len())
+	}
+	b := buf.Bytes()
+
+	d := New()
+	n, err = d.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(b)) {
+		t.Errorf("ReadFrom returned %d, read %d", n, len(b))
+	}
+	if !slices.Equal(d.Keys(), o.Keys()) {
+		t.Errorf("got keys %q, want %q", d.Keys(), o.Keys())
+	}
+	want := map[string]any{
+		"z":    1.5,
+		"t":    when,
+		"i":    int64(-7),
+		"u":    uint64(200),
+		"n":    json.Number("1e400"),
+		"b":    []byte{0, 1},
+		"a":    []any{1.0, "x", nil, true, false, OrderedMap{keys: []string{""}, vals: []any{[]any{}}}},
+		"ints": []any{int64(1), int64(2)},
+	}
+	for k, v := range want {
+		got := d.Get(k)
+		if tm, ok := got.(time.Time); ok {
+			if !tm.Equal(when) || tm.Format(time.RFC3339Nano) != when.Format(time.RFC3339Nano) {
+				t.Errorf("%s: got %v, want %v", k, tm, when)
+			}
+			continue
+		}
+		if m, ok := v.([]any); ok && k == "a" {
+			gm := got.([]any)
+			if !reflect.DeepEqual(gm[:5], m[:5]) || gm[5].(OrderedMap).String() != `{"":[]}` {
+				t.Errorf("%s: got %#v", k, got)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("%s: got %#v, want %#v", k, got, v)
+		}
+	}
+	if f := d.Get("nan").(float64); !math.IsNaN(f) {
+		t.Errorf("got %v, want NaN", f)
+	}
+	if s := d.Get("m").(OrderedMap); s.String() != `{"k":{}}` {
+		t.Errorf("got %s", s.String())
+	}
+
+	// Two maps can be read from one buffered reader.
+	r := bufio.NewReader(bytes.NewReader(append(slices.Clone(b), b...)))
+	for range 2 {
+		if _, err := New().ReadFrom(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o = New()
+	o.Set("bad", struct{}{})
+	if _, err := o.WriteTo(&buf); err == nil {
+		t.Error("unsupported value written")
+	}
+}
+
+func TestReadFrom_Invalid(t *testing.T) {
+	o := New()
+	o.Set("a", []any{"x", 1.0})
+	o.Set("b", map[string]any{"c": true})
+	var buf bytes.Buffer
+	if _, err := o.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	for i := range len(b) {
+		d := New()
+		d.Set("keep", 1)
+		if _, err := d.ReadFrom(bytes.NewReader(b[:i])); !errors.Is(err, ErrBinary) {
+			t.Errorf("%d bytes: got %v, want ErrBinary", i, err)
+		}
+		if d.Len() != 1 || d.Get("keep") != 1 {
+			t.Errorf("%d bytes: modified on error: %s", i, d.String())
+		}
+	}
+
+	for _, in := range []string{
+		"OMX\x01\x0b\x00",
+		"OMB\x02\x0b\x00",
+		"OMB\x01\x0b\x02a\x7f\x00",
+		"OMB\x01\x0b\x02a\x00",
+		"OMB\x01\x0b\x02a\x05\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01\x00",
+		"OMB\x01\x0b\x02a\x07\xff\xff\xff\xff\xff\xff\xff\xff\x7f",
+	} {
+		if _, err := New().ReadFrom(bytes.NewReader([]byte(in))); !errors.Is(err, ErrBinary) {
+			t.Errorf("%q: got %v, want ErrBinary", in, err)
+		}
+	}
+
+	if _, err := New().ReadFrom(bytes.NewReader([]byte("OMB\x01\x0c\x00"))); err == nil {
+		t.Error("array read as map")
+	}
+
+	dup := []byte("OMB\x01\x0b\x02a\x01\x02a\x02\x00")
+	var de *DuplicateError
+	if _, err := New().ReadFrom(bytes.NewReader(dup)); !errors.As(err, &de) || !errors.Is(err, ErrJSONDuplicate) {
+		t.Errorf("got %v, want DuplicateError", err)
+	} else if de.Offset != 8 {
+		t.Errorf("got offset %d, want 8", de.Offset)
+	}
+
+	l := New(WithLimits(Limits{MaxObjectKeys: 1}))
+	if _, err := l.ReadFrom(bytes.NewReader(b)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got %v, want ErrLimitExceeded", err)
+	}
+
+	f := New()
+	f.Freeze()
+	if _, err := f.ReadFrom(bytes.NewReader(b)); !errors.Is(err, ErrFrozen) {
+		t.Errorf("got %v, want ErrFrozen", err)
+	}
+}
+
+func BenchmarkReadFrom(b *testing.B) {
+	o := New()
+	for i := range 1000 {
+		m := New()
+		m.Set("id", float64(i))
+		m.Set("name", "name"+strconv.Itoa(i))
+		m.Set("tags", []any{"a", "b"})
+		o.Set("key"+strconv.Itoa(i), m)
+	}
+	var buf bytes.Buffer
+	if _, err := o.WriteTo(&buf); err != nil {
+		b.Fatal(err)
+	}
+	bin := buf.Bytes()
+	js, err := o.MarshalJSON()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := New().ReadFrom(bytes.NewReader(bin)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := New().UnmarshalJSON(js); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,368 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BSON element types.
+const (
+	bsonDouble   = 0x01
+	bsonString   = 0x02
+	bsonDocument = 0x03
+	bsonArray    = 0x04
+	bsonBinary   = 0x05
+	bsonObjectID = 0x07
+	bsonBool     = 0x08
+	bsonDateTime = 0x09
+	bsonNull     = 0x0A
+	bsonInt32    = 0x10
+	bsonInt64    = 0x12
+)
+
+// ErrBSON is wrapped by errors for malformed or unsupported BSON.
+var ErrBSON = errors.New("orderedmap: invalid BSON")
+
+// ObjectID is a BSON ObjectId, such as the _id of MongoDB documents.  It is
+// encoded in JSON as a hex string.
+type ObjectID [12]byte
+
+// MarshalJSON encodes id as a hex string.
+func (id ObjectID) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + hex.EncodeToString(id[:]) + `"`), nil
+}
+
+// MarshalBSON encodes o as a BSON document in order, implementing
+// bson.Marshaler of the MongoDB Go driver, so that stored documents keep the
+// order of o.  Nested maps are encoded as documents, which the driver decodes
+// as the ordered bson.D, and Go maps as documents in sorted key order.
+//
+// Values are encoded as: float32 and float64 as double, smaller integers as
+// int32, int, int64 and other integers as int64, json.Number as int64 if it
+// is an integer and otherwise double, string, bool and nil as themselves,
+// []byte as generic binary, time.Time as UTC datetime, ObjectID as ObjectId,
+// and other slices and arrays as arrays.  Other types are rejected with an
+// error wrapping ErrBSON.
+func (o OrderedMap) MarshalBSON() ([]byte, error) {
+	return appendBSONMap(nil, &o)
+}
+
+func appendBSONMap(b []byte, o *OrderedMap) ([]byte, error) {
+	start := len(b)
+	b = append(b, 0, 0, 0, 0)
+	for i, k := range o.keys {
+		var err error
+		if b, err = appendBSONElement(b, k, o.valueAt(i)); err != nil {
+			return nil, err
+		}
+	}
+	return endBSONDocument(b, start), nil
+}
+
+// endBSONDocument terminates the document beginning at start and sets its
+// length.
+func endBSONDocument(b []byte, start int) []byte {
+	b = append(b, 0)
+	binary.LittleEndian.PutUint32(b[start:], uint32(len(b)-start))
+	return b
+}
+
+func appendBSONElement(b []byte, key string, v any) ([]byte, error) {
+	if strings.IndexByte(key, 0) >= 0 {
+		return nil, fmt.Errorf("%w: key %q contains NUL", ErrBSON, key)
+	}
+	head := func(t byte) []byte {
+		b = append(b, t)
+		b = append(b, key...)
+		return append(b, 0)
+	}
+	if m, ok := asMap(v); ok {
+		return appendBSONMap(head(bsonDocument), m)
+	}
+	switch v := v.(type) {
+	case nil:
+		return head(bsonNull), nil
+	case bool:
+		if v {
+			return append(head(bsonBool), 1), nil
+		}
+		return append(head(bsonBool), 0), nil
+	case float64:
+		return binary.LittleEndian.AppendUint64(head(bsonDouble), math.Float64bits(v)), nil
+	case float32:
+		return binary.LittleEndian.AppendUint64(head(bsonDouble), math.Float64bits(float64(v))), nil
+	case int8, int16, int32, uint8, uint16:
+		n := reflect.ValueOf(v).Convert(reflect.TypeFor[int32]()).Interface().(int32)
+		return binary.LittleEndian.AppendUint32(head(bsonInt32), uint32(n)), nil
+	case int, int64, uint32:
+		n := reflect.ValueOf(v).Convert(reflect.TypeFor[int64]()).Interface().(int64)
+		return binary.LittleEndian.AppendUint64(head(bsonInt64), uint64(n)), nil
+	case uint, uint64:
+		n := reflect.ValueOf(v).Uint()
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: %q: integer %d overflows int64", ErrBSON, key, n)
+		}
+		return binary.LittleEndian.AppendUint64(head(bsonInt64), n), nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return binary.LittleEndian.AppendUint64(head(bsonInt64), uint64(n)), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrBSON, key, err)
+		}
+		return binary.LittleEndian.AppendUint64(head(bsonDouble), math.Float64bits(f)), nil
+	case string:
+		b = binary.LittleEndian.AppendUint32(head(bsonString), uint32(len(v)+1))
+		b = append(b, v...)
+		return append(b, 0), nil
+	case []byte:
+		b = binary.LittleEndian.AppendUint32(head(bsonBinary), uint32(len(v)))
+		b = append(b, 0) // generic binary subtype
+		return append(b, v...), nil
+	case time.Time:
+		return binary.LittleEndian.AppendUint64(head(bsonDateTime), uint64(v.UnixMilli())), nil
+	case ObjectID:
+		return append(head(bsonObjectID), v[:]...), nil
+	case map[string]any:
+		b = head(bsonDocument)
+		start := len(b)
+		b = append(b, 0, 0, 0, 0)
+		for _, k := range slices.Sorted(func(yield func(string) bool) {
+			for k := range v {
+				if !yield(k) {
+					return
+				}
+			}
+		}) {
+			var err error
+			if b, err = appendBSONElement(b, k, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return endBSONDocument(b, start), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%w: %q: unsupported type %T", ErrBSON, key, v)
+	}
+	b = head(bsonArray)
+	start := len(b)
+	b = append(b, 0, 0, 0, 0)
+	for i := range rv.Len() {
+		var err error
+		if b, err = appendBSONElement(b, strconv.Itoa(i), rv.Index(i).Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return endBSONDocument(b, start), nil
+}
+
+// UnmarshalBSON replaces the contents of o with the BSON document b,
+// implementing bson.Unmarshaler of the MongoDB Go driver, so that decoded
+// documents keep their order.  Duplicate keys are rejected like UnmarshalJSON,
+// with a *DuplicateError whose Offset is in b.  Nested documents are decoded
+// as OrderedMap and arrays as []any, or OrderedArray with OrderedArrays.
+//
+// Values are decoded as: double as float64, int32 as int32, int64 as int64,
+// string, bool and null as themselves, binary as []byte, datetime as UTC
+// time.Time and ObjectId as ObjectID.  Other types are rejected with an error
+// wrapping ErrBSON.  On error o is not modified.
+func (o *OrderedMap) UnmarshalBSON(b []byte) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	n := o.empty()
+	if err := readBSONMap(b, 0, n, ""); err != nil {
+		return err
+	}
+	return o.replace(func() error {
+		o.keys, o.vals, o.values = n.keys, n.vals, n.values
+		return nil
+	})
+}
+
+// bsonElements returns the elements of the document at the start of b, at
+// offset off of the input, and its length.
+func bsonElements(b []byte, off int) ([]byte, int, error) {
+	if len(b) < 5 {
+		return nil, 0, fmt.Errorf("%w: truncated document at offset %d", ErrBSON, off)
+	}
+	n := int(binary.LittleEndian.Uint32(b))
+	if n < 5 || n > len(b) || b[n-1] != 0 {
+		return nil, 0, fmt.Errorf("%w: document length %d at offset %d", ErrBSON, n, off)
+	}
+	return b[4 : n-1], n, nil
+}
+
+// readBSONMap reads the document b, at offset off of the input, into m at
+// path.
+func readBSONMap(b []byte, off int, m *OrderedMap, path string) error {
+	elems, n, err := bsonElements(b, off)
+	if err != nil {
+		return err
+	}
+	if n != len(b) {
+		return fmt.Errorf("%w: %d bytes after document", ErrBSON, len(b)-n)
+	}
+	pathOf := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	return readBSONElements(elems, off+4, m, pathOf, func(key string, keyOff int, v any) error {
+		if _, dup := m.get(key); dup {
+			return &DuplicateError{Key: key, First: m.keys[m.index(key)], Path: pathOf(key), Offset: int64(keyOff)}
+		}
+		return m.store(key, v)
+	})
+}
+
+// readBSONElements reads the elements elems, at offset off of the input,
+// calling add with each key, its offset and value.  m has the options of
+// nested maps, and pathOf returns the path of a key.
+func readBSONElements(elems []byte, off int, m *OrderedMap, pathOf func(key string) string, add func(key string, off int, v any) error) error {
+	for i := 0; i < len(elems); {
+		end := i + 1
+		for end < len(elems) && elems[end] != 0 {
+			end++
+		}
+		if end == len(elems) {
+			return fmt.Errorf("%w: unterminated key at offset %d", ErrBSON, off+i+1)
+		}
+		key := string(elems[i+1 : end])
+		v, n, err := readBSONValue(elems[i], elems[end+1:], off+end+1, m, pathOf(key))
+		if err != nil {
+			return err
+		}
+		if err := add(key, off+i+1, v); err != nil {
+			return err
+		}
+		i = end + 1 + n
+	}
+	return nil
+}
+
+// readBSONValue reads the value of type t at the start of b, at offset off,
+// and returns it and its length.
+func readBSONValue(t byte, b []byte, off int, m *OrderedMap, path string) (any, int, error) {
+	need := func(n int) error {
+		if len(b) < n {
+			return fmt.Errorf("%w: truncated value of %s at offset %d", ErrBSON, path, off)
+		}
+		return nil
+	}
+	switch t {
+	case bsonNull:
+		return nil, 0, nil
+	case bsonBool:
+		if err := need(1); err != nil {
+			return nil, 0, err
+		}
+		if b[0] > 1 {
+			return nil, 0, fmt.Errorf("%w: boolean %d at offset %d", ErrBSON, b[0], off)
+		}
+		return b[0] == 1, 1, nil
+	case bsonDouble, bsonInt64, bsonDateTime:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+		u := binary.LittleEndian.Uint64(b)
+		switch t {
+		case bsonDouble:
+			return math.Float64frombits(u), 8, nil
+		case bsonInt64:
+			return int64(u), 8, nil
+		}
+		return time.UnixMilli(int64(u)).UTC(), 8, nil
+	case bsonInt32:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+		return int32(binary.LittleEndian.Uint32(b)), 4, nil
+	case bsonObjectID:
+		if err := need(12); err != nil {
+			return nil, 0, err
+		}
+		return ObjectID(b[:12]), 12, nil
+	case bsonString:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		if n < 1 || 4+n > len(b) || b[4+n-1] != 0 {
+			return nil, 0, fmt.Errorf("%w: string length %d at offset %d", ErrBSON, n, off)
+		}
+		return string(b[4 : 4+n-1]), 4 + n, nil
+	case bsonBinary:
+		if err := need(5); err != nil {
+			return nil, 0, err
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		if n < 0 || 5+n > len(b) {
+			return nil, 0, fmt.Errorf("%w: binary length %d at offset %d", ErrBSON, n, off)
+		}
+		return slices.Clone(b[5 : 5+n]), 5 + n, nil
+	case bsonDocument:
+		_, n, err := bsonElements(b, off)
+		if err != nil {
+			return nil, 0, err
+		}
+		nm := m.empty()
+		if err := readBSONMap(b[:n], off, nm, path); err != nil {
+			return nil, 0, err
+		}
+		return *nm, n, nil
+	case bsonArray:
+		elems, n, err := bsonElements(b, off)
+		if err != nil {
+			return nil, 0, err
+		}
+		arr := []any{}
+		pathOf := func(key string) string { return path + "[" + key + "]" }
+		err = readBSONElements(elems, off+4, m, pathOf, func(_ string, _ int, v any) error {
+			arr = appendValue(m.allocator(), arr, v)
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if m.opts != nil && m.opts.arrays {
+			return OrderedArray(arr), n, nil
+		}
+		return arr, n, nil
+	}
+	return nil, 0, fmt.Errorf("%w: unsupported element type 0x%02x of %s at offset %d", ErrBSON, t, path, off)
+}
@@ -0,0 +1,146 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshalBSON(t *testing.T) {
+	// The example of bsonspec.org.
+	o := New()
+	o.Set("hello", "world")
+	b, err := o.MarshalBSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x16\x00\x00\x00\x02hello\x00\x06\x00\x00\x00world\x00\x00"; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+
+	if _, err := New().MarshalBSON(); err != nil {
+		t.Error(err)
+	}
+	for _, v := range []any{make(chan int), uint64(1 << 63), json.Number("x")} {
+		o := New()
+		o.Set("a", v)
+		if _, err := o.MarshalBSON(); !errors.Is(err, ErrBSON) {
+			t.Errorf("%T: %v", v, err)
+		}
+	}
+	o = New()
+	o.Set("a\x00b", 1)
+	if _, err := o.MarshalBSON(); !errors.Is(err, ErrBSON) {
+		t.Error("NUL key:", err)
+	}
+}
+
+func TestBSONRoundTrip(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"z":1.5,"a":{"y":[1,"x",{"q":true}],"b":null}}`)); err != nil {
+		t.Fatal(err)
+	}
+	o.Set("i32", int32(-7))
+	o.Set("i64", int64(1)<<40)
+	o.Set("bin", []byte{1, 2, 3})
+	o.Set("t", time.UnixMilli(1700000000123).UTC())
+	o.Set("id", ObjectID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	b, err := o.MarshalBSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New()
+	if err := m.UnmarshalBSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Keys(), o.Keys()) {
+		t.Error("keys", m.Keys())
+	}
+	for k, v := range o.All() {
+		if _, isMap := v.(OrderedMap); isMap {
+			continue
+		}
+		if !reflect.DeepEqual(m.Get(k), v) {
+			t.Errorf("%s: got %#v, want %#v", k, m.Get(k), v)
+		}
+	}
+	if got, want := m.Get("a").(OrderedMap).String(), `{"y":[1,"x",{"q":true}],"b":null}`; got != want {
+		t.Errorf("nested %s, want %s", got, want)
+	}
+	if b2, err := m.MarshalBSON(); err != nil || string(b2) != string(b) {
+		t.Error("re-encoding differs", err)
+	}
+	if got := m.String(); got != `{"z":1.5,"a":{"y":[1,"x",{"q":true}],"b":null},"i32":-7,"i64":1099511627776,"bin":"AQID","t":"2023-11-14T22:13:20.123Z","id":"0102030405060708090a0b0c"}` {
+		t.Error("JSON", got)
+	}
+
+	// Go maps are encoded in sorted order.
+	o = New()
+	o.Set("m", map[string]any{"b": 1, "a": []string{"x"}})
+	b, err = o.MarshalBSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = New(OrderedArrays())
+	if err := m.UnmarshalBSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.String(); got != `{"m":{"a":["x"],"b":1}}` {
+		t.Error(got)
+	}
+	n := m.Get("m").(OrderedMap)
+	if _, ok := n.Get("a").(OrderedArray); !ok {
+		t.Error("array not OrderedArray")
+	}
+}
+
+func TestUnmarshalBSONErrors(t *testing.T) {
+	o := New()
+	o.Set("keep", true)
+	for _, b := range []string{
+		"",
+		"\x05\x00\x00\x00",
+		"\x06\x00\x00\x00\x00\x00",
+		"\x0b\x00\x00\x00\x10a\x00\x01\x00\x00\x00",     // truncated int32
+		"\x0c\x00\x00\x00\x02a\x00\x09\x00\x00\x00\x00", // string length
+		"\x08\x00\x00\x00\x0b\x00\x00\x00",              // regex type
+		"\x09\x00\x00\x00\x08a\x00\x02\x00",             // boolean 2
+	} {
+		if err := o.UnmarshalBSON([]byte(b)); !errors.Is(err, ErrBSON) {
+			t.Errorf("%q: %v", b, err)
+		}
+	}
+	dup := "\x13\x00\x00\x00\x0aa\x00\x03b\x00\x05\x00\x00\x00\x00\x0aa\x00\x00"
+	err := o.UnmarshalBSON([]byte(dup))
+	var de *DuplicateError
+	if !errors.As(err, &de) || de.Key != "a" || de.Offset != 16 {
+		t.Errorf("duplicate: %v", err)
+	}
+	if o.Len() != 1 || o.Get("keep") != true {
+		t.Error("modified on error", o.String())
+	}
+}
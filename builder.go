@@ -0,0 +1,114 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// Builder builds an OrderedMap with chained calls, e.g.
+//
+//	b, err := NewBuilder().
+//		Set("name", "Alice").
+//		SetIf(admin, "role", "admin").
+//		Object("address", func(b *Builder) { b.Set("city", "Paris") }).
+//		Array("tags", "a", "b").
+//		MarshalJSON()
+//
+// The first error, such as from a validator, is kept and returned by Build
+// and MarshalJSON, and later calls have no effect.
+type Builder struct {
+	m   *OrderedMap
+	err error
+}
+
+// NewBuilder returns a Builder of an OrderedMap configured by opts.  Nested
+// objects have the same options.
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{m: New(opts...)}
+}
+
+// Set sets key to value like OrderedMap.Set.
+func (b *Builder) Set(key string, value any) *Builder {
+	if b.err == nil {
+		b.err = b.m.TrySet(key, value)
+	}
+	return b
+}
+
+// SetIf sets key to value if cond is true.
+func (b *Builder) SetIf(cond bool, key string, value any) *Builder {
+	if cond {
+		b.Set(key, value)
+	}
+	return b
+}
+
+// Object sets key to a nested object built by f.
+func (b *Builder) Object(key string, f func(b *Builder)) *Builder {
+	if b.err != nil {
+		return b
+	}
+	n := &Builder{m: b.m.empty()}
+	f(n)
+	if n.err != nil {
+		b.err = n.err
+		return b
+	}
+	return b.Set(key, *n.m)
+}
+
+// Array sets key to a []any of values.  Values that are a *Builder are built
+// into nested objects.
+func (b *Builder) Array(key string, values ...any) *Builder {
+	if b.err != nil {
+		return b
+	}
+	a := make([]any, len(values))
+	for i, v := range values {
+		if n, ok := v.(*Builder); ok {
+			m, err := n.Build()
+			if err != nil {
+				b.err = err
+				return b
+			}
+			v = *m
+		}
+		a[i] = v
+	}
+	return b.Set(key, a)
+}
+
+// Build returns the built map, or the first error.  Building again returns
+// the same map.
+func (b *Builder) Build() (*OrderedMap, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.m, nil
+}
+
+// MarshalJSON returns the JSON encoding of the built map, or the first error.
+func (b *Builder) MarshalJSON() ([]byte, error) {
+	m, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return m.MarshalJSON()
+}
@@ -0,0 +1,62 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	b, err := NewBuilder().
+		Set("name", "Alice").
+		SetIf(false, "role", "admin").
+		SetIf(true, "id", 1).
+		Object("address", func(b *Builder) { b.Set("zip", "75001").Set("city", "Paris") }).
+		Array("tags", "a", NewBuilder().Set("z", true).Set("y", nil)).
+		MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"Alice","id":1,"address":{"zip":"75001","city":"Paris"},"tags":["a",{"z":true,"y":null}]}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	errBad := errors.New("bad")
+	o := New()
+	o.SetValidator(func(key string, value any) error {
+		if key == "bad" {
+			return errBad
+		}
+		return nil
+	})
+	bl := &Builder{m: o}
+	bl.Object("a", func(b *Builder) { b.Set("bad", 1) }).Set("b", 2)
+	if _, err := bl.Build(); !errors.Is(err, errBad) {
+		t.Errorf("got %v, want %v", err, errBad)
+	}
+	if o.Len() != 0 {
+		t.Errorf("got %d keys after error", o.Len())
+	}
+}
@@ -0,0 +1,144 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "container/list"
+
+// EvictionPolicy is the order in which a Cache evicts entries.
+type EvictionPolicy int
+
+const (
+	// FIFO evicts the least recently inserted entry.
+	FIFO EvictionPolicy = iota
+	// LRU evicts the least recently used entry.  Get and Set of an existing key
+	// count as use.
+	LRU
+)
+
+// Cache is an ordered map bounded to a capacity.  Its order is the eviction
+// order, oldest first: setting a new key beyond capacity evicts the entry at
+// the front.  Get, Set and Delete take constant time.  A Cache is not safe for
+// concurrent use.
+type Cache struct {
+	// entries holds the *cacheEntry values in eviction order.
+	entries  list.List
+	index    map[string]*list.Element
+	capacity int
+	policy   EvictionPolicy
+	onEvict  func(key string, value any)
+}
+
+type cacheEntry struct {
+	key   string
+	value any
+}
+
+// NewCache returns an empty Cache holding at most capacity entries.  onEvict,
+// if not nil, is called with each evicted entry, but not with deleted ones.
+// NewCache panics if capacity is less than 1.
+func NewCache(capacity int, policy EvictionPolicy, onEvict func(key string, value any)) *Cache {
+	if capacity < 1 {
+		panic("orderedmap: cache capacity must be positive")
+	}
+	return &Cache{index: make(map[string]*list.Element), capacity: capacity, policy: policy, onEvict: onEvict}
+}
+
+// Get returns the value for key and whether it exists.  For LRU it marks key
+// as most recently used.
+func (c *Cache) Get(key string) (any, bool) {
+	e, ok := c.index[key]
+	if !ok {
+		return nil, false
+	}
+	if c.policy == LRU {
+		c.entries.MoveToBack(e)
+	}
+	return e.Value.(*cacheEntry).value, true
+}
+
+// Peek is Get without marking key as used.
+func (c *Cache) Peek(key string) (any, bool) {
+	e, ok := c.index[key]
+	if !ok {
+		return nil, false
+	}
+	return e.Value.(*cacheEntry).value, true
+}
+
+// Set sets the value for key, evicting the oldest entry if a new key exceeds
+// the capacity.  For LRU it marks key as most recently used.
+func (c *Cache) Set(key string, value any) {
+	if e, ok := c.index[key]; ok {
+		e.Value.(*cacheEntry).value = value
+		if c.policy == LRU {
+			c.entries.MoveToBack(e)
+		}
+		return
+	}
+	c.index[key] = c.entries.PushBack(&cacheEntry{key, value})
+	if c.entries.Len() > c.capacity {
+		oldest := c.entries.Remove(c.entries.Front()).(*cacheEntry)
+		delete(c.index, oldest.key)
+		if c.onEvict != nil {
+			c.onEvict(oldest.key, oldest.value)
+		}
+	}
+}
+
+// Delete deletes key without calling the eviction callback.
+func (c *Cache) Delete(key string) {
+	if e, ok := c.index[key]; ok {
+		c.entries.Remove(e)
+		delete(c.index, key)
+	}
+}
+
+// Len returns the number of entries.
+func (c *Cache) Len() int {
+	return c.entries.Len()
+}
+
+// Cap returns the capacity.
+func (c *Cache) Cap() int {
+	return c.capacity
+}
+
+// Keys returns a copy of the keys in eviction order, oldest first.
+func (c *Cache) Keys() []string {
+	keys := make([]string, 0, c.entries.Len())
+	for e := c.entries.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*cacheEntry).key)
+	}
+	return keys
+}
+
+// Map returns a new OrderedMap of the entries in eviction order, e.g. for
+// marshaling.  Modifying it does not modify c.
+func (c *Cache) Map() *OrderedMap {
+	o := New()
+	for e := c.entries.Front(); e != nil; e = e.Next() {
+		ce := e.Value.(*cacheEntry)
+		o.set(ce.key, ce.value)
+	}
+	return o
+}
@@ -0,0 +1,103 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	for _, policy := range []EvictionPolicy{FIFO, LRU} {
+		var evicted []string
+		c := NewCache(3, policy, func(key string, value any) { evicted = append(evicted, key) })
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Set("c", 3)
+		c.Get("a")
+		c.Set("b", 20)
+		c.Set("d", 4)
+
+		want, wantEvicted := []string{"b", "c", "d"}, []string{"a"}
+		if policy == LRU {
+			want, wantEvicted = []string{"a", "b", "d"}, []string{"c"}
+		}
+		if !reflect.DeepEqual(c.Keys(), want) || !reflect.DeepEqual(evicted, wantEvicted) {
+			t.Errorf("policy %d: keys %v, evicted %v", policy, c.Keys(), evicted)
+		}
+		if v, ok := c.Peek("b"); !ok || v != 20 {
+			t.Errorf("policy %d: Peek %v %v", policy, v, ok)
+		}
+		c.Delete("d")
+		if c.Len() != 2 || len(evicted) != 1 {
+			t.Errorf("policy %d: Delete called onEvict", policy)
+		}
+	}
+
+	// Beyond smallSize.
+	c := NewCache(smallSize*2, LRU, nil)
+	for i := 0; i < smallSize*3; i++ {
+		c.Set(strconv.Itoa(i), i)
+		c.Get("0")
+	}
+	if c.Len() != c.Cap() || c.Keys()[c.Len()-1] != "0" {
+		t.Error("large LRU", c.Keys())
+	}
+	if v, ok := c.Get(strconv.Itoa(smallSize*3 - 1)); !ok || v != smallSize*3-1 {
+		t.Error("large Get", v, ok)
+	}
+}
+
+func TestCache_Map(t *testing.T) {
+	c := NewCache(2, LRU, nil)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	m := c.Map()
+	if b, _ := m.MarshalJSON(); string(b) != `{"b":2,"a":1}` {
+		t.Errorf("Map got %s", b)
+	}
+	m.Delete("a")
+	if _, ok := c.Peek("a"); !ok {
+		t.Error("Map is not a copy")
+	}
+}
+
+// BenchmarkCache measures LRU hits, which must not depend on the capacity.
+func BenchmarkCache(b *testing.B) {
+	for _, n := range []int{16, 1024, 65536} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			c := NewCache(n, LRU, nil)
+			keys := make([]string, n)
+			for i := range keys {
+				keys[i] = strconv.Itoa(i)
+				c.Set(keys[i], i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Get(keys[i%n])
+			}
+		})
+	}
+}
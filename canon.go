@@ -0,0 +1,49 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "fmt"
+
+// Canon returns a new OrderedMap, with the options of o, of the given fields
+// of o in the order of fields, as the canonical form of o for the canon fields
+// in Coze.  Fields of o not in fields are omitted.  It returns an error
+// wrapping ErrKeyNotFound if a field does not exist in o, and wrapping
+// ErrJSONDuplicate if a field is given twice.  With no fields, the canon is
+// the keys of o.  o is not modified.
+func (o *OrderedMap) Canon(fields ...string) (*OrderedMap, error) {
+	if len(fields) == 0 {
+		fields = o.keys
+	}
+	m := o.empty()
+	for _, f := range fields {
+		v, ok := o.get(f)
+		if !ok {
+			return nil, fmt.Errorf("%w: canon field %q", ErrKeyNotFound, f)
+		}
+		if _, ok := m.get(f); ok {
+			return nil, fmt.Errorf("%w %q in canon", ErrJSONDuplicate, f)
+		}
+		m.set(f, v)
+	}
+	return m, nil
+}
@@ -0,0 +1,59 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanon(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"tmb":"U5X","alg":"ES256","msg":"hi","typ":"cyphr.me/msg"}`)); err != nil {
+		t.Fatal(err)
+	}
+	c, err := o.Canon("alg", "tmb", "typ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"alg":"ES256","tmb":"U5X","typ":"cyphr.me/msg"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if o.Len() != 4 {
+		t.Error("o modified")
+	}
+
+	if c, err := o.Canon(); err != nil || !c.EqualOrdered(o) {
+		t.Error("canon of no fields:", c, err)
+	}
+	if _, err := o.Canon("alg", "iat"); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("missing field:", err)
+	}
+	if _, err := o.Canon("alg", "alg"); !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("repeated field:", err)
+	}
+}
@@ -0,0 +1,132 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Codec encodes maps to and decodes maps from a format other than the one of
+// MarshalJSON, such as YAML or CBOR, so that format support can be added by
+// other packages.  See RegisterCodec.
+type Codec interface {
+	Encode(o *OrderedMap) ([]byte, error)
+	Decode(b []byte) (*OrderedMap, error)
+}
+
+// ErrUnknownCodec is returned for a codec name that is not registered.
+var ErrUnknownCodec = errors.New("orderedmap: unknown codec")
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"bson":       funcCodec{OrderedMap.MarshalBSON, (*OrderedMap).UnmarshalBSON},
+		"ini":        funcCodec{OrderedMap.MarshalINI, (*OrderedMap).UnmarshalINI},
+		"json":       funcCodec{OrderedMap.MarshalJSON, (*OrderedMap).UnmarshalJSON},
+		"properties": funcCodec{OrderedMap.MarshalProperties, (*OrderedMap).UnmarshalProperties},
+	}
+)
+
+// RegisterCodec makes c available by name to Encode, Decode and LookupCodec,
+// typically from the init function of the package implementing it.  The
+// codecs "bson", "ini", "json" and "properties", of the methods of the same
+// formats, are registered by default.  RegisterCodec panics if c is nil or
+// name is already registered.
+func RegisterCodec(name string, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if c == nil {
+		panic("orderedmap: RegisterCodec of nil codec " + name)
+	}
+	if _, dup := codecs[name]; dup {
+		panic("orderedmap: RegisterCodec called twice for codec " + name)
+	}
+	codecs[name] = c
+}
+
+// LookupCodec returns the codec registered by name and whether it exists.
+func LookupCodec(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[name]
+	return c, ok
+}
+
+// Codecs returns the names of the registered codecs, sorted.
+func Codecs() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Encode returns o in the format of the codec registered by name.
+func (o *OrderedMap) Encode(name string) ([]byte, error) {
+	c, err := codecFor(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Encode(o)
+}
+
+// Decode returns the map b decodes to with the codec registered by name.
+func Decode(name string, b []byte) (*OrderedMap, error) {
+	c, err := codecFor(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Decode(b)
+}
+
+func codecFor(name string) (Codec, error) {
+	c, ok := LookupCodec(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCodec, name)
+	}
+	return c, nil
+}
+
+// funcCodec is the Codec of a pair of marshal and unmarshal methods.
+type funcCodec struct {
+	marshal   func(OrderedMap) ([]byte, error)
+	unmarshal func(*OrderedMap, []byte) error
+}
+
+func (c funcCodec) Encode(o *OrderedMap) ([]byte, error) {
+	return c.marshal(*o)
+}
+
+func (c funcCodec) Decode(b []byte) (*OrderedMap, error) {
+	o := New()
+	if err := c.unmarshal(o, b); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -0,0 +1,106 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// lineCodec is a Codec of one "key value" line per member with string values.
+type lineCodec struct{}
+
+func (lineCodec) Encode(o *OrderedMap) ([]byte, error) {
+	var buf bytes.Buffer
+	for k, v := range o.All() {
+		s, _ := v.(string)
+		buf.WriteString(k + " " + s + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+func (lineCodec) Decode(b []byte) (*OrderedMap, error) {
+	o := New()
+	for _, line := range bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n")) {
+		k, v, _ := bytes.Cut(line, []byte(" "))
+		o.Set(string(k), string(v))
+	}
+	return o, nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("test-lines", lineCodec{})
+	if !slices.Contains(Codecs(), "test-lines") {
+		t.Errorf("codec not listed: %q", Codecs())
+	}
+	o, err := Decode("test-lines", []byte("b 1\na 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.String(); got != `{"b":"1","a":"2"}` {
+		t.Errorf("got %s", got)
+	}
+	b, err := o.Encode("test-lines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "b 1\na 2\n" {
+		t.Errorf("got %q", b)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate registration did not panic")
+		}
+	}()
+	RegisterCodec("test-lines", lineCodec{})
+}
+
+func TestCodecs_Builtin(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"z":"1","a":{"b":"2"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"bson", "ini", "json", "properties"} {
+		b, err := o.Encode(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		d, err := Decode(name, b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !d.EqualOrdered(o) {
+			t.Errorf("%s: got %s, want %s", name, d, o)
+		}
+	}
+
+	if _, err := Decode("nope", nil); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("got %v, want ErrUnknownCodec", err)
+	}
+	if _, err := o.Encode("nope"); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("got %v, want ErrUnknownCodec", err)
+	}
+}
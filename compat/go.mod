@@ -0,0 +1,27 @@
+module github.com/cyphrme/orderedmap/compat
+
+go 1.23
+
+require (
+	github.com/bytedance/sonic v1.15.4
+	github.com/cyphrme/orderedmap v0.0.0
+	github.com/json-iterator/go v1.1.12
+	github.com/minio/simdjson-go v0.4.5
+	github.com/modern-go/reflect2 v1.0.2
+	golang.org/x/text v0.21.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/bytedance/gopkg v0.1.3 // indirect
+	github.com/bytedance/sonic/loader v0.5.2 // indirect
+	github.com/cloudwego/base64x v0.1.6 // indirect
+	github.com/klauspost/compress v1.15.15 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.9 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
+	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
+	golang.org/x/arch v0.0.0-20210923205945-b76863e36670 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+)
+
+replace github.com/cyphrme/orderedmap => ../
@@ -0,0 +1,46 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omcollate sorts OrderedMap keys in the order of a language, using
+// golang.org/x/text/collate, e.g.
+//
+//	o.SortKeys(orderedmap.SortFunc(omcollate.Less(language.German)))
+package omcollate
+
+import (
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Less returns a less func ordering strings as in language tag, for
+// orderedmap.SortFunc, orderedmap.ByKey and orderedmap.NewSorted.  Strings
+// that collate equally compare bytewise, so that the order is total.  The func
+// is not safe for concurrent use.
+func Less(tag language.Tag, opts ...collate.Option) func(a, b string) bool {
+	c := collate.New(tag, opts...)
+	return func(a, b string) bool {
+		if r := c.CompareString(a, b); r != 0 {
+			return r < 0
+		}
+		return a < b
+	}
+}
@@ -0,0 +1,50 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omcollate
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+func TestLess(t *testing.T) {
+	o := orderedmap.New()
+	for _, k := range []string{"zebra", "Äpfel", "apfel", "Bär", "ähnlich"} {
+		o.Set(k, true)
+	}
+	o.SortKeys(orderedmap.SortFunc(Less(language.German)))
+	want := []string{"ähnlich", "apfel", "Äpfel", "Bär", "zebra"}
+	if !slices.Equal(o.Keys(), want) {
+		t.Errorf("got %v, want %v", o.Keys(), want)
+	}
+
+	o.SortKeys(orderedmap.SortFunc(Less(language.Swedish, collate.IgnoreCase)))
+	want = []string{"apfel", "Bär", "zebra", "ähnlich", "Äpfel"}
+	if !slices.Equal(o.Keys(), want) {
+		t.Errorf("Swedish: got %v, want %v", o.Keys(), want)
+	}
+}
@@ -0,0 +1,87 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omjsoniter registers OrderedMap with json-iterator/go.
+package omjsoniter
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/cyphrme/orderedmap"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/modern-go/reflect2"
+)
+
+var mapType = reflect.TypeOf(orderedmap.OrderedMap{})
+
+// Register registers an extension with api that encodes and decodes
+// OrderedMap, and *OrderedMap, with its own MarshalJSON and UnmarshalJSON,
+// preserving order and rejecting duplicates regardless of api's
+// configuration.  Register must be called before api is first used with
+// OrderedMap, as api caches encoders and decoders.
+func Register(api jsoniter.API) {
+	api.RegisterExtension(&extension{})
+}
+
+type extension struct {
+	jsoniter.DummyExtension
+}
+
+func (*extension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	if typ.Type1() == mapType {
+		return codec{}
+	}
+	return nil
+}
+
+func (*extension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
+	if typ.Type1() == mapType {
+		return codec{}
+	}
+	return nil
+}
+
+type codec struct{}
+
+func (codec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	b := iter.SkipAndReturnBytes()
+	if iter.Error != nil {
+		return
+	}
+	if err := (*orderedmap.OrderedMap)(ptr).UnmarshalJSON(b); err != nil {
+		iter.ReportError("orderedmap", err.Error())
+	}
+}
+
+func (codec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	b, err := (*orderedmap.OrderedMap)(ptr).MarshalJSON()
+	if err != nil {
+		stream.Error = err
+		return
+	}
+	stream.Write(b)
+}
+
+func (codec) IsEmpty(ptr unsafe.Pointer) bool {
+	return (*orderedmap.OrderedMap)(ptr).Len() == 0
+}
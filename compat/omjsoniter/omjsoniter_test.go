@@ -0,0 +1,64 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omjsoniter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+	jsoniter "github.com/json-iterator/go"
+)
+
+func TestRegister(t *testing.T) {
+	for name, cfg := range map[string]jsoniter.Config{
+		"std":     {EscapeHTML: true, SortMapKeys: true, ValidateJsonRawMessage: true},
+		"default": {EscapeHTML: true},
+		"fastest": {EscapeHTML: false, MarshalFloatWith6Digits: true, ObjectFieldMustBeSimpleString: true},
+	} {
+		api := cfg.Froze()
+		Register(api)
+
+		var v struct {
+			M orderedmap.OrderedMap
+			P *orderedmap.OrderedMap
+			E orderedmap.OrderedMap `json:",omitempty"`
+		}
+		s := `{"M":{"z":1,"a":{"y":[1,{"c":true,"b":null}]}},"P":{"d":"x","c":"y"}}`
+		if err := api.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(name, err)
+		}
+		b, err := api.Marshal(v)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if string(b) != s {
+			t.Error(name, "round trip", string(b))
+		}
+
+		err = api.Unmarshal([]byte(`{"M":{"a":{"x":1,"x":2}}}`), &v)
+		if err == nil || !strings.Contains(err.Error(), "duplicate") {
+			t.Error(name, "did not reject duplicate", err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omnorm applies Unicode normalization, from
+// golang.org/x/text/unicode/norm, to OrderedMap keys.
+package omnorm
+
+import (
+	"encoding/json"
+
+	"github.com/cyphrme/orderedmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// CheckDuplicate is orderedmap.CheckDuplicateStrict with NFKC normalization,
+// rejecting keys that are equal after NFC or NFKC normalization or case
+// folding.
+func CheckDuplicate(d *json.Decoder) error {
+	return orderedmap.CheckDuplicateStrict(d, norm.NFKC.String)
+}
+
+// NFC returns an option making keys that are equal after NFC normalization the
+// same key, keeping the form a key was first set with.  See
+// orderedmap.NormalizeKeys.
+func NFC() orderedmap.Option {
+	return orderedmap.NormalizeKeys(norm.NFC.String, true)
+}
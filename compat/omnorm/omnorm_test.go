@@ -0,0 +1,57 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omnorm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+func TestCheckDuplicate(t *testing.T) {
+	for _, c := range []struct {
+		in, first, key string
+	}{
+		{`{"caf\u00e9":1,"cafe\u0301":2}`, "caf\u00e9", "cafe\u0301"}, // NFC
+		{`{"\ufb01le":1,"file":2}`, "\ufb01le", "file"},               // NFKC ligature
+		{`{"a":{"Alg":1,"alg":2}}`, "Alg", "alg"},                     // case folding
+	} {
+		var de *orderedmap.DuplicateError
+		err := CheckDuplicate(json.NewDecoder(bytes.NewReader([]byte(c.in))))
+		if !errors.As(err, &de) || de.First != c.first || de.Key != c.key {
+			t.Errorf("%s: got %v", c.in, err)
+		}
+	}
+	if err := CheckDuplicate(json.NewDecoder(bytes.NewReader([]byte(`{"a":1,"b":2}`)))); err != nil {
+		t.Error(err)
+	}
+
+	o := orderedmap.New(NFC())
+	o.Set("cafe\u0301", 1)
+	if o.Get("caf\u00e9") != 1 {
+		t.Error("NFC lookup")
+	}
+}
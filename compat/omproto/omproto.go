@@ -0,0 +1,151 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omproto converts OrderedMap to and from protobuf's google.protobuf.Struct,
+// carrying key order, which Struct does not preserve, in a sidecar list.
+package omproto
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cyphrme/orderedmap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ToProtoStruct returns o as a Struct and its key order.  The order lists the
+// path of every key of o and its nested maps, depth-first, as an RFC 6901 JSON
+// Pointer, e.g. "/payload/alg" or "/sigs/0/alg", for sending alongside the
+// Struct, e.g. as a repeated string field.  Values must be representable by
+// structpb.NewValue.
+func ToProtoStruct(o *orderedmap.OrderedMap) (*structpb.Struct, []string, error) {
+	var order []string
+	s, err := toStruct(o, "", &order)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, order, nil
+}
+
+func toStruct(o *orderedmap.OrderedMap, path string, order *[]string) (*structpb.Struct, error) {
+	s := &structpb.Struct{Fields: make(map[string]*structpb.Value, o.Len())}
+	for k, v := range o.All() {
+		p := path + "/" + escape(k)
+		*order = append(*order, p)
+		pv, err := toValue(v, p, order)
+		if err != nil {
+			return nil, err
+		}
+		s.Fields[k] = pv
+	}
+	return s, nil
+}
+
+func toValue(v any, path string, order *[]string) (*structpb.Value, error) {
+	switch t := v.(type) {
+	case orderedmap.OrderedMap:
+		return toValue(&t, path, order)
+	case *orderedmap.OrderedMap:
+		s, err := toStruct(t, path, order)
+		if err != nil {
+			return nil, err
+		}
+		return structpb.NewStructValue(s), nil
+	case []any:
+		l := &structpb.ListValue{Values: make([]*structpb.Value, len(t))}
+		for i, e := range t {
+			var err error
+			if l.Values[i], err = toValue(e, path+"/"+strconv.Itoa(i), order); err != nil {
+				return nil, err
+			}
+		}
+		return structpb.NewListValue(l), nil
+	}
+	return structpb.NewValue(v)
+}
+
+// FromProtoStruct returns s as an OrderedMap, with keys ordered by order as
+// returned by ToProtoStruct.  Nested structs are OrderedMap values, as decoded
+// by UnmarshalJSON.  Keys missing from order follow the ordered keys, sorted.
+func FromProtoStruct(s *structpb.Struct, order []string) *orderedmap.OrderedMap {
+	// children maps the path of each map to its keys, in order.
+	children := make(map[string][]string)
+	for _, p := range order {
+		i := strings.LastIndexByte(p, '/')
+		if i < 0 {
+			continue
+		}
+		children[p[:i]] = append(children[p[:i]], unescape(p[i+1:]))
+	}
+	o := fromStruct(s, "", children)
+	return &o
+}
+
+func fromStruct(s *structpb.Struct, path string, children map[string][]string) orderedmap.OrderedMap {
+	var o orderedmap.OrderedMap
+	fields := s.GetFields()
+	for _, k := range children[path] {
+		if v, ok := fields[k]; ok {
+			o.Set(k, fromValue(v, path+"/"+escape(k), children))
+		}
+	}
+	var rest []string
+	for k := range fields {
+		if !o.Entry(k).Exists() {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		o.Set(k, fromValue(fields[k], path+"/"+escape(k), children))
+	}
+	return o
+}
+
+func fromValue(v *structpb.Value, path string, children map[string][]string) any {
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		return fromStruct(k.StructValue, path, children)
+	case *structpb.Value_ListValue:
+		s := make([]any, len(k.ListValue.GetValues()))
+		for i, e := range k.ListValue.GetValues() {
+			s[i] = fromValue(e, path+"/"+strconv.Itoa(i), children)
+		}
+		return s
+	}
+	return v.AsInterface()
+}
+
+var (
+	escaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// escape escapes key as a JSON Pointer reference token.
+func escape(key string) string {
+	return escaper.Replace(key)
+}
+
+func unescape(token string) string {
+	return unescaper.Replace(token)
+}
@@ -0,0 +1,68 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omproto
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProtoStruct(t *testing.T) {
+	in := `{"z":1,"a/b":{"y":true,"x":null},"sigs":[{"tmb":"t","alg":"ES256"}],"m":"s"}`
+	o := orderedmap.New()
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	s, order, err := ToProtoStruct(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/z", "/a~1b", "/a~1b/y", "/a~1b/x", "/sigs", "/sigs/0/tmb", "/sigs/0/alg", "/m"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order %v, want %v", order, want)
+	}
+
+	// Cross a serialization boundary, which loses Go map order.
+	b, err := proto.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2 := new(structpb.Struct)
+	if err = proto.Unmarshal(b, s2); err != nil {
+		t.Fatal(err)
+	}
+	got, err := FromProtoStruct(s2, order).MarshalJSON()
+	if err != nil || string(got) != in {
+		t.Errorf("round trip %s, %v", got, err)
+	}
+
+	// Keys missing from order are sorted.
+	got, _ = FromProtoStruct(s2, nil).MarshalJSON()
+	if string(got) != `{"a/b":{"x":null,"y":true},"m":"s","sigs":[{"alg":"ES256","tmb":"t"}],"z":1}` {
+		t.Errorf("without order %s", got)
+	}
+}
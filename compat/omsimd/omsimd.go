@@ -0,0 +1,169 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omsimd is an OrderedMap decode backend using minio/simdjson-go.
+//
+// simdjson-go requires a CPU with AVX2 and CLMUL.  New reports whether the
+// backend is supported, so that callers can fall back to encoding/json:
+//
+//	opts := []orderedmap.Option{}
+//	if b, err := omsimd.New(); err == nil {
+//		opts = append(opts, orderedmap.WithBackend(b))
+//	}
+//	o := orderedmap.New(opts...)
+package omsimd
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cyphrme/orderedmap"
+	"github.com/minio/simdjson-go"
+)
+
+// ErrUnsupportedCPU is returned by New if the CPU is not supported by
+// simdjson-go.
+var ErrUnsupportedCPU = errors.New("omsimd: CPU not supported by simdjson-go")
+
+// backend is the simdjson-go Backend.  It reuses parsed tapes across calls.
+type backend struct {
+	pool sync.Pool
+}
+
+// New returns a Backend parsing with simdjson-go, or ErrUnsupportedCPU.
+func New() (orderedmap.Backend, error) {
+	if !simdjson.SupportedCPU() {
+		return nil, ErrUnsupportedCPU
+	}
+	return &backend{}, nil
+}
+
+// Parse implements orderedmap.Backend.  Integers are reported as float64,
+// like encoding/json.
+func (b *backend) Parse(data []byte, h orderedmap.Handler) error {
+	reuse, _ := b.pool.Get().(*simdjson.ParsedJson)
+	pj, err := simdjson.Parse(data, reuse)
+	if err != nil {
+		return err
+	}
+	defer b.pool.Put(pj)
+	iter := pj.Iter()
+	for {
+		switch t := iter.Advance(); t {
+		case simdjson.TypeNone:
+			return nil
+		case simdjson.TypeRoot:
+			t, root, err := iter.Root(nil)
+			if err != nil {
+				return err
+			}
+			if err := walk(root, t, h); err != nil {
+				return err
+			}
+		default:
+			return errors.New("omsimd: unexpected element outside root")
+		}
+	}
+}
+
+// walk reports the element of type t at i to h.
+func walk(i *simdjson.Iter, t simdjson.Type, h orderedmap.Handler) error {
+	switch t {
+	case simdjson.TypeObject:
+		obj, err := i.Object(nil)
+		if err != nil {
+			return err
+		}
+		if err := h.BeginObject(); err != nil {
+			return err
+		}
+		var elem simdjson.Iter
+		for {
+			name, t, err := obj.NextElement(&elem)
+			if err != nil {
+				return err
+			}
+			if t == simdjson.TypeNone {
+				break
+			}
+			if err := h.Key(name); err != nil {
+				return err
+			}
+			if err := walk(&elem, t, h); err != nil {
+				return err
+			}
+		}
+		return h.EndObject()
+	case simdjson.TypeArray:
+		arr, err := i.Array(nil)
+		if err != nil {
+			return err
+		}
+		if err := h.BeginArray(); err != nil {
+			return err
+		}
+		elems := arr.Iter()
+		for {
+			t := elems.Advance()
+			if t == simdjson.TypeNone {
+				break
+			}
+			if err := walk(&elems, t, h); err != nil {
+				return err
+			}
+		}
+		return h.EndArray()
+	case simdjson.TypeNull:
+		return h.Value(nil)
+	case simdjson.TypeBool:
+		v, err := i.Bool()
+		if err != nil {
+			return err
+		}
+		return h.Value(v)
+	case simdjson.TypeString:
+		v, err := i.String()
+		if err != nil {
+			return err
+		}
+		return h.Value(v)
+	case simdjson.TypeInt:
+		v, err := i.Int()
+		if err != nil {
+			return err
+		}
+		return h.Value(float64(v))
+	case simdjson.TypeUint:
+		v, err := i.Uint()
+		if err != nil {
+			return err
+		}
+		return h.Value(float64(v))
+	case simdjson.TypeFloat:
+		v, err := i.Float()
+		if err != nil {
+			return err
+		}
+		return h.Value(v)
+	}
+	return errors.New("omsimd: unexpected element type " + t.String())
+}
@@ -0,0 +1,62 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omsimd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+func TestBackend(t *testing.T) {
+	b, err := New()
+	if errors.Is(err, ErrUnsupportedCPU) {
+		t.Skip(err)
+	}
+	s := `{"z":1,"a":{"y":[1,-2,1.5,18446744073709551615,{"c":true,"b":null}]},"d":"x","e":[],"f":{}}`
+	want := orderedmap.New()
+	if err := want.UnmarshalJSON([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	o := orderedmap.New(orderedmap.WithBackend(b))
+	for range 2 { // reusing the parsed tape
+		if err := o.UnmarshalJSON([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		if !o.EqualOrdered(want) {
+			t.Errorf("got %v, want %v", o, want)
+		}
+	}
+
+	var de *orderedmap.DuplicateError
+	err = o.UnmarshalJSON([]byte(`{"a":[{"x":1,"x":2}]}`))
+	if !errors.As(err, &de) || de.Path != "a[0].x" {
+		t.Errorf("did not reject duplicate: %v", err)
+	}
+	for _, s := range []string{`[1]`, `{"a":}`, `{}{}`} {
+		if err := o.UnmarshalJSON([]byte(s)); err == nil {
+			t.Errorf("%s: no error", s)
+		}
+	}
+}
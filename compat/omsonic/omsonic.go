@@ -0,0 +1,44 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omsonic supports using OrderedMap with bytedance/sonic.
+//
+// Every sonic configuration encodes and decodes OrderedMap with its own
+// MarshalJSON and UnmarshalJSON, preserving order and rejecting duplicates, as
+// tested by this package.  Pretouch avoids the latency of sonic compiling
+// OrderedMap on first use.
+package omsonic
+
+import (
+	"reflect"
+
+	"github.com/bytedance/sonic"
+	"github.com/cyphrme/orderedmap"
+)
+
+// Pretouch compiles OrderedMap, and *OrderedMap, for sonic ahead of time.
+func Pretouch() error {
+	if err := sonic.Pretouch(reflect.TypeOf(orderedmap.OrderedMap{})); err != nil {
+		return err
+	}
+	return sonic.Pretouch(reflect.TypeOf(&orderedmap.OrderedMap{}))
+}
@@ -0,0 +1,104 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"iter"
+	"sort"
+)
+
+// Collect returns a new OrderedMap of the key-value pairs from seq, in order.
+// A repeated key keeps its first position and its last value.
+func Collect(seq iter.Seq2[string, any]) *OrderedMap {
+	o := New()
+	for k, v := range seq {
+		o.Set(k, v)
+	}
+	return o
+}
+
+// ToMap returns the contents of o as a plain map[string]any.  Nested
+// OrderedMaps, including those in slices, are converted to plain maps as well.
+// o is not modified.
+func (o *OrderedMap) ToMap() map[string]any {
+	m := make(map[string]any, len(o.keys))
+	for i, k := range o.keys {
+		m[k] = toPlain(o.valueAt(i))
+	}
+	return m
+}
+
+// toPlain returns v with nested OrderedMaps converted to plain maps.
+func toPlain(v any) any {
+	if m, ok := asMap(v); ok {
+		return m.ToMap()
+	}
+	if s, ok := asSlice(v); ok {
+		c := make([]any, len(s))
+		for i, e := range s {
+			c[i] = toPlain(e)
+		}
+		return c
+	}
+	return v
+}
+
+// FromMapDeep returns a new OrderedMap of m, recursively converting nested
+// map[string]any, including those in []any, into OrderedMaps, as decoded by
+// UnmarshalJSON.  keyOrder orders the keys of each map and is sort.Strings if
+// nil.  m is not modified.
+func FromMapDeep(m map[string]any, keyOrder func(keys []string)) *OrderedMap {
+	if keyOrder == nil {
+		keyOrder = sort.Strings
+	}
+	o := fromMapDeep(m, keyOrder)
+	return &o
+}
+
+func fromMapDeep(m map[string]any, keyOrder func(keys []string)) OrderedMap {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	keyOrder(keys)
+	o := OrderedMap{}
+	for _, k := range keys {
+		o.Set(k, fromPlain(m[k], keyOrder))
+	}
+	return o
+}
+
+// fromPlain returns v with nested plain maps converted to OrderedMaps.
+func fromPlain(v any, keyOrder func(keys []string)) any {
+	switch t := v.(type) {
+	case map[string]any:
+		return fromMapDeep(t, keyOrder)
+	case []any:
+		c := make([]any, len(t))
+		for i, e := range t {
+			c[i] = fromPlain(e, keyOrder)
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	seq := func(yield func(string, any) bool) {
+		for _, k := range []string{"z", "a", "z", "m"} {
+			if !yield(k, len(k)) {
+				return
+			}
+		}
+	}
+	o := Collect(seq)
+	if !reflect.DeepEqual(o.Keys(), []string{"z", "a", "m"}) {
+		t.Error("Collect key order", o.Keys())
+	}
+}
+
+func TestOrderedMap_ToMap(t *testing.T) {
+	o := New()
+	err := json.Unmarshal([]byte(`{"a":{"b":[{"c":1}, 2]},"d":"x"}`), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.Set("e", New())
+
+	expected := map[string]any{
+		"a": map[string]any{"b": []any{map[string]any{"c": float64(1)}, float64(2)}},
+		"d": "x",
+		"e": map[string]any{},
+	}
+	m := o.ToMap()
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("ToMap %#v != %#v", m, expected)
+	}
+	a := o.Get("a").(OrderedMap)
+	if _, ok := a.Get("b").([]any)[0].(OrderedMap); !ok {
+		t.Error("ToMap modified the OrderedMap")
+	}
+}
+
+func TestFromMapDeep(t *testing.T) {
+	m := map[string]any{
+		"c": 1,
+		"a": map[string]any{"z": 1, "y": []any{map[string]any{"q": 1, "p": 2}}},
+		"b": []any{"x"},
+	}
+	o := FromMapDeep(m, nil)
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"a":{"y":[{"p":2,"q":1}],"z":1},"b":["x"],"c":1}`
+	if string(b) != expected {
+		t.Error("FromMapDeep", string(b), "!=", expected)
+	}
+	if _, ok := o.Get("a").(OrderedMap); !ok {
+		t.Errorf("nested map is not an OrderedMap: %T", o.Get("a"))
+	}
+
+	o = FromMapDeep(m, func(keys []string) { sort.Sort(sort.Reverse(sort.StringSlice(keys))) })
+	if !reflect.DeepEqual(o.Keys(), []string{"c", "b", "a"}) {
+		t.Error("FromMapDeep keyOrder", o.Keys())
+	}
+	if !reflect.DeepEqual(o.ToMap(), map[string]any{
+		"c": 1,
+		"a": map[string]any{"z": 1, "y": []any{map[string]any{"q": 1, "p": 2}}},
+		"b": []any{"x"},
+	}) {
+		t.Error("FromMapDeep did not round trip with ToMap")
+	}
+}
@@ -0,0 +1,105 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ToCSVRecord returns the values of o for headers, such as the keys of the
+// first of several maps, as a CSV record for csv.Writer.  If headers is nil the
+// keys of o are used.  A missing key or null is an empty field, a string is
+// written as is, a number or bool in JSON form, and other values as compact
+// JSON.
+func (o *OrderedMap) ToCSVRecord(headers []string) ([]string, error) {
+	if headers == nil {
+		headers = o.keys
+	}
+	rec := make([]string, len(headers))
+	for i, h := range headers {
+		f, err := csvField(o.Get(h))
+		if err != nil {
+			return nil, err
+		}
+		rec[i] = f
+	}
+	return rec, nil
+}
+
+// csvField returns v as a CSV field.
+func csvField(v any) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	}
+	b, err := encodeCompact(v)
+	return string(b), err
+}
+
+// FromCSV reads CSV from r, whose first record is the header, and returns a map
+// for each following record, with its fields as string values keyed by header
+// in column order.  Repeated headers are rejected with an error matching
+// ErrJSONDuplicate, and records must have as many fields as the header.
+func FromCSV(r io.Reader) ([]*OrderedMap, error) {
+	cr := csv.NewReader(r)
+	headers, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		if seen[h] {
+			return nil, fmt.Errorf("%w %q in CSV header", ErrJSONDuplicate, h)
+		}
+		seen[h] = true
+	}
+	cr.ReuseRecord = true
+	var maps []*OrderedMap
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return maps, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		o := New()
+		o.reserveKeys(len(headers))
+		for i, h := range headers {
+			o.set(h, rec[i])
+		}
+		maps = append(maps, o)
+	}
+}
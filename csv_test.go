@@ -0,0 +1,84 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	in := "name,age,tags\nann,36,\"a,b\"\nbob,,\n"
+	maps, err := FromCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(maps) != 2 {
+		t.Fatalf("got %d maps", len(maps))
+	}
+	if b, _ := maps[0].MarshalJSON(); string(b) != `{"name":"ann","age":"36","tags":"a,b"}` {
+		t.Errorf("got %s", b)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	headers := maps[0].Keys()
+	w.Write(headers)
+	for _, m := range maps {
+		rec, err := m.ToCSVRecord(headers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(rec)
+	}
+	w.Flush()
+	if buf.String() != in {
+		t.Errorf("round trip: got %q", buf.String())
+	}
+
+	o := New()
+	o.Set("n", 1.5e9)
+	o.Set("ok", true)
+	o.Set("nil", nil)
+	o.Set("m", map[string]any{"a": []any{1}})
+	rec, err := o.ToCSVRecord(nil)
+	if err != nil || strings.Join(rec, "|") != `1500000000|true||{"a":[1]}` {
+		t.Errorf("got %q, %v", rec, err)
+	}
+	if rec, _ := o.ToCSVRecord([]string{"ok", "x"}); strings.Join(rec, "|") != "true|" {
+		t.Errorf("got %q", rec)
+	}
+
+	if _, err := FromCSV(strings.NewReader("a,b,a\n1,2,3\n")); !errors.Is(err, ErrJSONDuplicate) {
+		t.Errorf("got %v", err)
+	}
+	if _, err := FromCSV(strings.NewReader("a,b\n1\n")); err == nil {
+		t.Error("short record accepted")
+	}
+	if maps, err := FromCSV(strings.NewReader("")); maps != nil || err != nil {
+		t.Errorf("empty: %v, %v", maps, err)
+	}
+}
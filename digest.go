@@ -0,0 +1,119 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// Digest returns the digest, using alg, of the ordered content of o: keys in
+// order and values, at every depth, as compact JSON without HTML escaping.
+// The encoding ignores the options of o that change MarshalJSON, such as
+// Redact, KeepRaw, Faithful and WithEncodeFormat, so that maps that are
+// EqualOrdered always have the same digest, making Digest suitable for
+// content addressing.
+func (o *OrderedMap) Digest(alg crypto.Hash) ([]byte, error) {
+	if !alg.Available() {
+		return nil, fmt.Errorf("orderedmap: hash %v is not available", alg)
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, o); err != nil {
+		return nil, err
+	}
+	h := alg.New()
+	h.Write(buf.Bytes())
+	return h.Sum(nil), nil
+}
+
+// writeCanonical writes the compact JSON of the decoded content of o to buf.
+func writeCanonical(buf *bytes.Buffer, o *OrderedMap) error {
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, err := encodeCompact(k)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		buf.WriteByte(':')
+		if err := writeCanonicalValue(buf, o.valueAt(i)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeCanonicalValue(buf *bytes.Buffer, v any) error {
+	if m, ok := asMap(v); ok {
+		return writeCanonical(buf, m)
+	}
+	if a, ok := asSlice(v); ok {
+		buf.WriteByte('[')
+		for i, e := range a {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalValue(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+	b, err := encodeCompact(v)
+	if err != nil {
+		return err
+	}
+	var c bytes.Buffer
+	if err := json.Compact(&c, b); err != nil {
+		return err
+	}
+	buf.Write(c.Bytes())
+	return nil
+}
+
+// OrderDigest returns the digest, using h, of the key sequence only.  Values
+// are not included, so the digest changes when keys are added, removed,
+// renamed, or reordered, but not when values change.  Nested maps are not
+// included.
+//
+// Each key is written to the hash as its 8 byte big-endian length followed by
+// the UTF-8 bytes of the key, making the encoding unambiguous and simple to
+// reproduce independently.  OrderDigest panics if h is not available.
+func (o *OrderedMap) OrderDigest(h crypto.Hash) []byte {
+	d := h.New()
+	var l [8]byte
+	for _, k := range o.keys {
+		binary.BigEndian.PutUint64(l[:], uint64(len(k)))
+		d.Write(l[:])
+		d.Write([]byte(k))
+	}
+	return d.Sum(nil)
+}
@@ -0,0 +1,125 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderedMap_OrderDigest(t *testing.T) {
+	o := New()
+	o.Set("alg", "ES256")
+	o.Set("iat", 1623132000)
+
+	// Independently computed digest of the key sequence.
+	h := sha256.New()
+	h.Write([]byte{0, 0, 0, 0, 0, 0, 0, 3})
+	h.Write([]byte("alg"))
+	h.Write([]byte{0, 0, 0, 0, 0, 0, 0, 3})
+	h.Write([]byte("iat"))
+	expected := h.Sum(nil)
+
+	d := o.OrderDigest(crypto.SHA256)
+	if !bytes.Equal(d, expected) {
+		t.Errorf("OrderDigest %X != %X", d, expected)
+	}
+
+	// Value changes do not change the digest.
+	o.Set("alg", "ES384")
+	if !bytes.Equal(o.OrderDigest(crypto.SHA256), expected) {
+		t.Error("OrderDigest changed on value change")
+	}
+
+	// Order changes do.
+	o.Delete("alg")
+	o.Set("alg", "ES256")
+	if bytes.Equal(o.OrderDigest(crypto.SHA256), expected) {
+		t.Error("OrderDigest did not change on reorder")
+	}
+}
+
+func TestOrderedMap_Digest(t *testing.T) {
+	o := New()
+	err := json.Unmarshal([]byte(`{ "b": "<&>", "a": [ 1, { "y": 2.5, "x": null } ] }`), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := sha256.Sum256([]byte(`{"b":"<&>","a":[1,{"y":2.5,"x":null}]}`))
+
+	d, err := o.Digest(crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d, expected[:]) {
+		t.Errorf("Digest %X != %X", d, expected)
+	}
+
+	// Order is content.
+	o.SortKeys(func(keys []string) { keys[0], keys[1] = keys[1], keys[0] })
+	d, _ = o.Digest(crypto.SHA256)
+	if bytes.Equal(d, expected[:]) {
+		t.Error("Digest did not change on reorder")
+	}
+
+	if _, err = o.Digest(crypto.Hash(0)); err == nil {
+		t.Error("Digest did not error on unavailable hash")
+	}
+}
+
+func TestOrderedMap_Digest_Options(t *testing.T) {
+	const in = `{"a":1.0, "b":{"secret":"x","n":0.5}}`
+	digest := func(s string, opts ...Option) []byte {
+		t.Helper()
+		o := New(opts...)
+		if err := o.UnmarshalJSON([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		d, err := o.Digest(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	want := digest(in)
+	for name, opts := range map[string][]Option{
+		"KeepRaw":          {KeepRaw()},
+		"Faithful":         {Faithful()},
+		"WithEncodeFormat": {WithEncodeFormat(EncodeFormat{Decimals: 3})},
+		"Redact":           {Redact([]string{"b.secret"}, "***")},
+	} {
+		if d := digest(in, opts...); !bytes.Equal(d, want) {
+			t.Errorf("%s: digest changed", name)
+		}
+	}
+	redact := Redact([]string{"b.secret"}, "***")
+	if bytes.Equal(digest(`{"a":1,"b":{"secret":"y","n":0.5}}`, redact), digest(in, redact)) {
+		t.Error("Redact: maps with different secrets have the same digest")
+	}
+	if !bytes.Equal(digest(`{"a":1,"b":{"secret":"x","n":5e-1}}`, KeepRaw()), want) {
+		t.Error("KeepRaw: equal maps have different digests")
+	}
+}
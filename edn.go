@@ -0,0 +1,228 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// EDNEncoder is the Handler writing the maps passed to Encode as EDN, such as
+// for Clojure services.  Members are written in order, though EDN maps, like
+// JSON objects, are unordered to most readers.  Numbers are written as EDN
+// integers if integral and as floats otherwise, time.Time as #inst, and
+// strings, bools and nil as such.  Other scalars are rejected.
+type EDNEncoder struct {
+	w io.Writer
+	// Keywords writes keys that are valid EDN keywords, such as "name", as
+	// keywords, such as :name, instead of strings.
+	Keywords bool
+	// stack holds, for each object and array being written, whether it is an
+	// object and the number of keys and values written to it.
+	stack []ednFrame
+}
+
+type ednFrame struct {
+	object bool
+	n      int
+}
+
+// NewEDNEncoder returns an EDNEncoder writing to w.
+func NewEDNEncoder(w io.Writer) *EDNEncoder {
+	return &EDNEncoder{w: w}
+}
+
+// Encode writes o as EDN followed by a newline.
+func (e *EDNEncoder) Encode(o *OrderedMap) error {
+	e.stack = e.stack[:0]
+	if err := o.Emit(e); err != nil {
+		return err
+	}
+	return e.write("\n")
+}
+
+// MarshalEDN returns o as EDN.  See EDNEncoder.
+func (o OrderedMap) MarshalEDN() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := (&EDNEncoder{w: &buf}).Encode(&o); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// ednKeyword matches the keys written as keywords by an EDNEncoder with
+// Keywords set.
+var ednKeyword = regexp.MustCompile(`^(?:[A-Za-z*!_?<>=]|[+-](?:[^0-9]|$))[A-Za-z0-9*+!_?<>=.-]*$`)
+
+func (e *EDNEncoder) BeginObject() error {
+	return e.begin(true, "{")
+}
+
+func (e *EDNEncoder) BeginArray() error {
+	return e.begin(false, "[")
+}
+
+func (e *EDNEncoder) begin(object bool, s string) error {
+	if err := e.next(); err != nil {
+		return err
+	}
+	e.stack = append(e.stack, ednFrame{object: object})
+	return e.write(s)
+}
+
+func (e *EDNEncoder) EndObject() error {
+	return e.end("}")
+}
+
+func (e *EDNEncoder) EndArray() error {
+	return e.end("]")
+}
+
+func (e *EDNEncoder) end(s string) error {
+	e.stack = e.stack[:len(e.stack)-1]
+	return e.write(s)
+}
+
+func (e *EDNEncoder) Key(key string) error {
+	if err := e.next(); err != nil {
+		return err
+	}
+	if e.Keywords && ednKeyword.MatchString(key) {
+		return e.write(":" + key)
+	}
+	return e.write(ednString(key))
+}
+
+func (e *EDNEncoder) Value(v any) error {
+	s, err := ednScalar(v)
+	if err != nil {
+		return err
+	}
+	if err := e.next(); err != nil {
+		return err
+	}
+	return e.write(s)
+}
+
+// next writes the separator before the next key or value.
+func (e *EDNEncoder) next() error {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	f := &e.stack[len(e.stack)-1]
+	f.n++
+	switch {
+	case f.n == 1:
+		return nil
+	case f.object && f.n%2 == 1:
+		return e.write(", ")
+	}
+	return e.write(" ")
+}
+
+func (e *EDNEncoder) write(s string) error {
+	_, err := io.WriteString(e.w, s)
+	return err
+}
+
+// ednScalar returns scalar v as EDN.
+func ednScalar(v any) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		return ednString(t), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case float64:
+		return ednFloat(t), nil
+	case float32:
+		return ednFloat(float64(t)), nil
+	case json.Number:
+		if _, err := strconv.ParseFloat(string(t), 64); err != nil {
+			return "", fmt.Errorf("orderedmap: invalid number %q", t)
+		}
+		return string(t), nil
+	case time.Time:
+		return "#inst " + ednString(t.Format(time.RFC3339Nano)), nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("orderedmap: cannot encode %T as EDN", v)
+}
+
+// ednFloat returns f as an EDN integer if integral and as a float otherwise.
+func ednFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "##NaN"
+	case math.IsInf(f, 1):
+		return "##Inf"
+	case math.IsInf(f, -1):
+		return "##-Inf"
+	case f == math.Trunc(f) && math.Abs(f) < 1e21:
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ednString returns s as an EDN string.
+func ednString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
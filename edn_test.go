@@ -0,0 +1,74 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMarshalEDN(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"z":1,"a":[1.5,"x\"\n",null,true],"m":{"k":{}},"e":[],"big":1e300}`)); err != nil {
+		t.Fatal(err)
+	}
+	o.Set("t", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	o.Set("n", math.Inf(-1))
+	o.Set("u", uint8(3))
+	b, err := o.MarshalEDN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"z" 1, "a" [1.5 "x\"\n" nil true], "m" {"k" {}}, "e" [], "big" 1e+300, "t" #inst "2024-01-02T03:04:05Z", "n" ##-Inf, "u" 3}`
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+
+	o = New()
+	o.Set("bad", struct{}{})
+	if _, err := o.MarshalEDN(); err == nil {
+		t.Error("unsupported value encoded")
+	}
+}
+
+func TestEDNEncoder_Keywords(t *testing.T) {
+	o := New()
+	for _, k := range []string{"name", "-1", "+", "a b", "x.y", "1a", "ok?"} {
+		o.Set(k, 0)
+	}
+	var buf bytes.Buffer
+	e := NewEDNEncoder(&buf)
+	e.Keywords = true
+	if err := e.Encode(o); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(New()); err != nil {
+		t.Fatal(err)
+	}
+	want := "{:name 0, \"-1\" 0, :+ 0, \"a b\" 0, :x.y 0, \"1a\" 0, :ok? 0}\n{}\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
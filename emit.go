@@ -0,0 +1,106 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Emit reports the tree of o to h, in order: o and nested maps as objects, Go
+// maps with string keys as objects in sorted key order, slices and arrays,
+// other than []byte, as arrays, and other values as scalars.  It is the
+// extension point for encoders of other formats, such as EDNEncoder, and
+// returns the first error returned by h.
+func (o *OrderedMap) Emit(h Handler) error {
+	if err := h.BeginObject(); err != nil {
+		return err
+	}
+	for i, k := range o.keys {
+		if err := h.Key(k); err != nil {
+			return err
+		}
+		if err := emitValue(o.valueAt(i), h); err != nil {
+			return err
+		}
+	}
+	return h.EndObject()
+}
+
+func emitValue(v any, h Handler) error {
+	switch v.(type) {
+	case nil, bool, float64, string:
+		return h.Value(v)
+	}
+	if m, ok := asMap(v); ok {
+		return m.Emit(h)
+	}
+	if a, ok := asSlice(v); ok {
+		if err := h.BeginArray(); err != nil {
+			return err
+		}
+		for _, e := range a {
+			if err := emitValue(e, h); err != nil {
+				return err
+			}
+		}
+		return h.EndArray()
+	}
+	if _, ok := v.([]byte); !ok && v != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+			return emitMap(rv, h)
+		}
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			if err := h.BeginArray(); err != nil {
+				return err
+			}
+			for i := range rv.Len() {
+				if err := emitValue(rv.Index(i).Interface(), h); err != nil {
+					return err
+				}
+			}
+			return h.EndArray()
+		}
+	}
+	return h.Value(v)
+}
+
+// emitMap reports Go map rv, with string keys, in sorted key order.
+func emitMap(rv reflect.Value, h Handler) error {
+	keys := rv.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+	if err := h.BeginObject(); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := h.Key(k.String()); err != nil {
+			return err
+		}
+		if err := emitValue(rv.MapIndex(k).Interface(), h); err != nil {
+			return err
+		}
+	}
+	return h.EndObject()
+}
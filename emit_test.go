@@ -0,0 +1,85 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// recorder is a Handler recording the tokens it receives.
+type recorder struct {
+	tokens []string
+	fail   string
+}
+
+func (r *recorder) add(s string) error {
+	r.tokens = append(r.tokens, s)
+	if r.fail != "" && s == r.fail {
+		return errors.New("stop")
+	}
+	return nil
+}
+
+func (r *recorder) BeginObject() error   { return r.add("{") }
+func (r *recorder) Key(key string) error { return r.add(key + ":") }
+func (r *recorder) EndObject() error     { return r.add("}") }
+func (r *recorder) BeginArray() error    { return r.add("[") }
+func (r *recorder) EndArray() error      { return r.add("]") }
+func (r *recorder) Value(v any) error {
+	s, err := csvField(v)
+	if err != nil {
+		return err
+	}
+	return r.add(s)
+}
+
+func TestEmit(t *testing.T) {
+	o := New()
+	o.Set("b", 1)
+	o.Set("a", []any{"x", OrderedArray{true}})
+	n := New()
+	n.Set("z", nil)
+	o.Set("m", n)
+	o.Set("i", []int{7, 8})
+	o.Set("raw", []byte("hi"))
+
+	var r recorder
+	if err := o.Emit(&r); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"{", "b:", "1", "a:", "[", "x", "[", "true", "]", "]", "m:", "{", "z:", "", "}", "i:", "[", "7", "8", "]", "raw:", `"aGk="`, "}"}
+	if !slices.Equal(r.tokens, want) {
+		t.Errorf("got %q, want %q", r.tokens, want)
+	}
+
+	r = recorder{fail: "x"}
+	if err := o.Emit(&r); err == nil || !strings.Contains(err.Error(), "stop") {
+		t.Errorf("got %v, want handler error", err)
+	}
+	if r.tokens[len(r.tokens)-1] != "x" {
+		t.Errorf("emitting continued after error: %q", r.tokens)
+	}
+}
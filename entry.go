@@ -0,0 +1,148 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// Entry is a handle to a key of an OrderedMap, returned by Entry, that looks
+// the key up once for a sequence of operations.  Go maps cannot reference a
+// key's storage, so for large maps an Entry saves the lookups but not the
+// single map assignment of each Set.
+//
+// An Entry is invalid after the map is modified other than through the Entry.
+type Entry struct {
+	o      *OrderedMap
+	key    string
+	mapKey string
+	// index is the position of key in a small map.
+	index  int
+	exists bool
+	value  any
+}
+
+// Entry looks up key and returns a handle for reading and modifying its value.
+func (o *OrderedMap) Entry(key string) *Entry {
+	o.expire(key)
+	e := &Entry{o: o, key: o.storedKey(key), index: -1}
+	if o.values != nil {
+		e.mapKey = o.mapKey(key)
+		e.value, e.exists = o.get(key)
+	} else if e.index = o.index(key); e.index >= 0 {
+		e.value, e.exists = o.valueAt(e.index), true
+	}
+	return e
+}
+
+// Key returns the entry's key.
+func (e *Entry) Key() string {
+	return e.key
+}
+
+// Exists reports whether the key exists in the map.
+func (e *Entry) Exists() bool {
+	return e.exists
+}
+
+// Get returns the value, or nil if the key does not exist.
+func (e *Entry) Get() any {
+	return e.value
+}
+
+// Set sets the value, appending the key to the map if it does not exist.  Like
+// OrderedMap.Set, it returns the validator's error, if any.
+func (e *Entry) Set(value any) error {
+	o := e.o
+	if err := o.validate(e.key, value); err != nil {
+		return err
+	}
+	o.own()
+	o.forget(e.key)
+	if o.observed() {
+		defer o.notifySet(e.key, e.value, e.exists, value)
+	}
+	switch {
+	case e.exists && o.values != nil:
+		o.values[e.mapKey] = value
+	case e.exists:
+		o.vals[e.index] = value
+	default:
+		o.keys = append(o.keys, e.key)
+		if o.values != nil {
+			o.values[e.mapKey] = value
+			break
+		}
+		o.vals = append(o.vals, value)
+		e.index = len(o.vals) - 1
+		if len(o.keys) > smallSize {
+			o.grow()
+			e.mapKey = o.mapKey(e.key)
+		}
+	}
+	e.exists, e.value = true, value
+	return nil
+}
+
+// Delete deletes the key from the map, if it exists.  Like OrderedMap.Delete,
+// it panics with ErrFrozen if the map is frozen.
+func (e *Entry) Delete() {
+	if e.o.frozen {
+		panic(ErrFrozen)
+	}
+	if !e.exists {
+		return
+	}
+	if e.o.values == nil && e.o.expires == nil && !e.o.observed() {
+		e.o.deleteSmall(e.index)
+	} else {
+		e.o.Delete(e.key)
+	}
+	e.exists, e.value, e.index = false, nil, -1
+}
+
+// OrInsert returns the value if the key exists, and otherwise sets and returns
+// the value returned by f.  The error is the validator's, if any.
+func (e *Entry) OrInsert(f func() any) (any, error) {
+	if e.exists {
+		return e.value, nil
+	}
+	if err := e.Set(f()); err != nil {
+		return nil, err
+	}
+	return e.value, nil
+}
+
+// Update sets the value for key to the value returned by f, which is given the
+// current value and whether key exists.  If f returns keep false, key is
+// deleted instead.  Key is looked up once.  The error is the validator's, if
+// any, in which case the map is not modified, or ErrFrozen, without calling
+// f, if o is frozen.
+func (o *OrderedMap) Update(key string, f func(old any, exists bool) (new any, keep bool)) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	e := o.Entry(key)
+	v, keep := f(e.value, e.exists)
+	if !keep {
+		e.Delete()
+		return nil
+	}
+	return e.Set(v)
+}
@@ -0,0 +1,123 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_Entry(t *testing.T) {
+	o := New()
+	words := []string{"b", "a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "b", "a"}
+	for _, w := range words { // count, growing past smallSize
+		e := o.Entry(w)
+		n, err := e.OrInsert(func() any { return 0 })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = e.Set(n.(int) + 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if o.Get("b") != 3 || o.Get("a") != 2 || o.Get("j") != 1 {
+		t.Error("Entry counts", o.Keys(), o.Values())
+	}
+	if !reflect.DeepEqual(o.Keys(), []string{"b", "a", "c", "d", "e", "f", "g", "h", "i", "j"}) {
+		t.Error("Entry key order", o.Keys())
+	}
+
+	for _, m := range []*OrderedMap{o, New()} {
+		m.Set("x", 1)
+		m.Set("y", 2)
+		e := m.Entry("x")
+		if !e.Exists() || e.Get() != 1 || e.Key() != "x" {
+			t.Error("Entry existing", e.Exists(), e.Get())
+		}
+		e.Delete()
+		if e.Exists() || m.Get("x") != nil || m.GetKeyAt(m.Len()-1) != "y" {
+			t.Error("Entry Delete", m.Keys())
+		}
+		e.Set(3) // reinsert at end
+		if m.GetKeyAt(m.Len()-1) != "x" || m.Get("x") != 3 {
+			t.Error("Entry Set after Delete", m.Keys())
+		}
+		if e = m.Entry("missing"); e.Exists() || e.Get() != nil {
+			t.Error("Entry missing")
+		}
+	}
+}
+
+func TestEntry_DeleteFrozen(t *testing.T) {
+	for _, key := range []string{"a", "missing"} {
+		o := New()
+		o.Set("a", 1)
+		o.Freeze()
+		e := o.Entry(key)
+		func() {
+			defer func() {
+				if r := recover(); r != ErrFrozen {
+					t.Errorf("Delete of %q panicked with %v", key, r)
+				}
+			}()
+			e.Delete()
+		}()
+		if o.Len() != 1 {
+			t.Error("frozen map modified")
+		}
+	}
+}
+
+func TestOrderedMap_Update(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	incr := func(old any, exists bool) (any, bool) {
+		if !exists {
+			return 1, true
+		}
+		return old.(int) + 1, true
+	}
+	o.Update("a", incr)
+	o.Update("b", incr)
+	if o.Get("a") != 2 || o.Get("b") != 1 {
+		t.Error("Update", o.Keys(), o.Values())
+	}
+
+	o.Update("a", func(old any, exists bool) (any, bool) { return nil, false })
+	o.Update("missing", func(old any, exists bool) (any, bool) { return nil, false })
+	if !reflect.DeepEqual(o.Keys(), []string{"b"}) {
+		t.Error("Update delete", o.Keys())
+	}
+
+	o.Freeze()
+	called := false
+	for _, keep := range []bool{true, false} {
+		err := o.Update("b", func(old any, exists bool) (any, bool) {
+			called = true
+			return 2, keep
+		})
+		if err != ErrFrozen || called {
+			t.Errorf("frozen Update keep %v: %v, called %v", keep, err, called)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToEnv returns the values of o and its nested maps, in depth-first order, as
+// environment variables "NAME=value" for os/exec.Cmd.Env, such as
+// "PREFIX_A_B=value" for key b of nested map a.  Names are the keys, as
+// flattened by Flatten with "_", in upper case with characters other than
+// letters, digits and '_' replaced by '_', after prefix and '_' unless prefix
+// is empty.  Values are formatted like ToCSVRecord.  Keys giving the same
+// name are rejected with an error wrapping ErrKeyCollision.
+func (o *OrderedMap) ToEnv(prefix string) ([]string, error) {
+	e := &envWriter{names: make(map[string]string)}
+	if prefix != "" {
+		prefix += "_"
+	}
+	if err := e.write(o, prefix, ""); err != nil {
+		return nil, err
+	}
+	return e.env, nil
+}
+
+type envWriter struct {
+	env []string
+	// names maps names to the flattened keys they are of.
+	names map[string]string
+}
+
+func (e *envWriter) write(o *OrderedMap, prefix, keyPrefix string) error {
+	for i, k := range o.keys {
+		v := o.valueAt(i)
+		name, key := prefix+envName(k), keyPrefix+k
+		if m, ok := asMap(v); ok && m.Len() > 0 {
+			if err := e.write(m, name+"_", key+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+		if first, ok := e.names[name]; ok {
+			return fmt.Errorf("%w: %q and %q are both %s", ErrKeyCollision, first, key, name)
+		}
+		e.names[name] = key
+		s, err := csvField(v)
+		if err != nil {
+			return err
+		}
+		e.env = append(e.env, name+"="+s)
+	}
+	return nil
+}
+
+// envName returns key in upper case with characters other than letters,
+// digits and '_' replaced by '_'.
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		case 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// FromEnv returns the variables of environ, such as from os.Environ, whose
+// names begin with prefix and '_', or all if prefix is empty, as a map in
+// order, reversing ToEnv: the rest of each name, in lower case, is split at
+// '_' into nested maps, so that "PREFIX_A_B=value" is key b of nested map a.
+// Values are strings.  A name that is both a value and a prefix of another
+// name is rejected with an error wrapping ErrKeyCollision.  Later variables
+// of the same name replace earlier ones.
+func FromEnv(prefix string, environ []string) (*OrderedMap, error) {
+	flat := New()
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		if prefix != "" {
+			if name, ok = strings.CutPrefix(name, prefix+"_"); !ok {
+				continue
+			}
+		}
+		if name == "" {
+			continue
+		}
+		flat.set(strings.ToLower(name), value)
+	}
+	return flat.Unflatten("_")
+}
@@ -0,0 +1,85 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestToEnv(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"db":{"host":"h","port":5432},"log-level":"debug","tags":["a","b"],"on":true,"none":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	env, err := o.ToEnv("APP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"APP_DB_HOST=h", "APP_DB_PORT=5432", "APP_LOG_LEVEL=debug", `APP_TAGS=["a","b"]`, "APP_ON=true", "APP_NONE="}
+	if !slices.Equal(env, want) {
+		t.Errorf("got %q, want %q", env, want)
+	}
+	if env, _ := o.ToEnv(""); env[0] != "DB_HOST=h" {
+		t.Error("no prefix", env)
+	}
+
+	o.Set("db_host", "x")
+	if _, err := o.ToEnv("APP"); !errors.Is(err, ErrKeyCollision) {
+		t.Error("collision:", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	environ := []string{"HOME=/root", "APP_DB_HOST=h", "APP_LOG=debug", "APP_DB_PORT=5432", "APPX=1", "APP_=2", "APP_LOG=info", "bad"}
+	o, err := FromEnv("APP", environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := o.String(), `{"db":{"host":"h","port":"5432"},"log":"info"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	all, err := FromEnv("", []string{"HOME=/root", "A_B=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := all.String(); got != `{"home":"/root","a":{"b":"1"}}` {
+		t.Error(got)
+	}
+
+	if _, err := FromEnv("APP", []string{"APP_DB=1", "APP_DB_HOST=h"}); !errors.Is(err, ErrKeyCollision) {
+		t.Error("collision:", err)
+	}
+
+	// Round trip.
+	env, err := o.ToEnv("APP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := FromEnv("APP", env)
+	if err != nil || back.String() != o.String() {
+		t.Error("round trip", back, err)
+	}
+}
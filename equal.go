@@ -0,0 +1,99 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "reflect"
+
+// Equal reports whether o and other have the same keys and values, regardless
+// of order.  Nested OrderedMaps are compared with Equal and slices element by
+// element; other values are compared with reflect.DeepEqual.
+func (o *OrderedMap) Equal(other *OrderedMap) bool {
+	return equal(o, other, false)
+}
+
+// EqualOrdered is like Equal, but also requires the same key order, including
+// in nested OrderedMaps.
+func (o *OrderedMap) EqualOrdered(other *OrderedMap) bool {
+	return equal(o, other, true)
+}
+
+func equal(a, b *OrderedMap, ordered bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Len() != b.Len() {
+		return false
+	}
+	for i, k := range a.keys {
+		var bv any
+		if ordered {
+			if b.keys[i] != k {
+				return false
+			}
+			bv = b.valueAt(i)
+		} else {
+			var ok bool
+			if bv, ok = b.get(k); !ok {
+				return false
+			}
+		}
+		if !valuesEqual(a.valueAt(i), bv, ordered) {
+			return false
+		}
+	}
+	return true
+}
+
+func valuesEqual(a, b any, ordered bool) bool {
+	am, aok := asMap(a)
+	bm, bok := asMap(b)
+	if aok || bok {
+		return aok && bok && equal(am, bm, ordered)
+	}
+	as, aok := asSlice(a)
+	bs, bok := asSlice(b)
+	if aok && bok {
+		if len(as) != len(bs) {
+			return false
+		}
+		for i := range as {
+			if !valuesEqual(as[i], bs[i], ordered) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// asMap returns v as an *OrderedMap if v is an OrderedMap, as decoded by
+// UnmarshalJSON, or a non-nil *OrderedMap.
+func asMap(v any) (*OrderedMap, bool) {
+	switch m := v.(type) {
+	case OrderedMap:
+		return &m, true
+	case *OrderedMap:
+		return m, m != nil
+	}
+	return nil, false
+}
@@ -0,0 +1,76 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderedMap_Equal(t *testing.T) {
+	a := New()
+	err := json.Unmarshal([]byte(`{"a":1,"b":{"x":[1,{"y":2,"z":3}]},"c":"c"}`), a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same content built by hand, nested map by pointer, different order.
+	inner := New()
+	inner.Set("z", float64(3))
+	inner.Set("y", float64(2))
+	nested := New()
+	nested.Set("x", []any{float64(1), inner})
+	b := New()
+	b.Set("c", "c")
+	b.Set("a", float64(1))
+	b.Set("b", nested)
+
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Error("Equal maps with different order are not Equal")
+	}
+	if a.EqualOrdered(b) {
+		t.Error("EqualOrdered for maps with different order")
+	}
+	if !a.EqualOrdered(a) {
+		t.Error("map is not EqualOrdered to itself")
+	}
+
+	// Only nested order differs.
+	b = New()
+	json.Unmarshal([]byte(`{"a":1,"b":{"x":[1,{"z":3,"y":2}]},"c":"c"}`), b)
+	if !a.Equal(b) || a.EqualOrdered(b) {
+		t.Error("nested order", a.Equal(b), a.EqualOrdered(b))
+	}
+
+	// Value differs.
+	b = New()
+	json.Unmarshal([]byte(`{"a":1,"b":{"x":[1,{"y":2,"z":4}]},"c":"c"}`), b)
+	if a.Equal(b) {
+		t.Error("maps with different nested values are Equal")
+	}
+
+	var n *OrderedMap
+	if a.Equal(nil) || !n.Equal(nil) {
+		t.Error("nil maps")
+	}
+}
@@ -0,0 +1,77 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// String returns the JSON of o in order, or "null" if it cannot be encoded, so
+// that *OrderedMap implements expvar.Var for publishing with expvar.Publish.
+func (o OrderedMap) String() string {
+	b, err := o.MarshalJSON()
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// Metrics counts the operations of the maps configured with WithMetrics.  It
+// is safe for concurrent use and implements expvar.Var.
+type Metrics struct {
+	// Gets, Sets and Deletes count calls of Get, Set and Delete.
+	Gets, Sets, Deletes atomic.Int64
+	// Unmarshals counts calls of UnmarshalJSON, and Duplicates those that
+	// returned an error wrapping ErrJSONDuplicate.
+	Unmarshals, Duplicates atomic.Int64
+}
+
+// WithMetrics counts the operations of the map, and the maps it decodes, in
+// m.  Many maps may share m.
+func WithMetrics(m *Metrics) Option {
+	return func(opts *options) { opts.metrics = m }
+}
+
+// String returns the counts of m as a JSON object.
+func (m *Metrics) String() string {
+	return fmt.Sprintf(`{"gets":%d,"sets":%d,"deletes":%d,"unmarshals":%d,"duplicates":%d}`,
+		m.Gets.Load(), m.Sets.Load(), m.Deletes.Load(), m.Unmarshals.Load(), m.Duplicates.Load())
+}
+
+// metrics returns the Metrics of o, or nil.
+func (o *OrderedMap) metrics() *Metrics {
+	if o.opts == nil {
+		return nil
+	}
+	return o.opts.metrics
+}
+
+// countUnmarshal counts an UnmarshalJSON returning err.
+func (m *Metrics) countUnmarshal(err error) {
+	m.Unmarshals.Add(1)
+	if errors.Is(err, ErrJSONDuplicate) {
+		m.Duplicates.Add(1)
+	}
+}
@@ -0,0 +1,74 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+var (
+	_ expvar.Var = (*OrderedMap)(nil)
+	_ expvar.Var = (*Metrics)(nil)
+)
+
+func TestString(t *testing.T) {
+	o := New()
+	o.Set("z", 1)
+	o.Set("a", map[string]any{"b": true})
+	if got, want := o.String(), `{"z":1,"a":{"b":true}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	o.Set("c", make(chan int))
+	if got := o.String(); got != "null" {
+		t.Error("unencodable map", got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	var m Metrics
+	o := New(WithMetrics(&m))
+	if err := o.UnmarshalJSON([]byte(`{"a":{"b":1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.UnmarshalJSON([]byte(`{"a":1,"a":2}`)); err == nil {
+		t.Fatal("no duplicate error")
+	}
+	if err := o.UnmarshalJSON([]byte(`{`)); err == nil {
+		t.Fatal("no syntax error")
+	}
+	o.Set("c", 3)
+	o.Get("c")
+	o.Get("d")
+	o.Delete("c")
+	if n, ok := o.Get("a").(OrderedMap); ok {
+		n.Get("b")
+	}
+	if got, want := m.String(), `{"gets":4,"sets":1,"deletes":1,"unmarshals":3,"duplicates":1}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if !json.Valid([]byte(m.String())) {
+		t.Error("invalid JSON")
+	}
+}
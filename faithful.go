@@ -0,0 +1,177 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "bytes"
+
+// Faithful makes UnmarshalJSON keep the formatting of its input, at any depth,
+// for MarshalJSON: whitespace between tokens, the quoting and escaping of keys,
+// and, like KeepRaw, which it implies, the input of values.  MarshalJSON of an
+// unmodified map then returns the input byte for byte.  Modified members and
+// their neighbours keep their formatting, and new keys are written compactly.
+//
+// Call MarshalJSON directly to keep the formatting, as encoding/json compacts
+// its output.  With Lenient, comments are replaced by spaces and keys quoted.
+func Faithful() Option {
+	return func(opts *options) { opts.raw, opts.faithful = true, true }
+}
+
+// layout is the formatting of a decoded object.
+type layout struct {
+	// members is keyed like values.
+	members map[string]member
+	// inner is the whitespace within an empty object.
+	inner []byte
+	// lead and trail are the whitespace around a top-level object.
+	lead, trail []byte
+	// close is the offset of the closing '}' during decoding.
+	close int64
+}
+
+// member is the formatting of an object member.
+type member struct {
+	key string
+	// prefix is the input from the preceding ',' or '{' to the value, and after
+	// the whitespace following the value.
+	prefix, after []byte
+	// prev, start and end are the offsets of recordRaw during decoding.
+	prev, start, end int64
+}
+
+// recordMember records the input offsets of the member key, if o keeps its
+// formatting.
+func (o *OrderedMap) recordMember(key string, prev, start, end int64) {
+	if !o.opts.faithful {
+		return
+	}
+	if o.layout == nil {
+		o.layout = &layout{members: make(map[string]member)}
+	}
+	o.layout.members[o.mapKey(key)] = member{key: key, prev: prev, start: start, end: end}
+}
+
+// recordClose records the offset close of the closing '}', if o keeps its
+// formatting.
+func (o *OrderedMap) recordClose(close int64) {
+	if o.opts == nil || !o.opts.faithful {
+		return
+	}
+	if o.layout == nil {
+		o.layout = &layout{members: make(map[string]member)}
+	}
+	o.layout.close = close
+}
+
+// resolveLayout sets the formatting of o from src.  Nested maps are resolved
+// by resolveRaws.
+func (o *OrderedMap) resolveLayout(src []byte) {
+	if o.layout == nil {
+		return
+	}
+	for k, m := range o.layout.members {
+		// The prefix begins after the ',' or '{' preceding the key.
+		p := keyOffset(m.prev, src, nil)
+		for isSpace(src[p-1]) {
+			p--
+		}
+		s := m.start
+		for isSpace(src[s]) || src[s] == ':' {
+			s++
+		}
+		e := m.end
+		for e < int64(len(src)) && isSpace(src[e]) {
+			e++
+		}
+		m.prefix, m.after = src[p:s:s], src[m.end:e:e]
+		o.layout.members[k] = m
+	}
+	p := o.layout.close
+	for isSpace(src[p-1]) {
+		p--
+	}
+	o.layout.inner = src[p:o.layout.close:o.layout.close]
+}
+
+// resolveEnds sets the whitespace around the top-level object src of o.
+func (o *OrderedMap) resolveEnds(src []byte) {
+	if o.layout == nil {
+		return
+	}
+	n := len(src) - len(bytes.TrimLeft(src, " \t\r\n"))
+	o.layout.lead = src[:n:n]
+	o.layout.trail = src[len(bytes.TrimRight(src, " \t\r\n")):]
+}
+
+// marshalFaithful is MarshalJSON of a map decoded with Faithful.
+func (o *OrderedMap) marshalFaithful() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(o.layout.lead)
+	if err := o.writeFaithful(&buf); err != nil {
+		return nil, err
+	}
+	buf.Write(o.layout.trail)
+	return buf.Bytes(), nil
+}
+
+func (o *OrderedMap) writeFaithful(buf *bytes.Buffer) error {
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		m, ok := o.layout.members[o.mapKey(k)]
+		ok = ok && m.key == k
+		if ok {
+			buf.Write(m.prefix)
+		} else {
+			b, err := encodeCompact(k)
+			if err != nil {
+				return err
+			}
+			buf.Write(b)
+			buf.WriteByte(':')
+		}
+		v := o.rawAt(i)
+		if n, isMap := asMap(v); isMap && n.layout != nil {
+			if err := n.writeFaithful(buf); err != nil {
+				return err
+			}
+		} else if raw, isRaw := o.rawValue(k, v); isRaw {
+			buf.Write(raw)
+		} else {
+			b, err := encodeCompact(o.formatValue(v))
+			if err != nil {
+				return err
+			}
+			buf.Write(b)
+		}
+		if ok {
+			buf.Write(m.after)
+		}
+	}
+	if len(o.keys) == 0 {
+		buf.Write(o.layout.inner)
+	}
+	buf.WriteByte('}')
+	return nil
+}
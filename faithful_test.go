@@ -0,0 +1,59 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "testing"
+
+func TestFaithful(t *testing.T) {
+	for _, in := range []string{
+		"{}",
+		" { } \n",
+		"{\n  \"a\" : 1.0 ,\n  \"\\u0062\":\t[ 1, {\"x\" :2} ],\n  \"c\": {\n    \"d\": \"\\/\"\n  },\n  \"e\": { }\n}\n",
+	} {
+		o := New(Faithful())
+		if err := o.UnmarshalJSON([]byte(in)); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := o.MarshalJSON(); err != nil || string(b) != in {
+			t.Errorf("got %q, %v, want %q", b, err, in)
+		}
+	}
+
+	in := "{\n  \"a\": 1,\n  \"b\": {\n    \"c\": 2\n  }\n}"
+	o := New(Faithful())
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	o.Set("a", "x")
+	o.SetPath("b.d", true)
+	o.Set("z", nil)
+	want := "{\n  \"a\": \"x\",\n  \"b\": {\n    \"c\": 2\n  ,\"d\":true}\n,\"z\":null}"
+	if b, _ := o.MarshalJSON(); string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+	o.Delete("b")
+	o.Delete("z")
+	if b, _ := o.MarshalJSON(); string(b) != "{\n  \"a\": \"x\"}" {
+		t.Errorf("after Delete got %q", b)
+	}
+}
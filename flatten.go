@@ -0,0 +1,112 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Flatten returns a new map of the values of o and its nested maps, in
+// depth-first order, with the keys of nested values joined by sep, e.g.
+// "a.b.c" for sep ".".  Nested maps are OrderedMap and *OrderedMap values.
+// Empty nested maps and other values, including slices, are kept as values.
+// Keys containing sep make the result ambiguous for Unflatten.
+func (o *OrderedMap) Flatten(sep string) *OrderedMap {
+	f := o.empty()
+	flatten(f, o, "", sep)
+	return f
+}
+
+func flatten(f, o *OrderedMap, prefix, sep string) {
+	for i, k := range o.keys {
+		v := o.valueAt(i)
+		if m, ok := asMap(v); ok && m.Len() > 0 {
+			flatten(f, m, prefix+k+sep, sep)
+			continue
+		}
+		f.set(prefix+k, v)
+	}
+}
+
+// Unflatten returns a new map of o with keys split by sep into nested
+// OrderedMaps, reversing Flatten.  Nested maps are in order of the first key
+// that creates them.  If a key is both a value and a prefix of another key,
+// such as "a" and "a.b", Unflatten returns an error wrapping ErrKeyCollision.
+// Values are set as by TrySet, so a validator of o may reject them.
+// An empty sep returns a copy of o.
+func (o *OrderedMap) Unflatten(sep string) (*OrderedMap, error) {
+	u := o.empty()
+	created := make(map[*OrderedMap]bool)
+	for i, k := range o.keys {
+		parts := []string{k}
+		if sep != "" {
+			parts = strings.Split(k, sep)
+		}
+		m := u
+		for j, p := range parts[:len(parts)-1] {
+			v, ok := m.get(p)
+			if !ok {
+				n := o.empty()
+				created[n] = true
+				if err := m.store(p, n); err != nil {
+					return nil, err
+				}
+				m = n
+				continue
+			}
+			n, ok := v.(*OrderedMap)
+			if !ok || !created[n] {
+				return nil, fmt.Errorf("%w: %q is a value and a prefix of %q", ErrKeyCollision, strings.Join(parts[:j+1], sep), k)
+			}
+			m = n
+		}
+		last := parts[len(parts)-1]
+		if _, ok := m.get(last); ok {
+			return nil, fmt.Errorf("%w: %q", ErrKeyCollision, k)
+		}
+		if err := m.store(last, o.valueAt(i)); err != nil {
+			return nil, err
+		}
+	}
+	if err := derefCreated(u, created); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// derefCreated replaces the created *OrderedMap values in o, recursively, with
+// OrderedMap values, as decoded by UnmarshalJSON.
+func derefCreated(o *OrderedMap, created map[*OrderedMap]bool) error {
+	for i, k := range o.keys {
+		if n, ok := o.valueAt(i).(*OrderedMap); ok && created[n] {
+			if err := derefCreated(n, created); err != nil {
+				return err
+			}
+			if err := o.store(k, *n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_Flatten(t *testing.T) {
+	in := `{"a":1,"b":{"c":{"d":2},"e":[{"f":3}],"g":{}},"h":4,"b2":{"i":5}}`
+	o := New()
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	f := o.Flatten(".")
+	if want := []string{"a", "b.c.d", "b.e", "b.g", "h", "b2.i"}; !reflect.DeepEqual(f.Keys(), want) {
+		t.Errorf("Flatten keys %v, want %v", f.Keys(), want)
+	}
+
+	u, err := f.Unflatten(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := u.MarshalJSON()
+	if err != nil || string(b) != in {
+		t.Error("Unflatten", string(b), err)
+	}
+	if _, ok := u.Get("b").(OrderedMap); !ok {
+		t.Errorf("Unflatten nested type %T", u.Get("b"))
+	}
+
+	c := New()
+	c.Set("a", 1)
+	c.Set("a.b", 2)
+	if _, err = c.Unflatten("."); !errors.Is(err, ErrKeyCollision) {
+		t.Error("collision", err)
+	}
+	c = New()
+	c.Set("a.b", 1)
+	c.Set("a", 2)
+	if _, err = c.Unflatten("."); !errors.Is(err, ErrKeyCollision) {
+		t.Error("collision", err)
+	}
+
+	errReject := errors.New("reject")
+	v := New()
+	v.Set("a.b", 1)
+	v.SetValidator(func(key string, value any) error {
+		if _, ok := value.(OrderedMap); ok {
+			return errReject
+		}
+		return nil
+	})
+	if _, err = v.Unflatten("."); err != errReject {
+		t.Error("validator", err)
+	}
+}
@@ -0,0 +1,138 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+)
+
+// EncodeFormat configures how MarshalJSON writes numbers and times.  See
+// WithEncodeFormat.
+type EncodeFormat struct {
+	// Integers writes float64 values that are integers, such as 1e21, in full
+	// without an exponent or decimals.
+	Integers bool
+	// Decimals, if positive, writes other float64 values with exactly that
+	// many decimals, rounding them.
+	Decimals int
+	// NonFiniteNull writes NaN and infinities as null.  By default they are
+	// rejected, as by encoding/json and I-JSON (RFC 7493).
+	NonFiniteNull bool
+	// Time is the format of time.Time values.
+	Time TimeFormat
+}
+
+// TimeFormat is the format of time.Time values written by MarshalJSON.
+type TimeFormat int
+
+const (
+	// TimeRFC3339Nano is RFC 3339 with fractional seconds, as written by
+	// encoding/json.
+	TimeRFC3339Nano TimeFormat = iota
+	// TimeRFC3339 is RFC 3339 truncated to seconds.
+	TimeRFC3339
+	// TimeUnix is a number of seconds since the Unix epoch.
+	TimeUnix
+	// TimeUnixMilli is a number of milliseconds since the Unix epoch.
+	TimeUnixMilli
+)
+
+// WithEncodeFormat makes MarshalJSON write float64 and time.Time values, of
+// the map and of its []any and OrderedArray values at any depth, in format f.
+// Nested maps are written with their own options, which decoded maps share.
+func WithEncodeFormat(f EncodeFormat) Option {
+	return func(opts *options) { opts.format = &f }
+}
+
+// formatValue returns v with the float64 and time.Time values within it
+// replaced by their encoding in the format of o's options.
+func (o *OrderedMap) formatValue(v any) any {
+	if o.opts == nil || o.opts.format == nil {
+		return v
+	}
+	v, _ = o.opts.format.value(v)
+	return v
+}
+
+// value returns v formatted, and whether it differs from v.
+func (f *EncodeFormat) value(v any) (any, bool) {
+	switch t := v.(type) {
+	case float64:
+		return f.float(t)
+	case time.Time:
+		return f.time(t)
+	case []any:
+		return f.slice(t)
+	case OrderedArray:
+		s, changed := f.slice(t)
+		return OrderedArray(s), changed
+	}
+	return v, false
+}
+
+// slice returns s formatted, copying it only if an element changes.
+func (f *EncodeFormat) slice(s []any) ([]any, bool) {
+	var out []any
+	for i, e := range s {
+		fe, changed := f.value(e)
+		if changed && out == nil {
+			out = append(make([]any, 0, len(s)), s[:i]...)
+		}
+		if out != nil {
+			out = append(out, fe)
+		}
+	}
+	if out == nil {
+		return s, false
+	}
+	return out, true
+}
+
+func (f *EncodeFormat) float(n float64) (any, bool) {
+	switch {
+	case math.IsNaN(n) || math.IsInf(n, 0):
+		if f.NonFiniteNull {
+			return nil, true
+		}
+	case f.Integers && n == math.Trunc(n):
+		return json.Number(strconv.FormatFloat(n, 'f', -1, 64)), true
+	case f.Decimals > 0:
+		return json.Number(strconv.FormatFloat(n, 'f', f.Decimals, 64)), true
+	}
+	return n, false
+}
+
+func (f *EncodeFormat) time(t time.Time) (any, bool) {
+	switch f.Time {
+	case TimeRFC3339:
+		return t.Format(time.RFC3339), true
+	case TimeUnix:
+		return json.Number(strconv.FormatInt(t.Unix(), 10)), true
+	case TimeUnixMilli:
+		return json.Number(strconv.FormatInt(t.UnixMilli(), 10)), true
+	}
+	return t, false
+}
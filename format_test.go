@@ -0,0 +1,72 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWithEncodeFormat(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 600e6, time.UTC)
+	set := func(o *OrderedMap) *OrderedMap {
+		o.Set("i", 1e21)
+		o.Set("f", 2.0/3)
+		o.Set("nan", math.NaN())
+		o.Set("t", tm)
+		o.Set("a", []any{1.5, tm, []any{math.Inf(1)}, "s"})
+		o.Set("oa", OrderedArray{3.0})
+		return o
+	}
+	for _, c := range []struct {
+		f    EncodeFormat
+		want string
+	}{
+		{EncodeFormat{Integers: true, Decimals: 2, NonFiniteNull: true},
+			`{"i":1000000000000000000000,"f":0.67,"nan":null,"t":"2024-01-02T03:04:05.6Z","a":[1.50,"2024-01-02T03:04:05.6Z",[null],"s"],"oa":[3]}`},
+		{EncodeFormat{NonFiniteNull: true, Time: TimeRFC3339},
+			`{"i":1e+21,"f":0.6666666666666666,"nan":null,"t":"2024-01-02T03:04:05Z","a":[1.5,"2024-01-02T03:04:05Z",[null],"s"],"oa":[3]}`},
+		{EncodeFormat{NonFiniteNull: true, Time: TimeUnix},
+			`{"i":1e+21,"f":0.6666666666666666,"nan":null,"t":1704164645,"a":[1.5,1704164645,[null],"s"],"oa":[3]}`},
+		{EncodeFormat{NonFiniteNull: true, Time: TimeUnixMilli},
+			`{"i":1e+21,"f":0.6666666666666666,"nan":null,"t":1704164645600,"a":[1.5,1704164645600,[null],"s"],"oa":[3]}`},
+	} {
+		o := set(New(WithEncodeFormat(c.f)))
+		b, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != c.want {
+			t.Errorf("%+v:\ngot  %s\nwant %s", c.f, b, c.want)
+		}
+	}
+
+	o := set(New(WithEncodeFormat(EncodeFormat{Integers: true})))
+	if _, err := o.MarshalJSON(); err == nil {
+		t.Error("NaN was not rejected")
+	}
+	if a := o.Get("a").([]any); a[0] != 1.5 {
+		t.Errorf("value was modified: %v", a)
+	}
+}
@@ -0,0 +1,81 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "errors"
+
+// ErrFrozen is returned, or for methods without an error result panicked with,
+// by modifications of a frozen OrderedMap.
+var ErrFrozen = errors.New("orderedmap: modification of frozen map")
+
+// Freeze makes o and its nested maps immutable.  Subsequent modifications
+// return ErrFrozen, or panic with it if they have no error result, such as
+// Delete, Entry.Delete and Sort.  Slices within o are not frozen.  Expired
+// SetWithTTL keys remain.  A Snapshot of a frozen map is not frozen.  Freeze
+// cannot be undone.
+func (o *OrderedMap) Freeze() {
+	if o.frozen {
+		return
+	}
+	o.own()
+	for i := range o.keys {
+		var v any
+		if o.values != nil {
+			v = o.values[o.mapKey(o.keys[i])]
+		} else {
+			v = o.vals[i]
+		}
+		v = freezeValue(v)
+		if o.values != nil {
+			o.values[o.mapKey(o.keys[i])] = v
+		} else {
+			o.vals[i] = v
+		}
+	}
+	o.frozen = true
+}
+
+// freezeValue freezes the maps within v, returning v with any OrderedMap
+// value replaced by its frozen copy.
+func freezeValue(v any) any {
+	switch t := v.(type) {
+	case OrderedMap:
+		t.Freeze()
+		return t
+	case *OrderedMap:
+		if t != nil {
+			t.Freeze()
+		}
+	case []any, OrderedArray:
+		s, _ := asSlice(t)
+		for i := range s {
+			s[i] = freezeValue(s[i])
+		}
+	}
+	return v
+}
+
+// Frozen reports whether o is frozen.
+func (o *OrderedMap) Frozen() bool {
+	return o.frozen
+}
@@ -0,0 +1,94 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestOrderedMap_Freeze(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"b":1,"a":{"x":1},"s":[{"y":2}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	o.Freeze()
+	if !o.Frozen() {
+		t.Fatal("not Frozen")
+	}
+
+	for name, err := range map[string]error{
+		"Set":           o.TrySet("c", 1),
+		"Entry":         o.Entry("b").Set(2),
+		"Move":          o.Move("a", 0),
+		"UnmarshalJSON": o.UnmarshalJSON([]byte(`{}`)),
+		"Batch":         o.Batch(func(tx *Tx) error { return nil }),
+	} {
+		if !errors.Is(err, ErrFrozen) {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+	for name, f := range map[string]func(){
+		"Delete":   func() { o.Delete("b") },
+		"SortKeys": func() { o.SortKeys(sort.Strings) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrFrozen {
+					t.Errorf("%s: recovered %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+
+	a := o.Get("a").(OrderedMap)
+	if err := a.TrySet("x", 2); !errors.Is(err, ErrFrozen) {
+		t.Error("nested map not frozen", err)
+	}
+	y := o.Get("s").([]any)[0].(OrderedMap)
+	if !y.Frozen() {
+		t.Error("map in slice not frozen")
+	}
+
+	s := o.Snapshot()
+	if err := s.TrySet("c", 1); err != nil || o.Get("c") != nil {
+		t.Error("Snapshot of frozen map", err)
+	}
+}
+
+func TestOrderedMap_Freeze_KeysValues(t *testing.T) {
+	for _, n := range []int{2, 2 * smallSize} {
+		o := New()
+		for i := range n {
+			o.Set("k"+strconv.Itoa(i), i)
+		}
+		o.Freeze()
+		o.KeysValues()["k0"] = "tampered"
+		if o.Get("k0") != 0 {
+			t.Errorf("%d keys: frozen map modified through KeysValues: %v", n, o.Get("k0"))
+		}
+	}
+}
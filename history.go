@@ -0,0 +1,166 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "time"
+
+// Change is a change to an OrderedMap, as recorded by History.  Op, Key, Old
+// and New are as given to an OnChange func.
+type Change struct {
+	Op       Op
+	Key      string
+	Old, New any
+
+	// pos is the former position of a deleted key.
+	pos int
+	// prev is the storage replaced by OpSort, OpUnmarshal and OpBatch.
+	prev *state
+}
+
+// state is the storage of an OrderedMap.
+type state struct {
+	keys    []string
+	vals    []any
+	values  map[string]any
+	expires map[string]time.Time
+	raws    map[string]span
+	layout  *layout
+}
+
+// saveState returns the storage of o, which is copied before o is next
+// modified.
+func (o *OrderedMap) saveState() *state {
+	o.shared = true
+	return &state{o.keys, o.vals, o.values, o.expires, o.raws, o.layout}
+}
+
+// restoreState swaps the storage of o with s.
+func (o *OrderedMap) restoreState(s *state) *state {
+	cur := o.saveState()
+	o.keys, o.vals, o.values, o.expires, o.raws, o.layout = s.keys, s.vals, s.values, s.expires, s.raws, s.layout
+	return cur
+}
+
+// history is the journal of an OrderedMap.
+type history struct {
+	// done is undone by Undo, most recent last, and undone redone by Redo.
+	done, undone []Change
+	max          int
+}
+
+// record appends c to the journal, discarding changes that were undone.
+func (h *history) record(c Change) {
+	if h.max > 0 && len(h.done) == h.max {
+		clear(h.done[:1])
+		h.done = h.done[1:]
+	}
+	h.done = append(h.done, c)
+	clear(h.undone)
+	h.undone = h.undone[:0]
+}
+
+// EnableHistory starts recording changes to o for Undo and Redo, keeping at
+// most max changes, or all changes if max is 0.  Sort, SortKeys and
+// UnmarshalJSON are recorded by retaining the previous storage, and so cost a
+// copy of the keys.  It replaces any existing history.
+func (o *OrderedMap) EnableHistory(max int) {
+	o.history = &history{max: max}
+}
+
+// DisableHistory stops recording changes and discards the history.
+func (o *OrderedMap) DisableHistory() {
+	o.history = nil
+}
+
+// History returns the changes that Undo would undo, oldest first.
+func (o *OrderedMap) History() []Change {
+	if o.history == nil {
+		return nil
+	}
+	return append([]Change(nil), o.history.done...)
+}
+
+// Undo reverts the most recent recorded change, and reports whether there was
+// one.  A restored key does not keep its SetWithTTL expiry.  Reverted changes
+// are reported to an OnChange func, with OpSort, OpUnmarshal and OpBatch
+// reported as OpUnmarshal, and are not validated.
+func (o *OrderedMap) Undo() bool {
+	h := o.history
+	if h == nil || len(h.done) == 0 {
+		return false
+	}
+	c := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	o.history = nil
+	defer func() { o.history = h }()
+
+	switch c.Op {
+	case OpInsert:
+		o.Delete(c.Key)
+	case OpSet:
+		o.set(c.Key, c.Old)
+	case OpDelete:
+		o.set(c.Key, c.Old)
+		o.Move(c.Key, c.pos)
+	case OpMove:
+		o.Move(c.Key, c.Old.(int))
+	default:
+		o.swapState(&c)
+	}
+	h.undone = append(h.undone, c)
+	return true
+}
+
+// Redo reapplies the most recently undone change, and reports whether there
+// was one.  See Undo.  Recording a new change discards undone changes.
+func (o *OrderedMap) Redo() bool {
+	h := o.history
+	if h == nil || len(h.undone) == 0 {
+		return false
+	}
+	c := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	o.history = nil
+	defer func() { o.history = h }()
+
+	switch c.Op {
+	case OpInsert, OpSet:
+		o.set(c.Key, c.New)
+	case OpDelete:
+		o.Delete(c.Key)
+	case OpMove:
+		o.Move(c.Key, c.New.(int))
+	default:
+		o.swapState(&c)
+	}
+	h.done = append(h.done, c)
+	return true
+}
+
+// swapState swaps the storage of o with that retained by c.
+func (o *OrderedMap) swapState(c *Change) {
+	c.prev = o.restoreState(c.prev)
+	if o.onChange != nil {
+		o.onChange(OpUnmarshal, "", nil, nil)
+	}
+}
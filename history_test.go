@@ -0,0 +1,107 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOrderedMap_History(t *testing.T) {
+	o := New()
+	o.Set("b", 1)
+	o.EnableHistory(0)
+
+	var states [][]any // keys and values after each change
+	snap := func() { states = append(states, []any{o.KeysCopy(), o.Values()}) }
+	snap()
+	o.Set("a", 2)
+	snap()
+	o.Set("b", 3)
+	snap()
+	o.SortKeys(sort.Strings)
+	snap()
+	o.Delete("a")
+	snap()
+	o.Set("c", 4)
+	o.Move("c", 0)
+	snap()
+	o.UnmarshalJSON([]byte(`{"x":1}`))
+	snap()
+	o.Batch(func(tx *Tx) error {
+		tx.Delete("x")
+		return tx.TrySet("y", 2)
+	})
+	snap()
+
+	ops := []Op{OpInsert, OpSet, OpSort, OpDelete, OpInsert, OpMove, OpUnmarshal, OpBatch}
+	var got []Op
+	for _, c := range o.History() {
+		got = append(got, c.Op)
+	}
+	if !reflect.DeepEqual(got, ops) {
+		t.Fatal("History", got)
+	}
+
+	check := func(name string, i int) {
+		t.Helper()
+		if s := []any{o.KeysCopy(), o.Values()}; !reflect.DeepEqual(s, states[i]) {
+			t.Errorf("%s to state %d: got %v, want %v", name, i, s, states[i])
+		}
+	}
+	// steps[i] is the number of changes from states[i] to states[i+1].
+	steps := []int{1, 1, 1, 1, 2, 1, 1}
+	for i := len(steps) - 1; i >= 0; i-- {
+		for n := 0; n < steps[i]; n++ {
+			o.Undo()
+		}
+		check("Undo", i)
+	}
+	if o.Undo() {
+		t.Error("Undo past start")
+	}
+	for i := range steps {
+		for n := 0; n < steps[i]; n++ {
+			o.Redo()
+		}
+		check("Redo", i+1)
+	}
+	if o.Redo() {
+		t.Error("Redo past end")
+	}
+
+	o.Undo()
+	o.Set("z", 1) // discards the undone change
+	if o.Redo() {
+		t.Error("Redo after new change")
+	}
+
+	o.EnableHistory(2)
+	o.Set("1", 1)
+	o.Set("2", 2)
+	o.Set("3", 3)
+	if len(o.History()) != 2 || o.History()[0].Key != "2" {
+		t.Error("max", o.History())
+	}
+}
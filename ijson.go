@@ -0,0 +1,157 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrIJSON is wrapped by errors for input that is not I-JSON.
+var ErrIJSON = errors.New("orderedmap: not I-JSON")
+
+// IJSON makes UnmarshalJSON reject input that is not I-JSON (RFC 7493), as
+// CheckIJSON.  Duplicates are always rejected.
+func IJSON() Option {
+	return func(opts *options) { opts.ijson = true }
+}
+
+// CheckIJSON checks that b is I-JSON (RFC 7493): JSON with a top-level object
+// or array that is valid UTF-8, without duplicate keys, without surrogate or
+// noncharacter code points in strings, including escaped, and without numbers
+// beyond the range of IEEE 754 double precision, or integers beyond its exact
+// range of ±(2^53-1).  Errors other than from the JSON syntax and duplicates
+// wrap ErrIJSON.
+func CheckIJSON(b []byte) error {
+	if err := json.Unmarshal(b, new(json.RawMessage)); err != nil {
+		return err
+	}
+	if t := bytes.TrimLeft(b, " \t\r\n"); t[0] != '{' && t[0] != '[' {
+		return fmt.Errorf("%w: top-level value is not an object or array", ErrIJSON)
+	}
+	if err := checkIJSON(b); err != nil {
+		return err
+	}
+	return CheckDuplicateBytes(b)
+}
+
+// maxExactInt is the largest integer magnitude of the range RFC 7493 requires
+// for interoperability.
+const maxExactInt = 1<<53 - 1
+
+// checkIJSON checks the strings and numbers of the JSON b.
+func checkIJSON(b []byte) error {
+	if !utf8.Valid(b) {
+		return fmt.Errorf("%w: invalid UTF-8", ErrIJSON)
+	}
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c == '"':
+			n, err := checkIJSONString(b, i)
+			if err != nil {
+				return err
+			}
+			i = n
+		case c == '-' || '0' <= c && c <= '9':
+			j := i + 1
+			for j < len(b) && bytes.IndexByte([]byte("0123456789.eE+-"), b[j]) >= 0 {
+				j++
+			}
+			if err := checkIJSONNumber(b[i:j], i); err != nil {
+				return err
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// checkIJSONString checks the string beginning at b[i] and returns the offset
+// following it.
+func checkIJSONString(b []byte, i int) (int, error) {
+	start := i
+	for i++; b[i] != '"'; {
+		var r rune
+		if b[i] == '\\' {
+			if b[i+1] != 'u' {
+				i += 2
+				continue
+			}
+			r = hexRune(b[i+2 : i+6])
+			i += 6
+			if utf16.IsSurrogate(r) {
+				// A high surrogate followed by a low surrogate is a pair.
+				var r2 rune = utf8.RuneError
+				if i+6 <= len(b) && b[i] == '\\' && b[i+1] == 'u' {
+					r2 = hexRune(b[i+2 : i+6])
+				}
+				if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+					return 0, fmt.Errorf("%w: surrogate escape in string at offset %d", ErrIJSON, start)
+				}
+				i += 6
+			}
+		} else {
+			var size int
+			r, size = utf8.DecodeRune(b[i:])
+			i += size
+		}
+		if isNoncharacter(r) {
+			return 0, fmt.Errorf("%w: noncharacter %U in string at offset %d", ErrIJSON, r, start)
+		}
+	}
+	return i + 1, nil
+}
+
+func hexRune(h []byte) rune {
+	n, _ := strconv.ParseUint(string(h), 16, 32)
+	return rune(n)
+}
+
+// isNoncharacter reports whether r is a Unicode noncharacter: U+FDD0 to
+// U+FDEF, and the last two code points of each plane.
+func isNoncharacter(r rune) bool {
+	return 0xFDD0 <= r && r <= 0xFDEF || r&0xFFFE == 0xFFFE
+}
+
+// checkIJSONNumber checks the number n at offset.
+func checkIJSONNumber(n []byte, offset int) error {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return fmt.Errorf("%w: number %s out of range at offset %d", ErrIJSON, n, offset)
+	}
+	if bytes.IndexAny(n, ".eE") >= 0 || math.Abs(f) < maxExactInt {
+		return nil
+	}
+	if i, err := strconv.ParseInt(string(n), 10, 64); err != nil || i > maxExactInt || i < -maxExactInt {
+		return fmt.Errorf("%w: integer %s not exact at offset %d", ErrIJSON, n, offset)
+	}
+	return nil
+}
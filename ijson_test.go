@@ -0,0 +1,86 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckIJSON(t *testing.T) {
+	for _, s := range []string{
+		`{"a":"😀","b":"😀","c":[-9007199254740991,9007199254740991,1e300,0.1,-0]}`,
+		`[1,"�"]`,
+		`{"\\u0041":"\\\""}`,
+	} {
+		if err := CheckIJSON([]byte(s)); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	for _, s := range []string{
+		"{\"a\":\"\xff\"}",
+		`{"a":"\ud83d"}`,
+		`{"a":"\ude00\ud83d"}`,
+		`{"a":"\ud83dx"}`,
+		`{"\ud800":1}`,
+		`{"a":"﷐"}`,
+		`{"a":"` + "\U0001FFFF" + `"}`,
+		`{"a":1e400}`,
+		`{"a":9007199254740992}`,
+		`{"a":-9007199254740993}`,
+		`{"a":123456789012345678901234567890}`,
+		`"a"`,
+	} {
+		if err := CheckIJSON([]byte(s)); !errors.Is(err, ErrIJSON) {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	if err := CheckIJSON([]byte(`{"a":1,"a":2}`)); !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("duplicate:", err)
+	}
+	if err := CheckIJSON([]byte(`{"a":`)); err == nil || errors.Is(err, ErrIJSON) {
+		t.Error("syntax error:", err)
+	}
+}
+
+func TestIJSON(t *testing.T) {
+	o := New(IJSON())
+	if err := o.UnmarshalJSON([]byte(`{"a":"😀","b":[1,2.5]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if o.Get("a") != "😀" {
+		t.Error(o.Get("a"))
+	}
+	for _, s := range []string{`{"a":"\udead"}`, `{"a":1e999}`, "{\"a\":\"\xc0\"}"} {
+		if err := o.UnmarshalJSON([]byte(s)); !errors.Is(err, ErrIJSON) {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	// Without IJSON, encoding/json replaces lone surrogates.
+	if err := New().UnmarshalJSON([]byte(`{"a":"\udead"}`)); err != nil {
+		t.Error(err)
+	}
+	if err := o.UnmarshalJSON([]byte(`{"a":`)); err == nil || errors.Is(err, ErrIJSON) {
+		t.Error("syntax error:", err)
+	}
+}
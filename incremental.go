@@ -0,0 +1,82 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "bytes"
+
+// CacheEncoding makes MarshalJSON keep the encoding of each member, at any
+// depth, and reuse it until the key is set or deleted, so that encoding a large
+// map again after a few changes only encodes the changed members, at the cost
+// of keeping a copy of the encoding.  Nested maps are encoded member by member,
+// so their changes are always detected, but other modifications of values in
+// place, such as of slices, are not, so set the key again after modifying one.
+// MarshalJSON updates the cache, so it is not safe for concurrent use with
+// CacheEncoding unless the map is frozen, in which case the cache is only read,
+// as it is with ParallelMarshal.
+func CacheEncoding() Option {
+	return func(opts *options) { opts.cache = true }
+}
+
+// DirtyKeys returns, in order, the keys of o with CacheEncoding that will be
+// encoded by the next MarshalJSON, as they have been set since the last.
+// Keys with map values are never cached, so are not reported.
+func (o *OrderedMap) DirtyKeys() []string {
+	var keys []string
+	for i, k := range o.keys {
+		if _, isMap := asMap(o.rawAt(i)); isMap {
+			continue
+		}
+		if _, ok := o.cachedMember(k, nil); !ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// cachedMember returns the cached encoding of the member key with value v.
+func (o *OrderedMap) cachedMember(key string, v any) ([]byte, bool) {
+	if o.raws == nil {
+		return nil, false
+	}
+	if _, isMap := asMap(v); isMap {
+		return nil, false
+	}
+	r, ok := o.raws[o.mapKey(key)]
+	return r.b, ok && r.cached && r.key == key
+}
+
+// cacheMember caches the encoding b of the member key with value v, unless v
+// is a map, or the input of v is kept by KeepRaw.
+func (o *OrderedMap) cacheMember(key string, v any, b []byte) {
+	if o.raws == nil || o.frozen || o.opts == nil || !o.opts.cache {
+		return
+	}
+	if _, isMap := asMap(v); isMap {
+		return
+	}
+	mk := o.mapKey(key)
+	if r, ok := o.raws[mk]; ok && !r.cached {
+		return
+	}
+	o.raws[mk] = span{b: bytes.Clone(b), key: key, cached: true}
+}
@@ -0,0 +1,113 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestCacheEncoding(t *testing.T) {
+	o := New(CacheEncoding())
+	plain := New()
+	for i := range 40 {
+		k := "k" + strconv.Itoa(i)
+		o.Set(k, []any{i, "<" + k + ">"})
+		plain.Set(k, []any{i, "<" + k + ">"})
+	}
+	o.Set("m", *New(CacheEncoding()))
+	plain.Set("m", *New())
+	check := func() {
+		t.Helper()
+		got, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := plain.MarshalJSON()
+		if string(got) != string(want) {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	if len(o.DirtyKeys()) != 40 {
+		t.Errorf("DirtyKeys = %v", o.DirtyKeys())
+	}
+	check()
+	if d := o.DirtyKeys(); d != nil {
+		t.Errorf("DirtyKeys after marshal = %v", d)
+	}
+	o.Set("k3", "x")
+	plain.Set("k3", "x")
+	o.Delete("k4")
+	plain.Delete("k4")
+	if d := o.DirtyKeys(); !slices.Equal(d, []string{"k3"}) {
+		t.Errorf("DirtyKeys = %v", d)
+	}
+	check()
+
+	m := o.Get("m").(OrderedMap)
+	m.Set("a", 1)
+	o.Set("m", m)
+	pm := plain.Get("m").(OrderedMap)
+	pm.Set("a", 1)
+	plain.Set("m", pm)
+	check()
+
+	snap := o.Snapshot()
+	o.Set("k5", 5)
+	plain.Set("k5", 5)
+	check()
+	if b, _ := snap.MarshalJSON(); slices.Contains(snap.DirtyKeys(), "k5") || string(b[:30]) != `{"k0":[0,"<k0>"],"k1":[1,"<k1>` {
+		t.Errorf("snapshot = %s, DirtyKeys %v", b[:30], snap.DirtyKeys())
+	}
+
+	r := New(CacheEncoding(), KeepRaw())
+	if err := r.UnmarshalJSON([]byte(`{"a":1.0,"b":{"c":2.0}}`)); err != nil {
+		t.Fatal(err)
+	}
+	r.Set("d", 3.0)
+	for range 2 {
+		if b, _ := r.MarshalJSON(); string(b) != `{"a":1.0,"b":{"c":2.0},"d":3}` {
+			t.Errorf("got %s", b)
+		}
+	}
+}
+
+func BenchmarkCacheEncoding(b *testing.B) {
+	for name, opts := range map[string][]Option{"uncached": nil, "cached": {CacheEncoding()}} {
+		b.Run(name, func(b *testing.B) {
+			o := New(opts...)
+			for i := range 50000 {
+				o.Set("key"+strconv.Itoa(i), map[string]any{"n": i, "s": "value"})
+			}
+			b.ReportAllocs()
+			for i := range b.N {
+				o.Set("key7", i)
+				if _, err := o.MarshalJSON(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
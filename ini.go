@@ -0,0 +1,191 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MarshalINI encodes o as an INI file: the values of o other than maps, first,
+// as key = value lines before any section, then each map as a section, in
+// order, of its values as lines.  Maps nested in sections are written as
+// sections named by the path of keys joined by ".", such as [a.b], after the
+// lines of their parent.  Values are formatted like ToCSVRecord, and quoted
+// as JSON strings if they would not read back the same.  Keys that cannot be
+// written are rejected.
+func (o OrderedMap) MarshalINI() ([]byte, error) {
+	var buf bytes.Buffer
+	var sections []int
+	for i, k := range o.keys {
+		if _, ok := asMap(o.valueAt(i)); ok {
+			sections = append(sections, i)
+			continue
+		}
+		if err := writeINILine(&buf, k, o.valueAt(i)); err != nil {
+			return nil, err
+		}
+	}
+	for _, i := range sections {
+		m, _ := asMap(o.valueAt(i))
+		if err := writeINISection(&buf, "", o.keys[i], m); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeINISection writes the section of map m at key of the section parent,
+// which is "" at the top level.
+func writeINISection(buf *bytes.Buffer, parent, key string, m *OrderedMap) error {
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	// "." separates the keys of nested sections.
+	if key == "" || strings.ContainsAny(key, ".[]\r\n") {
+		return fmt.Errorf("orderedmap: INI section name %q", key)
+	}
+	name := key
+	if parent != "" {
+		name = parent + "." + key
+	}
+	buf.WriteString("[" + name + "]\n")
+	var sections []int
+	for i, k := range m.keys {
+		v := m.valueAt(i)
+		if _, ok := asMap(v); ok {
+			sections = append(sections, i)
+			continue
+		}
+		if err := writeINILine(buf, k, v); err != nil {
+			return err
+		}
+	}
+	for _, i := range sections {
+		n, _ := asMap(m.valueAt(i))
+		if err := writeINISection(buf, name, m.keys[i], n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeINILine(buf *bytes.Buffer, k string, v any) error {
+	if k == "" || k != strings.TrimSpace(k) || strings.ContainsAny(k, "=\r\n") || strings.ContainsAny(k[:1], ";#[") {
+		return fmt.Errorf("orderedmap: INI key %q", k)
+	}
+	s, err := csvField(v)
+	if err != nil {
+		return err
+	}
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s, "\r\n") || strings.HasPrefix(s, `"`) {
+		b, err := encodeCompact(s)
+		if err != nil {
+			return err
+		}
+		s = string(b)
+	}
+	buf.WriteString(k + " = " + s + "\n")
+	return nil
+}
+
+// UnmarshalINI replaces the contents of o with the INI file b, reversing
+// MarshalINI: key = value lines before any section are values of o, and each
+// section is a map of its lines, nested by the "." separated path of its name.
+// Values are strings, unquoted if they are quoted JSON strings.  Blank lines
+// and lines beginning with ';' or '#' are ignored.  Repeated keys and
+// sections are rejected with an error wrapping ErrJSONDuplicate.  On error o
+// is not modified.
+func (o *OrderedMap) UnmarshalINI(b []byte) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	root := o.empty()
+	created := make(map[*OrderedMap]bool)
+	sections := make(map[string]bool)
+	m := root
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(nil, len(b)+1)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || l[0] == ';' || l[0] == '#' {
+			continue
+		}
+		if l[0] == '[' {
+			name, ok := strings.CutSuffix(l[1:], "]")
+			if !ok || name == "" {
+				return fmt.Errorf("orderedmap: INI line %d: invalid section %q", line, l)
+			}
+			if sections[name] {
+				return fmt.Errorf("%w section [%s] at INI line %d", ErrJSONDuplicate, name, line)
+			}
+			sections[name] = true
+			m = root
+			for _, p := range strings.Split(name, ".") {
+				v, ok := m.get(p)
+				if !ok {
+					n := o.empty()
+					created[n] = true
+					m.set(p, n)
+					m = n
+					continue
+				}
+				n, isCreated := v.(*OrderedMap)
+				if !isCreated || !created[n] {
+					return fmt.Errorf("%w: section [%s] at INI line %d is a value", ErrKeyCollision, name, line)
+				}
+				m = n
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(l, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" {
+			return fmt.Errorf("orderedmap: INI line %d: expected key = value", line)
+		}
+		if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+			if err := json.Unmarshal([]byte(v), &v); err != nil {
+				return fmt.Errorf("orderedmap: INI line %d: %w", line, err)
+			}
+		}
+		if _, dup := m.get(k); dup {
+			return fmt.Errorf("%w %q at INI line %d", ErrJSONDuplicate, k, line)
+		}
+		if err := m.store(k, v); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if err := derefCreated(root, created); err != nil {
+		return err
+	}
+	return o.replace(func() error {
+		o.keys, o.vals, o.values = root.keys, root.vals, root.values
+		return nil
+	})
+}
@@ -0,0 +1,126 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const iniFile = `name = app
+version = 2
+
+[server]
+port = 8080
+host = " padded "
+
+[server.tls]
+cert = a.pem
+
+[db]
+url = postgres://x?a=b
+`
+
+func TestMarshalINI(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"name":"app","server":{"port":8080,"tls":{"cert":"a.pem"},"host":" padded "},"version":2,"db":{"url":"postgres://x?a=b"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := o.MarshalINI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != iniFile {
+		t.Errorf("got\n%s\nwant\n%s", b, iniFile)
+	}
+
+	for _, k := range []string{"", "a=b", " a", "[a", "a\nb"} {
+		m := New()
+		m.Set(k, 1)
+		if _, err := m.MarshalINI(); err == nil {
+			t.Errorf("no error for key %q", k)
+		}
+	}
+
+	for _, in := range []string{`{"a.b":{"c":1}}`, `{"a":{"b.c":{"d":1}}}`} {
+		m := New()
+		if err := m.UnmarshalJSON([]byte(in)); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := m.MarshalINI(); err == nil {
+			t.Errorf("%s: no error, wrote %q", in, b)
+		}
+	}
+
+	// Long values read back.
+	long := New()
+	long.Set("k", strings.Repeat("v", 100_000))
+	long.Set("s", New())
+	long.Get("s").(*OrderedMap).Set("k", "v")
+	b, err = long.MarshalINI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := New()
+	if err := back.UnmarshalINI(b); err != nil {
+		t.Fatal(err)
+	}
+	if !back.EqualOrdered(long) {
+		t.Error("long value did not round-trip")
+	}
+}
+
+func TestUnmarshalINI(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalINI([]byte("; comment\n" + iniFile + "# end\n")); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"app","version":"2","server":{"port":"8080","host":" padded ","tls":{"cert":"a.pem"}},"db":{"url":"postgres://x?a=b"}}`
+	if got := o.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if _, ok := o.Get("server").(OrderedMap); !ok {
+		t.Errorf("section is %T", o.Get("server"))
+	}
+	b, err := o.MarshalINI()
+	if err != nil || string(b) != iniFile {
+		t.Errorf("round trip\n%s %v", b, err)
+	}
+
+	for _, s := range []string{"[a]\nk=1\nk=2", "[a]\n[a]", "a=1\n[a]"} {
+		err := New().UnmarshalINI([]byte(s))
+		if !errors.Is(err, ErrJSONDuplicate) && !errors.Is(err, ErrKeyCollision) {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	for _, s := range []string{"[a", "[]", "novalue", "=1", `k = "\x"`} {
+		if err := New().UnmarshalINI([]byte(s)); err == nil {
+			t.Errorf("no error for %q", s)
+		}
+	}
+	o.Set("keep", "1")
+	if err := o.UnmarshalINI([]byte("a=1\na=2")); err == nil || o.Get("keep") != "1" {
+		t.Error("modified on error", err)
+	}
+}
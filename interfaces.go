@@ -0,0 +1,60 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "iter"
+
+// Getter looks up values by key.  It is implemented by OrderedMap, SortedMap,
+// OrderedMultiMap, Tx and ReadOnlyMap, so that functions reading a map can
+// accept any of them.
+type Getter interface {
+	// Get returns the value for key, or nil.
+	Get(key string) any
+	// Len returns the number of keys.
+	Len() int
+}
+
+// Setter modifies values by key.  It is implemented by OrderedMap, Tx and
+// OrderedBiMap.
+type Setter interface {
+	// TrySet sets the value for key, or returns an error if it is rejected.
+	TrySet(key string, value any) error
+	// Delete deletes key, if it exists.
+	Delete(key string)
+}
+
+// OrderedIterator iterates over keys in the order of the map.  It is
+// implemented by OrderedMap, SortedMap, OrderedMultiMap, PriorityMap,
+// ReadOnlyMap and persistent.Map.
+type OrderedIterator interface {
+	// All returns an iterator over the key-value pairs in order.
+	All() iter.Seq2[string, any]
+	// Keys returns the keys in order.
+	Keys() []string
+}
+
+// GetSetter is a map that can be read and modified, such as OrderedMap and Tx.
+type GetSetter interface {
+	Getter
+	Setter
+}
@@ -0,0 +1,85 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+	"github.com/cyphrme/orderedmap/persistent"
+)
+
+var (
+	_ orderedmap.GetSetter       = (*orderedmap.OrderedMap)(nil)
+	_ orderedmap.GetSetter       = (*orderedmap.Tx)(nil)
+	_ orderedmap.Setter          = (*orderedmap.OrderedBiMap)(nil)
+	_ orderedmap.Getter          = (*orderedmap.SortedMap)(nil)
+	_ orderedmap.Getter          = (*orderedmap.OrderedMultiMap)(nil)
+	_ orderedmap.Getter          = orderedmap.ReadOnlyMap(nil)
+	_ orderedmap.OrderedIterator = (*orderedmap.OrderedMap)(nil)
+	_ orderedmap.OrderedIterator = (*orderedmap.SortedMap)(nil)
+	_ orderedmap.OrderedIterator = (*orderedmap.OrderedMultiMap)(nil)
+	_ orderedmap.OrderedIterator = (*orderedmap.PriorityMap)(nil)
+	_ orderedmap.OrderedIterator = (*persistent.Map)(nil)
+)
+
+// joinKeys accepts any ordered map.
+func joinKeys(it orderedmap.OrderedIterator) []string {
+	var keys []string
+	for k := range it.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestInterfaces(t *testing.T) {
+	o := orderedmap.New()
+	s := orderedmap.NewSorted(nil)
+	for _, k := range []string{"b", "a"} {
+		o.Set(k, 1)
+		s.Set(k, 1)
+	}
+	p := persistent.New().Set("z", 1).Set("y", 2)
+	for _, c := range []struct {
+		it   orderedmap.OrderedIterator
+		want []string
+	}{
+		{o, []string{"b", "a"}},
+		{s, []string{"a", "b"}},
+		{o.ReadOnly(), []string{"b", "a"}},
+		{p, []string{"z", "y"}},
+	} {
+		if got := joinKeys(c.it); !slices.Equal(got, c.want) || !slices.Equal(c.it.Keys(), c.want) {
+			t.Errorf("%T: got %v, want %v", c.it, got, c.want)
+		}
+	}
+
+	err := o.Batch(func(tx *orderedmap.Tx) error {
+		var gs orderedmap.GetSetter = tx
+		return gs.TrySet("c", gs.Len())
+	})
+	if err != nil || o.Get("c") != 2 {
+		t.Errorf("Batch through GetSetter: %v, %v", err, o.Get("c"))
+	}
+}
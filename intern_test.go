@@ -0,0 +1,67 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestInternKeys(t *testing.T) {
+	src := []byte(`{"alg":1,"nested":{"alg":2}}`)
+	a, b := New(InternKeys()), New(InternKeys())
+	for _, o := range []*OrderedMap{a, b} {
+		if err := o.UnmarshalJSON(src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := a.Get("nested").(OrderedMap)
+	if unsafe.StringData(a.Keys()[0]) != unsafe.StringData(b.Keys()[0]) ||
+		unsafe.StringData(a.Keys()[0]) != unsafe.StringData(n.Keys()[0]) {
+		t.Error("alg not interned")
+	}
+
+	c := New()
+	c.UnmarshalJSON(src)
+	if unsafe.StringData(a.Keys()[0]) == unsafe.StringData(c.Keys()[0]) {
+		t.Error("interned without InternKeys")
+	}
+}
+
+func BenchmarkInternKeys(b *testing.B) {
+	src := []byte(`{"alg":"ES256","iat":1623132000,"tmb":"cLj8vsYtMBwYkzoFVZHBZo6SNL8wSdCIjCKAwXNuhOk","typ":"cyphr.me/msg"}`)
+	for _, opts := range [][]Option{nil, {InternKeys()}} {
+		name := "plain"
+		if opts != nil {
+			name = "intern"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if err := New(opts...).UnmarshalJSON(src); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
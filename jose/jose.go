@@ -0,0 +1,95 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package jose encodes and decodes JWS and JWE protected headers as
+// OrderedMaps in base64url, keeping the exact members of the encoded header
+// for signature verification.
+package jose
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// ErrCompact is returned by ParseCompact for a malformed JWS.
+var ErrCompact = errors.New("jose: malformed JWS compact serialization")
+
+// MarshalB64 returns the JSON of header in unpadded base64url, as a protected
+// header in a JWS or JWE.  A header from UnmarshalB64 that has not been
+// modified encodes to its input.
+func MarshalB64(header *orderedmap.OrderedMap) (string, error) {
+	b, err := header.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// UnmarshalB64 decodes the unpadded base64url JSON object s, rejecting
+// duplicate members as RFC 7515 allows.  The header keeps the formatting of
+// s, as with orderedmap.Faithful, so that MarshalB64 of it returns s.  opts
+// are applied after Faithful.
+func UnmarshalB64(s string, opts ...orderedmap.Option) (*orderedmap.OrderedMap, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("jose: header: %w", err)
+	}
+	header := orderedmap.New(append([]orderedmap.Option{orderedmap.Faithful()}, opts...)...)
+	if err := header.UnmarshalJSON(b); err != nil {
+		return nil, fmt.Errorf("jose: header: %w", err)
+	}
+	return header, nil
+}
+
+// SigningInput returns the JWS signing input of header and payload, their
+// base64url encodings joined by '.'.
+func SigningInput(header *orderedmap.OrderedMap, payload []byte) (string, error) {
+	h, err := MarshalB64(header)
+	if err != nil {
+		return "", err
+	}
+	return h + "." + base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// ParseCompact splits a JWS in the compact serialization, decoding its
+// protected header with UnmarshalB64.  The signing input is the token up to
+// the last '.'.  The signature is not verified.
+func ParseCompact(token string, opts ...orderedmap.Option) (header *orderedmap.OrderedMap, payload, signature []byte, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, fmt.Errorf("%w: %d parts", ErrCompact, len(parts))
+	}
+	if header, err = UnmarshalB64(parts[0], opts...); err != nil {
+		return nil, nil, nil, err
+	}
+	if payload, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: payload: %w", ErrCompact, err)
+	}
+	if signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: signature: %w", ErrCompact, err)
+	}
+	return header, payload, signature, nil
+}
@@ -0,0 +1,111 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jose
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// The JWS of RFC 7515, appendix A.1.
+const (
+	rfcHeader = "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9"
+	rfcToken  = rfcHeader +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func TestUnmarshalB64(t *testing.T) {
+	h, err := UnmarshalB64(rfcHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Keys(); !slices.Equal(got, []string{"typ", "alg"}) {
+		t.Error("keys", got)
+	}
+	// The header has a CRLF and a space, which are kept.
+	s, err := MarshalB64(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != rfcHeader {
+		t.Errorf("got %s, want %s", s, rfcHeader)
+	}
+
+	for _, bad := range []string{"eyJ9=", "e30=", "eyJhIjoxLCJhIjoyfQ"} {
+		if _, err := UnmarshalB64(bad); err == nil {
+			t.Errorf("no error for %s", bad)
+		}
+	}
+	_, err = UnmarshalB64("eyJhIjoxLCJhIjoyfQ") // {"a":1,"a":2}
+	if !errors.Is(err, orderedmap.ErrJSONDuplicate) {
+		t.Error("duplicate error is not ErrJSONDuplicate:", err)
+	}
+}
+
+func TestMarshalB64(t *testing.T) {
+	h := orderedmap.New()
+	h.Set("alg", "ES256")
+	h.Set("kid", "a")
+	s, err := MarshalB64(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "eyJhbGciOiJFUzI1NiIsImtpZCI6ImEifQ" {
+		t.Error(s)
+	}
+	in, err := SigningInput(h, []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in != s+".e30" {
+		t.Error(in)
+	}
+}
+
+func TestParseCompact(t *testing.T) {
+	h, payload, sig, err := ParseCompact(rfcToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Get("alg") != "HS256" || !strings.HasPrefix(string(payload), `{"iss":"joe"`) || len(sig) != 32 {
+		t.Error(h, string(payload), sig)
+	}
+	in, err := SigningInput(h, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := rfcToken[:strings.LastIndexByte(rfcToken, '.')]; in != want {
+		t.Errorf("signing input %s, want %s", in, want)
+	}
+
+	for _, bad := range []string{"a.b", rfcHeader + ".!.", rfcHeader + ".e30.!"} {
+		if _, _, _, err := ParseCompact(bad); !errors.Is(err, ErrCompact) {
+			t.Errorf("%s: error %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build go1.27 && goexperiment.jsonv2
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+)
+
+// The json/v2 methods delegate to MarshalJSON and UnmarshalJSON so that
+// OrderedMap behaves the same under encoding/json, which in Go 1.27 with
+// json/v2 prefers them, and encoding/json/v2.
+
+// MarshalJSONTo implements json/v2's MarshalerTo, writing o to enc in order.
+func (o OrderedMap) MarshalJSONTo(enc *jsontext.Encoder) error {
+	b, err := o.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return enc.WriteValue(b)
+}
+
+// UnmarshalJSONFrom implements json/v2's UnmarshalerFrom, replacing the
+// contents of o with the next JSON value from dec, like UnmarshalJSON.
+// Duplicate keys are always rejected, even if dec's options allow duplicate
+// names, as they do when called by encoding/json.  Error offsets, and key
+// offsets recorded with KeyOffsets, are offsets in dec's input.
+func (o *OrderedMap) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	v, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+	base := dec.InputOffset() - int64(len(v))
+	if o.opts != nil && o.opts.zeroCopy {
+		v = bytes.Clone(v) // dec reuses its buffer
+	}
+	if err := o.UnmarshalJSON(v); err != nil {
+		return rebaseOffset(err, base)
+	}
+	o.shiftOffsets(func(off int64) int64 { return off + base })
+	return nil
+}
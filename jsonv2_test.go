@@ -0,0 +1,99 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build go1.27 && goexperiment.jsonv2
+
+package orderedmap
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONv2(t *testing.T) {
+	s := `{"z":1,"a":[true,null,{"y":"<>","b":{}}],"m":"x"}`
+	o := New()
+	if err := jsonv2.Unmarshal([]byte(s), o); err != nil {
+		t.Fatal(err)
+	}
+	if o.GetKeyAt(0) != "z" || o.Get("z") != float64(1) {
+		t.Error("json/v2 Unmarshal", o.Keys(), o.Values())
+	}
+	a := o.Get("a").([]any)
+	if _, ok := a[2].(OrderedMap); !ok {
+		t.Errorf("nested object decoded as %T", a[2])
+	}
+
+	b, err := jsonv2.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != s {
+		t.Error("json/v2 Marshal", string(b))
+	}
+
+	// Also within other types.
+	var v struct {
+		M OrderedMap
+		P *OrderedMap
+	}
+	if err = jsonv2.Unmarshal([]byte(`{"M":{"b":1,"a":2},"P":{"d":1,"c":2}}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ = jsonv2.Marshal(v); string(b) != `{"M":{"b":1,"a":2},"P":{"d":1,"c":2}}` {
+		t.Error("json/v2 Marshal struct", string(b))
+	}
+
+	// Duplicates
+	err = jsonv2.Unmarshal([]byte(`{"a":{"b":1,"b":2}}`), New())
+	if err == nil {
+		t.Error("json/v2 Unmarshal did not reject duplicate")
+	}
+	err = jsonv2.Unmarshal([]byte(`{"a":{"b":1,"B":2}}`), New(CaseInsensitive()))
+	var de *DuplicateError
+	if !errors.As(err, &de) || de.Path != "a.B" {
+		t.Error("json/v2 Unmarshal did not reject case-insensitive duplicate", err)
+	}
+
+	// Limits
+	err = jsonv2.Unmarshal([]byte(`{"a":[[1]]}`), New(WithLimits(Limits{MaxDepth: 2})))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Error("json/v2 Unmarshal did not enforce MaxDepth", err)
+	}
+
+	// Streaming
+	s = `{"a":1} {"b":2} {"c":1,"c":2}`
+	dec := jsontext.NewDecoder(strings.NewReader(s), jsontext.AllowDuplicateNames(true))
+	for _, k := range []string{"a", "b"} {
+		m := New()
+		if err = jsonv2.UnmarshalDecode(dec, m); err != nil || m.GetKeyAt(0) != k {
+			t.Error("json/v2 UnmarshalDecode stream", err, m.Keys())
+		}
+	}
+	err = jsonv2.UnmarshalDecode(dec, New())
+	if !errors.As(err, &de) || de.Offset != int64(strings.LastIndex(s, `"c"`)) {
+		t.Error("json/v2 duplicate offset in stream", err)
+	}
+}
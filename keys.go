@@ -0,0 +1,61 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// KeyString returns the JSON object key for k by the rules of encoding/json
+// for map keys, which Marshal also follows: a value of a string type is used
+// as is, an encoding.TextMarshaler is written by MarshalText, and a value of
+// an integer type is written in decimal.  Other values are rejected with a
+// *json.UnsupportedTypeError.
+func KeyString(k any) (string, error) {
+	v := reflect.ValueOf(k)
+	if !v.IsValid() {
+		return "", &json.UnsupportedTypeError{Type: reflect.TypeFor[any]()}
+	}
+	return mapKeyString(v)
+}
+
+// SetAnyKey sets the value for the key KeyString returns for k.
+func (o *OrderedMap) SetAnyKey(k, value any) error {
+	key, err := KeyString(k)
+	if err != nil {
+		return err
+	}
+	return o.TrySet(key, value)
+}
+
+// GetAnyKey returns the value for the key KeyString returns for k, and
+// whether it exists.  It returns false if k cannot be a key.
+func (o *OrderedMap) GetAnyKey(k any) (any, bool) {
+	key, err := KeyString(k)
+	if err != nil {
+		return nil, false
+	}
+	o.expire(key)
+	return o.get(key)
+}
@@ -0,0 +1,74 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[l]), nil
+}
+
+func TestKeyString(t *testing.T) {
+	type name string
+	for _, c := range []struct {
+		k    any
+		want string
+	}{
+		{"a", "a"},
+		{name("n"), "n"},
+		{-12, "-12"},
+		{uint8(255), "255"},
+		{level(1), "high"},
+		{netip.MustParseAddr("::1"), "::1"},
+	} {
+		if got, err := KeyString(c.k); err != nil || got != c.want {
+			t.Errorf("KeyString(%v) = %q, %v, want %q", c.k, got, err, c.want)
+		}
+	}
+	var ute *json.UnsupportedTypeError
+	for _, k := range []any{1.5, nil, []int{1}} {
+		if _, err := KeyString(k); !errors.As(err, &ute) {
+			t.Errorf("KeyString(%v): got %v", k, err)
+		}
+	}
+
+	o := New()
+	o.SetAnyKey(2, "two")
+	o.SetAnyKey(level(0), "l")
+	if err := o.SetAnyKey(2.5, "x"); err == nil {
+		t.Error("float key accepted")
+	}
+	if v, ok := o.GetAnyKey(2); !ok || v != "two" {
+		t.Errorf("GetAnyKey(2) = %v, %v", v, ok)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"2":"two","low":"l"}` {
+		t.Errorf("got %s", b)
+	}
+}
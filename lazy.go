@@ -0,0 +1,89 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Lazy makes UnmarshalJSON store each value as its raw JSON and parse it only
+// when first accessed, which saves decoding values that are never used.  The
+// input is still fully checked for syntax, duplicates and Limits.  Nested maps
+// are lazy too.  MarshalJSON writes unparsed values as their raw JSON.
+//
+// Values of keys registered with RegisterType are decoded immediately.  Lazy
+// has no effect on maps with a validator, which must be given every value, or
+// with KeyOffsets or KeepRaw.  A lazy value of a registered nested key that
+// fails to decode is accessed as its json.RawMessage.
+func Lazy() Option {
+	return func(opts *options) { opts.lazy = true }
+}
+
+// lazyValue is the raw JSON of a value that has not been parsed.
+type lazyValue json.RawMessage
+
+func (v lazyValue) MarshalJSON() ([]byte, error) {
+	return v, nil
+}
+
+// decodesLazily reports whether o decodes values lazily.
+func (o *OrderedMap) decodesLazily() bool {
+	return o.opts != nil && o.opts.lazy && o.opts.validator == nil && !o.opts.offsets && !o.opts.raw
+}
+
+// parseLazy parses v as decoded by UnmarshalJSON.
+func (o *OrderedMap) parseLazy(v lazyValue) any {
+	dec := json.NewDecoder(bytes.NewReader(v))
+	token, err := dec.Token()
+	if err != nil {
+		return json.RawMessage(v)
+	}
+	parent := o
+	if o.opts != nil && (o.opts.offsets || o.opts.raw) {
+		// v is not the input of o, so nested maps cannot record it.
+		opts := o.opts.clone()
+		opts.offsets, opts.raw, opts.faithful = false, false, false
+		parent = &OrderedMap{opts: opts}
+	}
+	p, err := decodeValue(dec, token, parent)
+	if err != nil {
+		return json.RawMessage(v)
+	}
+	if o.frozen {
+		p = freezeValue(p)
+	}
+	return p
+}
+
+// UnmarshalJSONKeys is UnmarshalJSON, but decodes only the values of the given
+// top-level keys.  The values of other keys keep their order and are stored
+// as raw JSON, parsed only if accessed, and are not validated.  The input is
+// still fully checked for syntax, duplicates and Limits.
+func (o *OrderedMap) UnmarshalJSONKeys(b []byte, keys ...string) error {
+	only := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		only[o.mapKey(k)] = true
+	}
+	return o.unmarshal(b, only)
+}
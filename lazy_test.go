@@ -0,0 +1,83 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	in := `{"a": 1, "b": {"c": [1, 2]}, "d": "x"}`
+	o := New(Lazy())
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := o.rawAt(1).(lazyValue); !ok {
+		t.Fatalf("value not lazy: %T", o.rawAt(1))
+	}
+	b, err := json.Marshal(o)
+	if err != nil || string(b) != `{"a":1,"b":{"c":[1,2]},"d":"x"}` {
+		t.Error("MarshalJSON of unparsed map", string(b), err)
+	}
+
+	m, ok := o.Get("b").(OrderedMap)
+	if !ok {
+		t.Fatalf("Get: %T", o.Get("b"))
+	}
+	if _, ok := o.rawAt(1).(OrderedMap); !ok {
+		t.Error("parsed value not stored")
+	}
+	if s, ok := m.Get("c").([]any); !ok || len(s) != 2 || s[1] != 2.0 {
+		t.Errorf("nested value %#v", m.Get("c"))
+	}
+	if o.Values()[2] != "x" {
+		t.Error("Values", o.Values())
+	}
+
+	// Input is fully checked.
+	if err = o.UnmarshalJSON([]byte(`{"a":{"b":1,"b":2}}`)); !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("duplicate", err)
+	}
+}
+
+func TestOrderedMap_UnmarshalJSONKeys(t *testing.T) {
+	o := New()
+	err := o.UnmarshalJSONKeys([]byte(`{"big":{"a":[1,2,3]},"alg":"ES256","tmb":"x"}`), "alg", "tmb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.rawAt(1) != "ES256" || o.rawAt(2) != "x" {
+		t.Error("selected keys not decoded", o.rawAt(1), o.rawAt(2))
+	}
+	if _, ok := o.rawAt(0).(lazyValue); !ok || o.Keys()[0] != "big" {
+		t.Errorf("skipped key %T", o.rawAt(0))
+	}
+	if _, ok := o.Get("big").(OrderedMap); !ok {
+		t.Errorf("skipped value on access %T", o.Get("big"))
+	}
+	if err = o.UnmarshalJSONKeys([]byte(`{"big":{"a":1,"a":2},"alg":1}`), "alg"); !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("duplicate in skipped value", err)
+	}
+}
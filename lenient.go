@@ -0,0 +1,217 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Lenient makes UnmarshalJSON accept the JSONC and JSON5 extensions common in
+// hand-edited configuration: // and /* */ comments, trailing commas in
+// objects and arrays, unquoted identifier keys, and single-quoted strings.
+// Other JSON5 extensions, such as hexadecimal numbers and Infinity, are not
+// supported.  Key order is recorded and duplicates rejected as in strict mode,
+// and error offsets are offsets in the lenient input.  MarshalJSON output is
+// strict JSON.
+func Lenient() Option {
+	return func(opts *options) { opts.lenient = true }
+}
+
+// checkpoint records that strict offset out corresponds to lenient offset src.
+type checkpoint struct {
+	out, src int
+}
+
+// offsetMap maps offsets of the strict JSON returned by toStrict to offsets
+// of its input, where they differ by insertions and deletions.
+type offsetMap []checkpoint
+
+// source returns the lenient offset of strict offset off.
+func (m offsetMap) source(off int64) int64 {
+	i := sort.Search(len(m), func(i int) bool { return int64(m[i].out) > off })
+	if i == 0 {
+		return off
+	}
+	cp := m[i-1]
+	return int64(cp.src) + off - int64(cp.out)
+}
+
+// rebase maps the offset of err from strict to lenient input, and returns err.
+func (m offsetMap) rebase(err error) error {
+	var de *DuplicateError
+	var le *LimitError
+	var se *json.SyntaxError
+	switch {
+	case errors.As(err, &de):
+		de.Offset = m.source(de.Offset)
+	case errors.As(err, &le):
+		le.Offset = m.source(le.Offset)
+	case errors.As(err, &se):
+		se.Offset = m.source(se.Offset)
+	}
+	return err
+}
+
+// toStrict converts lenient JSON b to strict JSON.  Comments and trailing
+// commas are replaced with spaces, so that offsets only change where keys are
+// quoted and single-quoted strings are escaped.  Invalid input is passed
+// through for the JSON decoder to reject.
+func toStrict(b []byte) ([]byte, offsetMap) {
+	out := make([]byte, 0, len(b))
+	var m offsetMap
+	mark := func(src int) {
+		if len(out) != src || len(m) > 0 {
+			m = append(m, checkpoint{len(out), src})
+		}
+	}
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c == '"':
+			j := stringEnd(b, i, '"')
+			out = append(out, b[i:j]...)
+			i = j
+		case c == '\'':
+			out = append(out, '"')
+			for i++; i < len(b) && b[i] != '\''; i++ {
+				switch {
+				case b[i] == '"':
+					out = append(out, '\\', '"')
+					mark(i + 1)
+				case b[i] == '\\' && i+1 < len(b) && b[i+1] == '\'':
+					i++
+					out = append(out, '\'')
+					mark(i + 1)
+				case b[i] == '\\' && i+1 < len(b):
+					out = append(out, b[i], b[i+1])
+					i++
+				default:
+					out = append(out, b[i])
+				}
+			}
+			out = append(out, '"')
+			i++
+		case c == '/' && i+1 < len(b) && (b[i+1] == '/' || b[i+1] == '*'):
+			j := commentEnd(b, i)
+			for ; i < j; i++ {
+				if b[i] == '\n' || b[i] == '\r' {
+					out = append(out, b[i])
+				} else {
+					out = append(out, ' ')
+				}
+			}
+		case c == ',':
+			if j := skipIgnored(b, i+1); j < len(b) && (b[j] == '}' || b[j] == ']') {
+				out = append(out, ' ')
+			} else {
+				out = append(out, ',')
+			}
+			i++
+		case isIdentStart(b[i:]):
+			j := identEnd(b, i)
+			if k := skipIgnored(b, j); k < len(b) && b[k] == ':' {
+				out = append(out, '"')
+				out = append(out, b[i:j]...)
+				out = append(out, '"')
+				mark(j)
+			} else {
+				out = append(out, b[i:j]...)
+			}
+			i = j
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out, m
+}
+
+// stringEnd returns the offset after the string beginning at i with quote q.
+func stringEnd(b []byte, i int, q byte) int {
+	for i++; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case q:
+			return i + 1
+		}
+	}
+	return len(b)
+}
+
+// commentEnd returns the offset after the comment beginning at i.
+func commentEnd(b []byte, i int) int {
+	if b[i+1] == '/' {
+		for ; i < len(b) && b[i] != '\n'; i++ {
+		}
+		return i
+	}
+	for i += 2; i+1 < len(b); i++ {
+		if b[i] == '*' && b[i+1] == '/' {
+			return i + 2
+		}
+	}
+	return len(b)
+}
+
+// skipIgnored returns the offset of the first byte at or after i that is not
+// whitespace or in a comment.
+func skipIgnored(b []byte, i int) int {
+	for i < len(b) {
+		switch {
+		case isSpace(b[i]):
+			i++
+		case b[i] == '/' && i+1 < len(b) && (b[i+1] == '/' || b[i+1] == '*'):
+			i = commentEnd(b, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isIdentStart reports whether b begins with an identifier start: a letter,
+// '_' or '$'.
+func isIdentStart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+// identEnd returns the offset after the identifier beginning at i.
+func identEnd(b []byte, i int) int {
+	for i < len(b) {
+		r, n := utf8.DecodeRune(b[i:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		i += n
+	}
+	return i
+}
@@ -0,0 +1,57 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLenient(t *testing.T) {
+	in := `// config
+{
+	name: 'it\'s "ok"', /* inline */
+	"list": [1, 2, 3,],
+	$nested: {a_1: true, b: null,}, // trailing
+}`
+	o := New(Lenient())
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := o.MarshalJSON()
+	if err != nil || string(b) != `{"name":"it's \"ok\"","list":[1,2,3],"$nested":{"a_1":true,"b":null}}` {
+		t.Error("MarshalJSON", string(b), err)
+	}
+
+	dup := `{a: 1, /* comment */ 'b': 2, a: 3}`
+	err = o.UnmarshalJSON([]byte(dup))
+	var de *DuplicateError
+	if !errors.As(err, &de) || de.Key != "a" || de.Offset != int64(strings.LastIndex(dup, "a")) {
+		t.Errorf("duplicate %v", err)
+	}
+
+	if err = New().UnmarshalJSON([]byte(`{a: 1}`)); err == nil {
+		t.Error("strict mode accepted lenient input")
+	}
+}
@@ -0,0 +1,110 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NaturalLess reports whether a sorts before b in natural order, in which runs
+// of ASCII digits compare by numeric value, so that "item2" sorts before
+// "item10".  Other text compares bytewise.  Numbers equal in value but with
+// more leading zeros sort after, so that the order is total.
+func NaturalLess(a, b string) bool {
+	zeros := 0 // leading zeros of a less those of b, for the first tie
+	for a != "" && b != "" {
+		if !isDigit(a[0]) || !isDigit(b[0]) {
+			if a[0] != b[0] {
+				return a[0] < b[0]
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		na, za := digitRun(a)
+		nb, zb := digitRun(b)
+		da, db := a[za:na], b[zb:nb]
+		if len(da) != len(db) {
+			return len(da) < len(db)
+		}
+		if da != db {
+			return da < db
+		}
+		if zeros == 0 {
+			zeros = za - zb
+		}
+		a, b = a[na:], b[nb:]
+	}
+	if a != "" || b != "" {
+		return a == ""
+	}
+	return zeros < 0
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// digitRun returns the length of the run of digits at the start of s, and the
+// number of its leading zeros, not counting a final zero.
+func digitRun(s string) (n, zeros int) {
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	for zeros < n-1 && s[zeros] == '0' {
+		zeros++
+	}
+	return n, zeros
+}
+
+// CaseInsensitiveLess reports whether a sorts before b ignoring case, by
+// comparing the lower case of each rune.  Strings that differ only in case
+// compare bytewise, so that the order is total.
+func CaseInsensitiveLess(a, b string) bool {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		ra, na := utf8.DecodeRuneInString(a[i:])
+		rb, nb := utf8.DecodeRuneInString(b[j:])
+		if la, lb := unicode.ToLower(ra), unicode.ToLower(rb); la != lb {
+			return la < lb
+		}
+		i, j = i+na, j+nb
+	}
+	if n, m := utf8.RuneCountInString(a), utf8.RuneCountInString(b); n != m {
+		return n < m
+	}
+	return a < b
+}
+
+// SortFunc returns a func for SortKeys that sorts keys stably by less, such as
+// NaturalLess or CaseInsensitiveLess.
+func SortFunc(less func(a, b string) bool) func(keys []string) {
+	return func(keys []string) {
+		sort.SliceStable(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	}
+}
+
+// ByKey returns a func for Sort that sorts pairs by key with less.
+func ByKey(less func(a, b string) bool) func(a, b *pair) bool {
+	return func(a, b *pair) bool { return less(a.key, b.key) }
+}
@@ -0,0 +1,59 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	want := []string{"", "0", "00", "1", "01", "item", "item2", "item02", "item10", "item010", "item10a", "item10b", "x99y", "x100"}
+	got := slices.Clone(want)
+	slices.Reverse(got)
+	sort.Slice(got, func(i, j int) bool { return NaturalLess(got[i], got[j]) })
+	if !slices.Equal(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	for _, s := range want {
+		if NaturalLess(s, s) {
+			t.Errorf("NaturalLess(%q, %q)", s, s)
+		}
+	}
+}
+
+func TestCaseInsensitiveLess(t *testing.T) {
+	o := New()
+	for _, k := range []string{"b", "Éclair", "A", "a", "éa", "B2"} {
+		o.Set(k, nil)
+	}
+	o.SortKeys(SortFunc(CaseInsensitiveLess))
+	if want := []string{"A", "a", "b", "B2", "éa", "Éclair"}; !slices.Equal(o.Keys(), want) {
+		t.Errorf("got %q, want %q", o.Keys(), want)
+	}
+	o.Sort(ByKey(NaturalLess))
+	if want := []string{"A", "B2", "a", "b", "Éclair", "éa"}; !slices.Equal(o.Keys(), want) {
+		t.Errorf("Sort: got %q, want %q", o.Keys(), want)
+	}
+}
@@ -0,0 +1,68 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"context"
+	"slices"
+)
+
+// Load sets the pairs received from ch, in order, until ch is closed, and
+// returns nil, or until ctx is done, and returns ctx.Err().  It grows o ahead
+// of the pairs buffered in ch, so a buffered channel, with a capacity such as
+// a database cursor's batch size, saves growing o pair by pair.  Loading stops
+// at the first error of TrySet, such as of a validator.  Pairs set before an
+// error remain set.
+func (o *OrderedMap) Load(ctx context.Context, ch <-chan Pair) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case p, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if n := len(ch); n > 0 {
+				o.reserveKeys(n + 1)
+			}
+			if err := o.TrySet(p.Key, p.Value); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reserveKeys grows o for n more keys.
+func (o *OrderedMap) reserveKeys(n int) {
+	if cap(o.keys)-len(o.keys) >= n {
+		return
+	}
+	o.own()
+	o.keys = slices.Grow(o.keys, n)
+	if o.values == nil {
+		o.vals = slices.Grow(o.vals, min(n, smallSize+1-len(o.vals)))
+	}
+}
@@ -0,0 +1,74 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	ch := make(chan Pair, 64)
+	go func() {
+		for i := range 100 {
+			ch <- Pair{"k" + strconv.Itoa(i%90), i}
+		}
+		close(ch)
+	}()
+	o := New()
+	if err := o.Load(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+	if o.Len() != 90 || o.GetKeyAt(89) != "k89" || o.Get("k3") != 93 {
+		t.Errorf("Len %d, last key %q, k3 %v", o.Len(), o.GetKeyAt(o.Len()-1), o.Get("k3"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch = make(chan Pair)
+	go func() {
+		ch <- Pair{"a", 1}
+		cancel()
+	}()
+	if err := o.Load(ctx, ch); !errors.Is(err, context.Canceled) || o.Get("a") != 1 {
+		t.Errorf("got %v, a = %v", err, o.Get("a"))
+	}
+
+	errOdd := errors.New("odd")
+	v := New()
+	v.SetValidator(func(key string, value any) error {
+		if value.(int)%2 == 1 {
+			return errOdd
+		}
+		return nil
+	})
+	ch = make(chan Pair, 3)
+	ch <- Pair{"a", 2}
+	ch <- Pair{"b", 3}
+	ch <- Pair{"c", 4}
+	close(ch)
+	if err := v.Load(context.Background(), ch); err != errOdd || v.Len() != 1 {
+		t.Errorf("got %v, Len %d", err, v.Len())
+	}
+}
@@ -0,0 +1,366 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"math"
+	"os"
+	"slices"
+	"time"
+)
+
+// MappedMap is a read-only map of a file written by WriteTo, memory-mapped
+// where the platform supports it, so that a large map can be queried by key
+// without decoding it into the heap.  Only an index of the top-level keys, of
+// 12 bytes a key, is kept in memory, and the value of a key is decoded each
+// time it is accessed.  Keys are matched exactly.  A MappedMap is safe for
+// concurrent use, and must not be used after Close.
+type MappedMap struct {
+	data  []byte
+	unmap func([]byte) error
+	// tmpl holds the options of decoded values.
+	tmpl *OrderedMap
+	// offs holds the offset of each member, in order, and sorted the
+	// positions of the members sorted by key.
+	offs   []int
+	sorted []int32
+}
+
+// OpenMapped opens the map written by WriteTo to the file at path.  The file
+// is checked for syntax and duplicate keys, and values are decoded with opts,
+// such as Limits, as by ReadFrom.  The file must not be modified while open.
+func OpenMapped(path string, opts ...Option) (*MappedMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() > math.MaxInt {
+		return nil, fmt.Errorf("orderedmap: file %s too large to map", path)
+	}
+	data, err := mmapFile(f, int(fi.Size()))
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMapped(data, opts...)
+	if err != nil {
+		munmapFile(data)
+		return nil, err
+	}
+	m.unmap = munmapFile
+	return m, nil
+}
+
+// newMapped returns the MappedMap of data, indexing its keys.
+func newMapped(data []byte, opts ...Option) (*MappedMap, error) {
+	m := &MappedMap{data: data, tmpl: New(opts...)}
+	if len(data) < len(binaryMagic) || string(data[:3]) != binaryMagic[:3] {
+		return nil, fmt.Errorf("%w: missing header", ErrBinary)
+	}
+	if data[3] != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBinary, data[3])
+	}
+	i := len(binaryMagic)
+	if i == len(data) || data[i] != binObject {
+		return nil, errExpectedObject
+	}
+	i++
+	for {
+		off := i
+		n, j, err := binUvarint(data, i)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			i = j
+			break
+		}
+		if n-1 > uint64(len(data)-j) {
+			return nil, fmt.Errorf("%w: truncated at offset %d", ErrBinary, len(data))
+		}
+		if i, err = skipBinary(data, j+int(n-1)); err != nil {
+			return nil, err
+		}
+		if len(m.offs) == math.MaxInt32 {
+			return nil, fmt.Errorf("orderedmap: too many keys to map")
+		}
+		m.offs = append(m.offs, off)
+	}
+	if i != len(data) {
+		return nil, fmt.Errorf("%w: invalid data at offset %d after map", ErrBinary, i)
+	}
+	m.offs = slices.Clip(m.offs)
+	m.sorted = make([]int32, len(m.offs))
+	for i := range m.sorted {
+		m.sorted[i] = int32(i)
+	}
+	slices.SortStableFunc(m.sorted, func(a, b int32) int {
+		return bytes.Compare(m.key(int(a)), m.key(int(b)))
+	})
+	for i := 1; i < len(m.sorted); i++ {
+		if k := m.key(int(m.sorted[i])); bytes.Equal(k, m.key(int(m.sorted[i-1]))) {
+			return nil, &DuplicateError{Key: string(k), First: string(k), Path: string(k), Offset: int64(m.offs[m.sorted[i]])}
+		}
+	}
+	return m, nil
+}
+
+// Close unmaps the file of m.
+func (m *MappedMap) Close() error {
+	data := m.data
+	m.data, m.offs, m.sorted = nil, nil, nil
+	if m.unmap == nil {
+		return nil
+	}
+	return m.unmap(data)
+}
+
+// key returns the key of the member at position pos.
+func (m *MappedMap) key(pos int) []byte {
+	k, _ := m.member(pos)
+	return k
+}
+
+// member returns the key of the member at position pos and the offset of its
+// value.
+func (m *MappedMap) member(pos int) ([]byte, int) {
+	n, i, _ := binUvarint(m.data, m.offs[pos])
+	end := i + int(n-1)
+	return m.data[i:end], end
+}
+
+// index returns the position of key, or -1 if it does not exist.
+func (m *MappedMap) index(key string) int {
+	k := []byte(key)
+	i, ok := slices.BinarySearchFunc(m.sorted, k, func(pos int32, k []byte) int {
+		return bytes.Compare(m.key(int(pos)), k)
+	})
+	if !ok {
+		return -1
+	}
+	return int(m.sorted[i])
+}
+
+// value decodes the value of the member at position pos.
+func (m *MappedMap) value(pos int) (any, error) {
+	key, i := m.member(pos)
+	off := int64(i)
+	d := &binaryReader{r: bytes.NewReader(m.data[i:])}
+	root := m.tmpl.empty()
+	t := &treeBuilder{root: root, typed: true, offset: func() int64 { return off + d.tok }}
+	if root.opts != nil {
+		t.limits = root.opts.limits
+	}
+	if err := t.BeginObject(); err != nil {
+		return nil, err
+	}
+	if err := t.Key(string(key)); err != nil {
+		return nil, err
+	}
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.value(t, tag, 0); err != nil {
+		return nil, err
+	}
+	return root.valueAt(0), nil
+}
+
+// Value returns the value for key, decoded, and an error wrapping
+// ErrKeyNotFound if key does not exist or the error of decoding it.  The
+// other accessors return nil for a value that fails to decode, such as for
+// exceeding the Limits of OpenMapped.
+func (m *MappedMap) Value(key string) (any, error) {
+	pos := m.index(key)
+	if pos < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+	return m.value(pos)
+}
+
+func (m *MappedMap) Len() int {
+	return len(m.offs)
+}
+
+func (m *MappedMap) Get(key string) any {
+	v, _ := m.Lookup(key)
+	return v
+}
+
+func (m *MappedMap) Lookup(key string) (any, bool) {
+	pos := m.index(key)
+	if pos < 0 {
+		return nil, false
+	}
+	return m.GetValueAt(pos), true
+}
+
+func (m *MappedMap) GetKeyAt(pos int) string {
+	return string(m.key(pos))
+}
+
+func (m *MappedMap) GetValueAt(pos int) any {
+	v, err := m.value(pos)
+	if err != nil {
+		return nil
+	}
+	return readOnlyValue(v)
+}
+
+// Keys returns a copy of the keys in order.
+func (m *MappedMap) Keys() []string {
+	keys := make([]string, len(m.offs))
+	for i := range keys {
+		keys[i] = m.GetKeyAt(i)
+	}
+	return keys
+}
+
+func (m *MappedMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for i := range m.offs {
+			if !yield(m.GetKeyAt(i), m.GetValueAt(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Decode returns the map of m decoded into the heap, as by ReadFrom.
+func (m *MappedMap) Decode() (*OrderedMap, error) {
+	o := m.tmpl.empty()
+	if _, err := o.ReadFrom(bytes.NewReader(m.data)); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Snapshot returns Decode, or an empty map if m fails to decode.
+func (m *MappedMap) Snapshot() *OrderedMap {
+	o, err := m.Decode()
+	if err != nil {
+		return m.tmpl.empty()
+	}
+	return o
+}
+
+// ToMap returns Snapshot as plain Go maps.
+func (m *MappedMap) ToMap() map[string]any {
+	return m.Snapshot().ToMap()
+}
+
+func (m *MappedMap) MarshalJSON() ([]byte, error) {
+	o, err := m.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return o.MarshalJSON()
+}
+
+// binUvarint returns the uvarint at b[i] and the offset after it.
+func binUvarint(b []byte, i int) (uint64, int, error) {
+	n, l := binary.Uvarint(b[i:])
+	switch {
+	case l == 0:
+		return 0, 0, fmt.Errorf("%w: truncated at offset %d", ErrBinary, len(b))
+	case l < 0:
+		return 0, 0, fmt.Errorf("%w: varint overflow at offset %d", ErrBinary, i)
+	}
+	return n, i + l, nil
+}
+
+// skipBinary returns the offset after the value at b[i], checking its syntax.
+func skipBinary(b []byte, i int) (int, error) {
+	// objects holds, for each object and array being skipped, whether it is
+	// an object.
+	var objects []bool
+	for {
+		if i >= len(b) {
+			return 0, fmt.Errorf("%w: truncated at offset %d", ErrBinary, len(b))
+		}
+		off, t := i, b[i]
+		i++
+		var n uint64
+		var err error
+		switch t {
+		case binNull, binFalse, binTrue:
+		case binFloat:
+			n = 8
+		case binInt, binUint:
+			_, i, err = binUvarint(b, i)
+		case binString, binNumber, binBytes, binTime:
+			n, i, err = binUvarint(b, i)
+		case binEnd:
+			if len(objects) == 0 || objects[len(objects)-1] {
+				return 0, fmt.Errorf("%w: missing value at offset %d", ErrBinary, off)
+			}
+			objects = objects[:len(objects)-1]
+		case binObject:
+			objects = append(objects, true)
+		case binArray:
+			objects = append(objects, false)
+		default:
+			return 0, fmt.Errorf("%w: unknown type %d at offset %d", ErrBinary, t, off)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n > uint64(len(b)-i) {
+			return 0, fmt.Errorf("%w: truncated at offset %d", ErrBinary, len(b))
+		}
+		if t == binTime {
+			var tm time.Time
+			if err := tm.UnmarshalBinary(b[i : i+int(n)]); err != nil {
+				return 0, fmt.Errorf("%w: time at offset %d: %v", ErrBinary, off, err)
+			}
+		}
+		i += int(n)
+		// Skip the key of the next member of the current object, ending the
+		// objects that have no more.
+		for len(objects) > 0 && objects[len(objects)-1] {
+			if n, i, err = binUvarint(b, i); err != nil {
+				return 0, err
+			}
+			if n > 0 {
+				if n-1 > uint64(len(b)-i) {
+					return 0, fmt.Errorf("%w: truncated at offset %d", ErrBinary, len(b))
+				}
+				i += int(n - 1)
+				break
+			}
+			objects = objects[:len(objects)-1]
+		}
+		if len(objects) == 0 {
+			return i, nil
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var _ ReadOnlyMap = (*MappedMap)(nil)
+
+func writeMapped(t *testing.T, o *OrderedMap) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := o.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "map.bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpenMapped(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"z":1,"a":{"b":[1,{"c":"x"}]},"m":"s","":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	o.Set("t", when)
+	m, err := OpenMapped(writeMapped(t, o))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if m.Len() != 5 {
+		t.Errorf("got len %d, want 5", m.Len())
+	}
+	if want := []string{"z", "a", "m", "", "t"}; !slices.Equal(m.Keys(), want) {
+		t.Errorf("got keys %q, want %q", m.Keys(), want)
+	}
+	if v := m.Get("z"); v != 1.0 {
+		t.Errorf("got %v, want 1", v)
+	}
+	if v := m.Get("m"); v != "s" {
+		t.Errorf("got %v, want s", v)
+	}
+	if v, ok := m.Lookup(""); v != nil || !ok {
+		t.Errorf("got %v, %t, want nil, true", v, ok)
+	}
+	if v, ok := m.Lookup("nope"); v != nil || ok {
+		t.Errorf("got %v, %t, want nil, false", v, ok)
+	}
+	if v := m.Get("t").(time.Time); !v.Equal(when) {
+		t.Errorf("got %v, want %v", v, when)
+	}
+	a, ok := m.Get("a").(ReadOnlyMap)
+	if !ok {
+		t.Fatalf("got %T, want ReadOnlyMap", m.Get("a"))
+	}
+	if b, _ := a.MarshalJSON(); string(b) != `{"b":[1,{"c":"x"}]}` {
+		t.Errorf("got %s", b)
+	}
+	if m.GetKeyAt(1) != "a" || m.GetValueAt(2) != "s" {
+		t.Errorf("got %q, %v", m.GetKeyAt(1), m.GetValueAt(2))
+	}
+	if _, err := m.Value("nope"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("got %v, want ErrKeyNotFound", err)
+	}
+	var keys []string
+	for k := range m.All() {
+		keys = append(keys, k)
+	}
+	if !slices.Equal(keys, m.Keys()) {
+		t.Errorf("All got keys %q", keys)
+	}
+	b, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := o.MarshalJSON()
+	if !bytes.Equal(b, want) {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	if s := m.Snapshot(); !s.EqualOrdered(o) {
+		t.Errorf("got snapshot %s", s)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	empty, err := OpenMapped(writeMapped(t, New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Len() != 0 || empty.Get("a") != nil {
+		t.Errorf("got %d keys", empty.Len())
+	}
+	empty.Close()
+}
+
+func TestOpenMapped_Concurrent(t *testing.T) {
+	o := New()
+	for i := range 500 {
+		o.Set("k"+strconv.Itoa(i), float64(i))
+	}
+	m, err := OpenMapped(writeMapped(t, o))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g; i < 500; i += 4 {
+				if v := m.Get("k" + strconv.Itoa(i)); v != float64(i) {
+					t.Errorf("k%d: got %v", i, v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOpenMapped_Invalid(t *testing.T) {
+	o := New()
+	o.Set("a", []any{"x", map[string]any{"c": true}})
+	o.Set("b", 1.5)
+	var buf bytes.Buffer
+	if _, err := o.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	for i := range len(b) {
+		if _, err := newMapped(b[:i]); !errors.Is(err, ErrBinary) && !errors.Is(err, errExpectedObject) {
+			t.Errorf("%d bytes: got %v, want ErrBinary", i, err)
+		}
+	}
+	if _, err := newMapped(append(slices.Clone(b), 0)); !errors.Is(err, ErrBinary) {
+		t.Errorf("got %v, want ErrBinary for trailing data", err)
+	}
+
+	var de *DuplicateError
+	if _, err := newMapped([]byte("OMB\x01\x0b\x02a\x01\x02a\x02\x00")); !errors.As(err, &de) || de.Offset != 8 {
+		t.Errorf("got %v, want DuplicateError at 8", err)
+	}
+
+	m, err := newMapped(b, WithLimits(Limits{MaxArrayLen: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Value("a"); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got %v, want ErrLimitExceeded", err)
+	}
+	if m.Get("a") != nil || m.Get("b") != 1.5 {
+		t.Errorf("got %v, %v", m.Get("a"), m.Get("b"))
+	}
+	if _, err := m.MarshalJSON(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got %v, want ErrLimitExceeded", err)
+	}
+
+	if _, err := OpenMapped(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want ErrNotExist", err)
+	}
+}
+
+// FuzzNewMapped checks that newMapped does not panic and that accepted input
+// reads as with ReadFrom.
+func FuzzNewMapped(f *testing.F) {
+	o := New()
+	o.Set("a", []any{"x", map[string]any{"c": true}, time.Unix(0, 0).UTC()})
+	o.Set("b", -1)
+	var buf bytes.Buffer
+	if _, err := o.WriteTo(&buf); err != nil {
+		f.Fatal(err)
+	}
+	for _, s := range []string{
+		buf.String(),
+		"OMB\x01\x0b\x00",
+		"OMB\x01\x0b\x02a\x01\x02a\x02\x00",
+		"OMB\x01\x0b\xff\xff\xff\xff\xff\xff\xff\xff\x7f",
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		m, err := newMapped(b)
+		if err != nil {
+			return
+		}
+		d, err := m.Decode()
+		if err != nil {
+			t.Fatalf("ReadFrom of mapped input %q: %v", b, err)
+		}
+		if d.Len() != m.Len() {
+			t.Fatalf("got %d keys, ReadFrom %d", m.Len(), d.Len())
+		}
+		for i, k := range d.Keys() {
+			if m.GetKeyAt(i) != k {
+				t.Fatalf("key %d: got %q, ReadFrom %q", i, m.GetKeyAt(i), k)
+			}
+			if _, err := m.Value(k); err != nil {
+				t.Fatalf("value of %q: %v", k, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,78 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package maptest
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// FromBytes returns the map generated by Random from the random source b, so
+// that every input of a fuzz test is a valid map, and mutations of the input
+// mutate the map.  Once b is exhausted the source returns zeros.
+func FromBytes(b []byte, c Config) *orderedmap.OrderedMap {
+	return Random(rand.New(&byteSource{b: b}), c)
+}
+
+// byteSource is a rand.Source reading its values from b.
+type byteSource struct {
+	b []byte
+}
+
+func (s *byteSource) Int63() int64 {
+	var v [8]byte
+	n := copy(v[:], s.b)
+	s.b = s.b[n:]
+	return int64(binary.LittleEndian.Uint64(v[:]) >> 1)
+}
+
+func (s *byteSource) Seed(int64) {}
+
+// FuzzOrderedMap runs fn as the fuzz target of f with maps generated by
+// FromBytes from the fuzz input.  The corpus is seeded with n random inputs.
+func FuzzOrderedMap(f *testing.F, c Config, n int, fn func(t *testing.T, o *orderedmap.OrderedMap)) {
+	r := rand.New(rand.NewSource(1))
+	for range n {
+		b := make([]byte, 8+r.Intn(256))
+		r.Read(b)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		fn(t, FromBytes(b, c))
+	})
+}
+
+// AddCorpus adds the JSON encodings of n maps generated by Random to the
+// corpus of f, for fuzz targets taking JSON input.
+func AddCorpus(f *testing.F, r *rand.Rand, c Config, n int) {
+	for range n {
+		b, err := Random(r, c).MarshalJSON()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+}
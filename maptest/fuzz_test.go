@@ -0,0 +1,65 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package maptest
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+func TestFromBytes(t *testing.T) {
+	for _, b := range [][]byte{nil, {1}, bytes.Repeat([]byte{0xff}, 64)} {
+		a, c := FromBytes(b, Config{}), FromBytes(b, Config{})
+		RequireEqualOrdered(t, a, c)
+	}
+}
+
+func FuzzOrderedMap_roundTrip(f *testing.F) {
+	FuzzOrderedMap(f, Config{}, 8, func(t *testing.T, o *orderedmap.OrderedMap) {
+		b, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := orderedmap.New()
+		if err := got.UnmarshalJSON(b); err != nil {
+			t.Fatalf("%s: %v", b, err)
+		}
+		RequireEqualOrdered(t, o, got)
+	})
+}
+
+func FuzzAddCorpus(f *testing.F) {
+	AddCorpus(f, rand.New(rand.NewSource(1)), Config{MaxDepth: 2}, 8)
+	f.Fuzz(func(t *testing.T, b []byte) {
+		o := orderedmap.New()
+		if o.UnmarshalJSON(b) != nil {
+			return
+		}
+		if _, err := o.MarshalJSON(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package maptest provides test helpers for code using orderedmap: assertions
+// of ordered equality, golden files of ordered JSON and random maps for
+// property tests.
+package maptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+var update = flag.Bool("maptest.update", false, "update golden files of maptest.Golden")
+
+// RequireEqual fails t unless want and got are Equal, regardless of key order.
+func RequireEqual(t testing.TB, want, got *orderedmap.OrderedMap) {
+	t.Helper()
+	if !want.Equal(got) {
+		t.Fatalf("maps not equal\nwant: %s\n got: %s", marshal(want), marshal(got))
+	}
+}
+
+// RequireEqualOrdered fails t unless want and got are EqualOrdered, including
+// key order.
+func RequireEqualOrdered(t testing.TB, want, got *orderedmap.OrderedMap) {
+	t.Helper()
+	if !want.EqualOrdered(got) {
+		t.Fatalf("maps not equal in order\nwant: %s\n got: %s", marshal(want), marshal(got))
+	}
+}
+
+// Golden compares got with the JSON object in the golden file path, and fails
+// t unless they are EqualOrdered.  Formatting of the file is ignored.  With
+// the -maptest.update flag, Golden instead writes got to path, indented.
+func Golden(t testing.TB, path string, got *orderedmap.OrderedMap) {
+	t.Helper()
+	if *update {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, marshal(got), "", "\t"); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteByte('\n')
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -maptest.update to create it)", err)
+	}
+	want := orderedmap.New()
+	if err := want.UnmarshalJSON(b); err != nil {
+		t.Fatalf("golden file %s: %v", path, err)
+	}
+	if !want.EqualOrdered(got) {
+		t.Fatalf("%s: maps not equal in order\nwant: %s\n got: %s", path, marshal(want), marshal(got))
+	}
+}
+
+func marshal(o *orderedmap.OrderedMap) []byte {
+	if o == nil {
+		return []byte("<nil>")
+	}
+	b, err := o.MarshalJSON()
+	if err != nil {
+		return []byte(err.Error())
+	}
+	return b
+}
+
+// Config limits the maps generated by Random.  Zero fields use defaults.
+type Config struct {
+	// MaxKeys is the largest number of keys of each map, 8 by default.
+	MaxKeys int
+	// MaxDepth is the deepest nesting of values, 3 by default.
+	MaxDepth int
+}
+
+// Random returns a map of random keys and values, as decoded by UnmarshalJSON:
+// nil, bool, float64, string, []any and nested OrderedMap values.  Numbers and
+// strings round trip through JSON, so the map is EqualOrdered to its decoded
+// MarshalJSON output.
+func Random(r *rand.Rand, c Config) *orderedmap.OrderedMap {
+	if c.MaxKeys <= 0 {
+		c.MaxKeys = 8
+	}
+	if c.MaxDepth <= 0 {
+		c.MaxDepth = 3
+	}
+	return randomMap(r, c, c.MaxDepth)
+}
+
+func randomMap(r *rand.Rand, c Config, depth int) *orderedmap.OrderedMap {
+	o := orderedmap.New()
+	// Repeated keys are set again, so that a Source of few values terminates.
+	for range r.Intn(c.MaxKeys + 1) {
+		o.Set(randomString(r), randomValue(r, c, depth-1))
+	}
+	return o
+}
+
+func randomValue(r *rand.Rand, c Config, depth int) any {
+	kinds := 6
+	if depth <= 0 {
+		kinds = 4 // only scalars
+	}
+	switch r.Intn(kinds) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		if r.Intn(2) == 0 {
+			return float64(r.Intn(2000) - 1000)
+		}
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(r.NormFloat64()*1e3, 'g', -1, 64), 64)
+		return f
+	case 3:
+		return randomString(r)
+	case 4:
+		s := make([]any, r.Intn(c.MaxKeys+1))
+		for i := range s {
+			s[i] = randomValue(r, c, depth-1)
+		}
+		return s
+	}
+	return *randomMap(r, c, depth)
+}
+
+// runes are the runes of random strings, including those JSON escapes.
+var runes = []rune("abcxyzABC019 _-.\"\\/\n\t\u0000\u007fé€😀<>& ")
+
+func randomString(r *rand.Rand) string {
+	s := make([]rune, r.Intn(8))
+	for i := range s {
+		s[i] = runes[r.Intn(len(runes))]
+	}
+	return string(s)
+}
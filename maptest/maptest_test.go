@@ -0,0 +1,92 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package maptest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// fakeTB records the failure of a helper.
+type fakeTB struct {
+	testing.TB
+	failed string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Fatalf(format string, args ...any) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func TestRequireEqualOrdered(t *testing.T) {
+	a, b := orderedmap.New(), orderedmap.New()
+	a.Set("x", 1)
+	a.Set("y", 2)
+	b.Set("y", 2)
+	b.Set("x", 1)
+
+	f := &fakeTB{TB: t}
+	RequireEqual(f, a, b)
+	if f.failed != "" {
+		t.Errorf("RequireEqual failed: %s", f.failed)
+	}
+	RequireEqualOrdered(f, a, b)
+	if want := "maps not equal in order\nwant: {\"x\":1,\"y\":2}\n got: {\"y\":2,\"x\":1}"; f.failed != want {
+		t.Errorf("got %q, want %q", f.failed, want)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	o := orderedmap.New()
+	if err := o.UnmarshalJSON([]byte(`{"b":1,"a":{"d":[true,null],"c":"x"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	Golden(t, "testdata/golden.json", o)
+
+	o.Set("b", 2)
+	f := &fakeTB{TB: t}
+	Golden(f, "testdata/golden.json", o)
+	if f.failed == "" {
+		t.Error("Golden did not fail")
+	}
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 200 {
+		o := Random(r, Config{})
+		b, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := orderedmap.New()
+		if err := got.UnmarshalJSON(b); err != nil {
+			t.Fatalf("%s: %v", b, err)
+		}
+		RequireEqualOrdered(t, o, got)
+	}
+}
@@ -0,0 +1,281 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Marshal returns the JSON encoding of v like json.Marshal, but with the keys
+// of every map, at any depth, in a deterministic order given by the `ordered`
+// struct tag of the map's field, or sorted if it has none.  The tag lists keys
+// in order, e.g. `ordered:"alg,iat,tmb"`, and keys not listed follow, sorted.
+//
+// Structs are encoded with their fields in declaration order, following the
+// name, "-", omitempty and omitzero options of json tags.  Fields of embedded
+// structs are inlined, without encoding/json's rules for conflicting names.
+// Values implementing json.Marshaler, including OrderedMap, are encoded by
+// their method, as are values implementing encoding.TextMarshaler, and the
+// string option quotes string, number and bool fields as with encoding/json.
+func Marshal(v any) ([]byte, error) {
+	c, err := orderValue(reflect.ValueOf(v), nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(c)
+}
+
+var (
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// orderValue returns v with maps and structs converted to OrderedMaps.  order
+// is the `ordered` tag of the field holding v, if any.
+func orderValue(v reflect.Value, order []string) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if m, ok := marshaler(v); ok {
+		return m, nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return orderValue(v.Elem(), order)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return orderMap(v, order)
+	case reflect.Struct:
+		o := New()
+		if err := orderStruct(o, v); err != nil {
+			return nil, err
+		}
+		return o, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), nil // base64, like encoding/json
+		}
+		fallthrough
+	case reflect.Array:
+		s := make([]any, v.Len())
+		for i := range s {
+			var err error
+			if s[i], err = orderValue(v.Index(i), order); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	}
+	return v.Interface(), nil
+}
+
+// orderMap returns map v as an OrderedMap with keys in order, then sorted.
+func orderMap(v reflect.Value, order []string) (*OrderedMap, error) {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		k, err := mapKeyString(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+		values[k] = iter.Value()
+	}
+	sort.Strings(keys)
+	rank := make(map[string]int, len(order))
+	for i, k := range order {
+		rank[k] = i - len(order) // before unlisted keys, which rank 0
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return rank[keys[i]] < rank[keys[j]] })
+
+	o := New()
+	for _, k := range keys {
+		e, err := orderValue(values[k], nil)
+		if err != nil {
+			return nil, err
+		}
+		o.Set(k, e)
+	}
+	return o, nil
+}
+
+// mapKeyString returns the JSON object key of map key k, as encoding/json.
+func mapKeyString(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", &json.UnsupportedTypeError{Type: k.Type()}
+}
+
+// orderStruct sets the fields of struct v in o.
+func orderStruct(o *OrderedMap, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !ft.Implements(marshalerType) {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				if err := orderStruct(o, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if hasOption(opts, "omitempty") && isEmptyValue(fv) || hasOption(opts, "omitzero") && fv.IsZero() {
+			continue
+		}
+		var order []string
+		if tag, ok := f.Tag.Lookup("ordered"); ok {
+			order = strings.Split(tag, ",")
+		}
+		if hasOption(opts, "string") {
+			if q, ok, err := quoted(fv); ok || err != nil {
+				if err != nil {
+					return err
+				}
+				o.Set(name, q)
+				continue
+			}
+		}
+		e, err := orderValue(fv, order)
+		if err != nil {
+			return err
+		}
+		o.Set(name, e)
+	}
+	return nil
+}
+
+// marshaler returns v, or its address if addressable, if it encodes itself by
+// json.Marshaler or encoding.TextMarshaler, as encoding/json.
+func marshaler(v reflect.Value) (any, bool) {
+	for _, t := range []reflect.Type{marshalerType, textMarshalerType} {
+		if v.Type().Implements(t) {
+			return v.Interface(), true
+		}
+		if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(t) {
+			return v.Addr().Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// quoted returns field v with the string option, the JSON of a string,
+// number or bool, or of what a pointer points to, as a string, and whether v
+// is of such a type.  A nil pointer is nil.
+func quoted(v reflect.Value) (any, bool, error) {
+	e := v
+	if e.Kind() == reflect.Pointer && e.Type().Name() == "" {
+		if _, ok := marshaler(e); ok || !quotable(e.Type().Elem().Kind()) {
+			return nil, false, nil
+		}
+		if e.IsNil() {
+			return nil, true, nil
+		}
+		e = e.Elem()
+	}
+	if _, ok := marshaler(e); ok || !quotable(e.Kind()) {
+		return nil, false, nil
+	}
+	b, err := json.Marshal(e.Interface())
+	return string(b), true, err
+}
+
+// quotable reports whether the string option applies to values of kind k.
+func quotable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty for omitempty, as encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
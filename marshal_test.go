@@ -0,0 +1,112 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshal(t *testing.T) {
+	type Embedded struct {
+		E int `json:"e"`
+	}
+	type Payload struct {
+		Embedded
+		Head    map[string]any `json:"head" ordered:"alg,iat,tmb"`
+		Tags    map[int]string `json:"tags,omitempty"`
+		Empty   []int          `json:"empty,omitempty"`
+		Skip    string         `json:"-"`
+		Nested  []map[string]int
+		Raw     []byte `json:"raw"`
+		private int
+	}
+	p := Payload{
+		Embedded: Embedded{1},
+		Head:     map[string]any{"tmb": "x", "z": 1, "alg": "ES256", "b": map[string]int{"y": 1, "x": 2}},
+		Tags:     map[int]string{10: "a", 2: "b"},
+		Skip:     "skip",
+		Nested:   []map[string]int{{"b": 1, "a": 2}},
+		Raw:      []byte("hi"),
+	}
+	b, err := Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"e":1,"head":{"alg":"ES256","tmb":"x","b":{"x":2,"y":1},"z":1},"tags":{"10":"a","2":"b"},"Nested":[{"a":2,"b":1}],"raw":"aGk="}`
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+
+	// OrderedMap keeps its own order.
+	o := New()
+	o.Set("z", 1)
+	o.Set("a", 2)
+	if b, err = Marshal(map[string]any{"m": o}); err != nil || string(b) != `{"m":{"z":1,"a":2}}` {
+		t.Error("OrderedMap", string(b), err)
+	}
+}
+
+// upper is a TextMarshaler with a pointer receiver.
+type upper string
+
+func (u *upper) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(*u))), nil
+}
+
+func TestMarshal_EncodingJSON(t *testing.T) {
+	n := 7
+	type S struct {
+		Addr  netip.Addr
+		AddrP *netip.Addr
+		Up    upper
+		Ups   []upper
+		Str   string     `json:",string"`
+		Int   int        `json:"int,string"`
+		Float float64    `json:",string"`
+		Bool  bool       `json:",string"`
+		IntP  *int       `json:",string"`
+		NilP  *int       `json:",string"`
+		Slice []int      `json:",string"`
+		Time  time.Time  `json:",string"`
+		AddrS netip.Addr `json:",string"`
+		HTML  string     `json:",string"`
+	}
+	addr := netip.MustParseAddr("1.2.3.4")
+	v := &S{Addr: addr, AddrP: &addr, Up: "a", Ups: []upper{"b"}, Str: `x"y`, Int: 3, Float: 1.5, Bool: true,
+		IntP: &n, Slice: []int{1}, Time: time.Unix(0, 0).UTC(), AddrS: addr, HTML: "<a&b>"}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"iter"
+	"path"
+	"regexp"
+)
+
+// Match returns an iterator over the key-value pairs of o, in order, whose
+// keys match the glob pattern, with the syntax of path.Match, such as
+// "x-meta-*".  '/' is matched only by itself.  A malformed pattern matches no
+// keys; use path.Match(pattern, "") to check a pattern.  See All.
+func (o *OrderedMap) Match(pattern string) iter.Seq2[string, any] {
+	return o.matching(func(k string) bool {
+		ok, err := path.Match(pattern, k)
+		return ok && err == nil
+	})
+}
+
+// MatchRegexp returns an iterator over the key-value pairs of o, in order,
+// whose keys match re.  See All.
+func (o *OrderedMap) MatchRegexp(re *regexp.Regexp) iter.Seq2[string, any] {
+	return o.matching(re.MatchString)
+}
+
+func (o *OrderedMap) matching(match func(k string) bool) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for i, k := range o.keys {
+			if match(k) && !yield(k, o.valueAt(i)) {
+				return
+			}
+		}
+	}
+}
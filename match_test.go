@@ -0,0 +1,77 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"iter"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func matchedKeys(seq iter.Seq2[string, any]) []string {
+	var keys []string
+	for k := range seq {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestMatch(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"x-meta-b":1,"id":2,"x-meta-a":3,"x-other":4,"x-meta-/c":5}`)); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		pattern string
+		want    []string
+	}{
+		{"x-meta-*", []string{"x-meta-b", "x-meta-a"}},
+		{"x-*", []string{"x-meta-b", "x-meta-a", "x-other"}},
+		{"x-meta-[a-b]", []string{"x-meta-b", "x-meta-a"}},
+		{"??", []string{"id"}},
+		{"x-meta-/*", []string{"x-meta-/c"}},
+		{"[", nil},
+	} {
+		if got := matchedKeys(o.Match(tt.pattern)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+
+	for k, v := range o.Match("x-meta-*") {
+		if v != o.Get(k) {
+			t.Errorf("value of %s is %v", k, v)
+		}
+		break
+	}
+}
+
+func TestMatchRegexp(t *testing.T) {
+	o := New()
+	o.Set("b2", 1)
+	o.Set("a", 2)
+	o.Set("a1", 3)
+	if got := matchedKeys(o.MatchRegexp(regexp.MustCompile(`\d$`))); !slices.Equal(got, []string{"b2", "a1"}) {
+		t.Error(got)
+	}
+}
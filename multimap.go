@@ -0,0 +1,238 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// MultiPolicy is how OrderedMultiMap represents keys with multiple values in
+// JSON.
+type MultiPolicy int
+
+const (
+	// RepeatKeys repeats a key for each of its values, in arrival order, e.g.
+	// `{"a":1,"b":2,"a":3}`.
+	RepeatKeys MultiPolicy = iota
+	// ArrayValues gives each key, in order of first arrival, an array of its
+	// values, e.g. `{"a":[1,3],"b":[2]}`.
+	ArrayValues
+)
+
+// OrderedMultiMap is an ordered map in which a key may have multiple values,
+// such as HTTP headers or query parameters.  It keeps the arrival order of all
+// key-value pairs.  The zero value is an empty map using RepeatKeys.
+//
+// Lookups are by linear scan, which suits the small maps it is intended for.
+type OrderedMultiMap struct {
+	pairs []pair
+	// Policy is the JSON representation of the map.
+	Policy MultiPolicy
+}
+
+// NewMultiMap returns an empty OrderedMultiMap using policy.
+func NewMultiMap(policy MultiPolicy) *OrderedMultiMap {
+	return &OrderedMultiMap{Policy: policy}
+}
+
+// Add appends a value for key.
+func (m *OrderedMultiMap) Add(key string, value any) {
+	m.pairs = append(m.pairs, pair{key, value})
+}
+
+// Set replaces all values of key with value, keeping the position of its
+// first value, or appends key if it has no values.
+func (m *OrderedMultiMap) Set(key string, value any) {
+	for i := range m.pairs {
+		if m.pairs[i].key == key {
+			m.pairs[i].value = value
+			m.deleteAfter(key, i+1)
+			return
+		}
+	}
+	m.Add(key, value)
+}
+
+// Get returns the first value of key, or nil.
+func (m *OrderedMultiMap) Get(key string) any {
+	for _, p := range m.pairs {
+		if p.key == key {
+			return p.value
+		}
+	}
+	return nil
+}
+
+// GetAll returns the values of key in arrival order.
+func (m *OrderedMultiMap) GetAll(key string) []any {
+	var vs []any
+	for _, p := range m.pairs {
+		if p.key == key {
+			vs = append(vs, p.value)
+		}
+	}
+	return vs
+}
+
+// Delete deletes all values of key.
+func (m *OrderedMultiMap) Delete(key string) {
+	m.deleteAfter(key, 0)
+}
+
+// deleteAfter deletes the pairs of key at or after position i.
+func (m *OrderedMultiMap) deleteAfter(key string, i int) {
+	n := i
+	for _, p := range m.pairs[i:] {
+		if p.key != key {
+			m.pairs[n] = p
+			n++
+		}
+	}
+	clear(m.pairs[n:])
+	m.pairs = m.pairs[:n]
+}
+
+// Keys returns the distinct keys in order of first arrival.
+func (m *OrderedMultiMap) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, p := range m.pairs {
+		if !seen[p.key] {
+			seen[p.key] = true
+			keys = append(keys, p.key)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of key-value pairs.
+func (m *OrderedMultiMap) Len() int {
+	return len(m.pairs)
+}
+
+// All returns an iterator over all key-value pairs in arrival order.
+func (m *OrderedMultiMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, p := range m.pairs {
+			if !yield(p.key, p.value) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON encodes m according to its Policy.
+func (m OrderedMultiMap) MarshalJSON() ([]byte, error) {
+	o := OrderedMap{}
+	if m.Policy == ArrayValues {
+		for _, k := range m.Keys() {
+			o.Set(k, m.GetAll(k))
+		}
+		return o.MarshalJSON()
+	}
+	// RepeatKeys cannot be represented by an OrderedMap.
+	e := getEncodeState()
+	defer putEncodeState(e)
+	e.buf.WriteByte('{')
+	for i, p := range m.pairs {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		if err := e.enc.Encode(p.key); err != nil {
+			return nil, err
+		}
+		e.buf.Truncate(e.buf.Len() - 1) // Encode's trailing newline
+		e.buf.WriteByte(':')
+		if err := e.enc.Encode(p.value); err != nil {
+			return nil, err
+		}
+		e.buf.Truncate(e.buf.Len() - 1)
+	}
+	e.buf.WriteByte('}')
+	return bytes.Clone(e.buf.Bytes()), nil
+}
+
+// UnmarshalJSON replaces the contents of m with the JSON object b, in which
+// keys may repeat.  Nested objects are decoded as OrderedMap and must not have
+// duplicates.  With ArrayValues, each element of an array value is a value of
+// its key.
+func (m *OrderedMultiMap) UnmarshalJSON(b []byte) error {
+	err := (&dupChecker{src: b, multi: true}).check(json.NewDecoder(bytes.NewReader(b)), "")
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	// By convention, null is a no-op.
+	if token != nil {
+		if delim, ok := token.(json.Delim); !ok || delim != '{' {
+			return fmt.Errorf("orderedmap: cannot unmarshal JSON %v into OrderedMultiMap, expected object", token)
+		}
+		m.pairs = nil
+		if err = m.decode(dec); err != nil {
+			return err
+		}
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return fmt.Errorf("orderedmap: invalid data after top-level JSON object")
+	}
+	return nil
+}
+
+func (m *OrderedMultiMap) decode(dec *json.Decoder) error {
+	parent := &OrderedMap{}
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok && delim == '}' {
+			return nil
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("orderedmap: invalid JSON object key %v", token)
+		}
+		if token, err = dec.Token(); err != nil {
+			return err
+		}
+		value, err := decodeValue(dec, token, parent)
+		if err != nil {
+			return err
+		}
+		if s, ok := value.([]any); ok && m.Policy == ArrayValues {
+			for _, v := range s {
+				m.Add(key, v)
+			}
+			continue
+		}
+		m.Add(key, value)
+	}
+}
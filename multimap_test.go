@@ -0,0 +1,88 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMultiMap(t *testing.T) {
+	m := NewMultiMap(RepeatKeys)
+	m.Add("Accept", "text/html")
+	m.Add("Host", "example.com")
+	m.Add("Accept", "application/json")
+	if m.Get("Accept") != "text/html" || !reflect.DeepEqual(m.GetAll("Accept"), []any{"text/html", "application/json"}) {
+		t.Error("Get and GetAll", m.Get("Accept"), m.GetAll("Accept"))
+	}
+	if !reflect.DeepEqual(m.Keys(), []string{"Accept", "Host"}) || m.Len() != 3 {
+		t.Error("Keys and Len", m.Keys(), m.Len())
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"Accept":"text/html","Host":"example.com","Accept":"application/json"}` {
+		t.Error("RepeatKeys MarshalJSON", string(b))
+	}
+	m2 := NewMultiMap(RepeatKeys)
+	if err = m2.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m2.pairs, m.pairs) {
+		t.Error("RepeatKeys UnmarshalJSON", m2.pairs)
+	}
+
+	m.Policy = ArrayValues
+	if b, err = json.Marshal(m); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"Accept":["text/html","application/json"],"Host":["example.com"]}` {
+		t.Error("ArrayValues MarshalJSON", string(b))
+	}
+	m2 = NewMultiMap(ArrayValues)
+	if err = m2.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m2.GetAll("Accept"), []any{"text/html", "application/json"}) {
+		t.Error("ArrayValues UnmarshalJSON", m2.pairs)
+	}
+
+	m.Set("Accept", "*/*")
+	if !reflect.DeepEqual(m.pairs, []pair{{"Accept", "*/*"}, {"Host", "example.com"}}) {
+		t.Error("Set", m.pairs)
+	}
+	m.Delete("Accept")
+	if !reflect.DeepEqual(m.Keys(), []string{"Host"}) {
+		t.Error("Delete", m.Keys())
+	}
+
+	// Nested objects still reject duplicates.
+	err = m.UnmarshalJSON([]byte(`{"a":1,"a":{"b":1,"b":2}}`))
+	if !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("nested duplicate", err)
+	}
+}
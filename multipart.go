@@ -0,0 +1,136 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// MultipartFile is a file part of multipart form data.
+type MultipartFile struct {
+	Filename string
+	// ContentType is the type of the part, application/octet-stream if empty.
+	ContentType string
+	Content     []byte
+}
+
+// ToMultipart writes the members of o to w as form data parts, in order, and
+// closes w.  A MultipartFile is written as a file part, a []any or []string
+// as a part for each element with the name of its key, and other values as
+// fields formatted like ToCSVRecord.  With a boundary set by w.SetBoundary the
+// output is reproducible, such as for signing.
+func (o *OrderedMap) ToMultipart(w *multipart.Writer) error {
+	for i, k := range o.keys {
+		var err error
+		switch v := o.valueAt(i).(type) {
+		case []string:
+			for _, e := range v {
+				if err = writeMultipartValue(w, k, e); err != nil {
+					break
+				}
+			}
+		case []any:
+			for _, e := range v {
+				if err = writeMultipartValue(w, k, e); err != nil {
+					break
+				}
+			}
+		default:
+			err = writeMultipartValue(w, k, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeMultipartValue(w *multipart.Writer, name string, v any) error {
+	if f, ok := v.(MultipartFile); ok {
+		ct := f.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(name), quoteEscaper.Replace(f.Filename)))
+		h.Set("Content-Type", ct)
+		p, err := w.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		_, err = p.Write(f.Content)
+		return err
+	}
+	s, err := csvField(v)
+	if err != nil {
+		return err
+	}
+	return w.WriteField(name, s)
+}
+
+// FromMultipart reads the form data parts of r into a map in order.  Fields
+// are string values and file parts MultipartFile values.  The values of a
+// name repeated in several parts are collected, in order, into a []any at
+// the position of its first part.  Parts without a form field name are
+// rejected.  Bound the size of r, such as with http.MaxBytesReader, for
+// untrusted input.
+func FromMultipart(r *multipart.Reader) (*OrderedMap, error) {
+	o := New()
+	for {
+		p, err := r.NextPart()
+		if errors.Is(err, io.EOF) {
+			return o, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := p.FormName()
+		if name == "" {
+			return nil, fmt.Errorf("orderedmap: multipart part without a form field name")
+		}
+		b, err := io.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		var v any = string(b)
+		if p.FileName() != "" {
+			v = MultipartFile{Filename: p.FileName(), ContentType: p.Header.Get("Content-Type"), Content: b}
+		}
+		switch prev := o.Get(name).(type) {
+		case nil:
+			o.set(name, v)
+		case []any:
+			o.set(name, append(prev, v))
+		default:
+			o.set(name, []any{prev, v})
+		}
+	}
+}
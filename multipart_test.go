@@ -0,0 +1,92 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToMultipart(t *testing.T) {
+	o := New()
+	o.Set("z", "last")
+	o.Set("n", 1.5)
+	o.Set("tags", []any{"a", "b"})
+	o.Set("file", MultipartFile{Filename: `x"y.txt`, Content: []byte("hi")})
+	o.Set("obj", map[string]any{"k": true})
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.ToMultipart(w); err != nil {
+		t.Fatal(err)
+	}
+	part := func(disposition, body string) string {
+		return "--b\r\nContent-Disposition: form-data; " + disposition + "\r\n\r\n" + body + "\r\n"
+	}
+	want := part(`name="z"`, "last") +
+		part(`name="n"`, "1.5") +
+		part(`name="tags"`, "a") +
+		part(`name="tags"`, "b") +
+		"--b\r\nContent-Disposition: form-data; name=\"file\"; filename=\"x\\\"y.txt\"\r\n" +
+		"Content-Type: application/octet-stream\r\n\r\nhi\r\n" +
+		part(`name="obj"`, `{"k":true}`) +
+		"--b--\r\n"
+	if buf.String() != want {
+		t.Errorf("got  %q\nwant %q", buf.String(), want)
+	}
+
+	m, err := FromMultipart(multipart.NewReader(&buf, "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"z", "n", "tags", "file", "obj"}) {
+		t.Error("keys", got)
+	}
+	if !reflect.DeepEqual(m.Get("tags"), []any{"a", "b"}) || m.Get("n") != "1.5" {
+		t.Error("values", m.String())
+	}
+	f, ok := m.Get("file").(MultipartFile)
+	if !ok || f.Filename != `x"y.txt` || string(f.Content) != "hi" || f.ContentType != "application/octet-stream" {
+		t.Errorf("file %#v", m.Get("file"))
+	}
+}
+
+func TestFromMultipartErrors(t *testing.T) {
+	body := "--b\r\nContent-Type: text/plain\r\n\r\nx\r\n--b--\r\n"
+	if _, err := FromMultipart(multipart.NewReader(strings.NewReader(body), "b")); err == nil {
+		t.Error("no error for part without name")
+	}
+	if _, err := FromMultipart(multipart.NewReader(strings.NewReader("--b\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\nxx"), "b")); err == nil {
+		t.Error("no error for truncated input")
+	}
+	o := New()
+	o.Set("c", make(chan int))
+	if err := o.ToMultipart(multipart.NewWriter(new(bytes.Buffer))); err == nil {
+		t.Error("no error for unencodable value")
+	}
+}
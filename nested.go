@@ -0,0 +1,68 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "fmt"
+
+// GetOrCreateMap returns the nested map at key, creating an empty one with the
+// options of o, appended like Set, if key does not exist.  The map is stored
+// as an *OrderedMap, so that it can be modified in place: a stored OrderedMap
+// value is replaced by a pointer to a copy.  GetOrCreateMap panics with an
+// error wrapping ErrKeyCollision if the value of key is not a map, and with
+// the validator's error if it rejects the map.
+func (o *OrderedMap) GetOrCreateMap(key string) *OrderedMap {
+	o.expire(key)
+	v, ok := o.get(key)
+	var n *OrderedMap
+	switch m := v.(type) {
+	case *OrderedMap:
+		if m != nil {
+			return m
+		}
+	case OrderedMap:
+		// m shares storage with the stored value, so copy it on write.
+		m.shared = true
+		n = &m
+	}
+	if ok && n == nil {
+		panic(fmt.Errorf("%w: %q is not a map", ErrKeyCollision, key))
+	}
+	if n == nil {
+		n = o.empty()
+	}
+	if err := o.TrySet(key, n); err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// EnsurePath returns the nested map at the path of keys, creating the maps
+// that do not exist with GetOrCreateMap, or o if there are no keys.  Unlike
+// SetPath, keys may contain ".".
+func (o *OrderedMap) EnsurePath(keys ...string) *OrderedMap {
+	m := o
+	for _, k := range keys {
+		m = m.GetOrCreateMap(k)
+	}
+	return m
+}
@@ -0,0 +1,75 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetOrCreateMap(t *testing.T) {
+	o := New()
+	o.GetOrCreateMap("b").Set("x", 1)
+	o.GetOrCreateMap("a").Set("y", 2)
+	o.GetOrCreateMap("b").Set("z", 3)
+	if got, want := o.String(), `{"b":{"x":1,"z":3},"a":{"y":2}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// Decoded maps are OrderedMap values, replaced by copies.
+	if err := o.UnmarshalJSON([]byte(`{"a":{"b":1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	s := o.Snapshot()
+	o.GetOrCreateMap("a").Set("c", 2)
+	if got, want := o.String(), `{"a":{"b":1,"c":2}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := s.String(), `{"a":{"b":1}}`; got != want {
+		t.Errorf("snapshot %s, want %s", got, want)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrKeyCollision) {
+			t.Error("recovered", err)
+		}
+	}()
+	o.Set("n", 1)
+	o.GetOrCreateMap("n")
+	t.Error("no panic for value that is not a map")
+}
+
+func TestEnsurePath(t *testing.T) {
+	o := New(CaseInsensitive())
+	o.EnsurePath("a", "b.c", "d").Set("e", 1)
+	o.EnsurePath("A", "x").Set("f", 2)
+	if got, want := o.String(), `{"a":{"b.c":{"d":{"e":1}},"x":{"f":2}}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if o.EnsurePath() != o {
+		t.Error("empty path is not o")
+	}
+	if o.EnsurePath("a", "x").Get("f") != 2 {
+		t.Error("path not found")
+	}
+}
@@ -0,0 +1,315 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of JSON value of a Node.
+type Kind int
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+var kindNames = [...]string{"null", "bool", "number", "string", "array", "object"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
+// Node is a JSON value of a document model.  Objects are backed by an
+// OrderedMap of *Node values, so they keep their key order, and arrays by a
+// slice of *Node.  Nodes are modified in place and MarshalJSON encodes the
+// document in order.  The zero value is a null Node.
+type Node struct {
+	kind Kind
+	// v is the bool, float64 or string of a scalar.
+	v   any
+	obj *OrderedMap
+	arr []*Node
+}
+
+// ParseNode decodes the JSON value b, of any kind, into a Node.  Duplicate
+// keys are rejected like UnmarshalJSON.
+func ParseNode(b []byte) (*Node, error) {
+	c := &dupChecker{src: b}
+	if err := c.check(json.NewDecoder(bytes.NewReader(b)), ""); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	v, err := decodeValue(dec, token, &OrderedMap{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("orderedmap: invalid data after top-level JSON value")
+	}
+	return NewNode(v)
+}
+
+// NewNode returns a Node of a copy of v, which is typically a value decoded by
+// UnmarshalJSON.  Nested OrderedMaps, *OrderedMaps, []any and OrderedArrays
+// are converted to Nodes, and a *Node is returned as is.  Other values are
+// converted through their JSON encoding.
+func NewNode(v any) (*Node, error) {
+	switch t := v.(type) {
+	case *Node:
+		return t, nil
+	case nil:
+		return &Node{}, nil
+	case bool:
+		return &Node{kind: KindBool, v: t}, nil
+	case float64:
+		return &Node{kind: KindNumber, v: t}, nil
+	case string:
+		return &Node{kind: KindString, v: t}, nil
+	}
+	if m, ok := asMap(v); ok {
+		n := &Node{kind: KindObject, obj: m.empty()}
+		for i, k := range m.keys {
+			c, err := NewNode(m.valueAt(i))
+			if err != nil {
+				return nil, err
+			}
+			n.obj.set(k, c)
+		}
+		return n, nil
+	}
+	if s, ok := asSlice(v); ok {
+		n := &Node{kind: KindArray, arr: make([]*Node, len(s))}
+		for i, e := range s {
+			c, err := NewNode(e)
+			if err != nil {
+				return nil, err
+			}
+			n.arr[i] = c
+		}
+		return n, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNode(b)
+}
+
+// Kind returns the kind of n.
+func (n *Node) Kind() Kind {
+	return n.kind
+}
+
+// Len returns the number of members of an object or elements of an array, and
+// 0 for other kinds.
+func (n *Node) Len() int {
+	switch n.kind {
+	case KindObject:
+		return n.obj.Len()
+	case KindArray:
+		return len(n.arr)
+	}
+	return 0
+}
+
+// Keys returns the keys of an object in order, and nil for other kinds.
+func (n *Node) Keys() []string {
+	if n.kind != KindObject {
+		return nil
+	}
+	return n.obj.KeysCopy()
+}
+
+// Get returns the member key of an object, or nil if n is not an object or key
+// is not set.
+func (n *Node) Get(key string) *Node {
+	if n.kind != KindObject {
+		return nil
+	}
+	c, _ := n.obj.Get(key).(*Node)
+	return c
+}
+
+// Index returns the element i of an array, or nil if n is not an array or i
+// is out of range.
+func (n *Node) Index(i int) *Node {
+	if n.kind != KindArray || i < 0 || i >= len(n.arr) {
+		return nil
+	}
+	return n.arr[i]
+}
+
+// Path returns the Node at path, in the form of DuplicateError.Path, such as
+// "payload.sigs[1].alg", or nil if there is none.  An empty path returns n.
+func (n *Node) Path(path string) *Node {
+	for path != "" && n != nil {
+		if path[0] == '[' {
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil
+			}
+			i, err := strconv.Atoi(path[1:end])
+			if err != nil {
+				return nil
+			}
+			n, path = n.Index(i), path[end+1:]
+			if strings.HasPrefix(path, ".") {
+				path = path[1:]
+			}
+			continue
+		}
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			end = len(path)
+		}
+		n, path = n.Get(path[:end]), path[end:]
+		if strings.HasPrefix(path, ".") {
+			path = path[1:]
+		}
+	}
+	return n
+}
+
+// Bool returns the value of a bool.
+func (n *Node) Bool() (bool, bool) {
+	b, ok := n.v.(bool)
+	return b, ok
+}
+
+// Float64 returns the value of a number.
+func (n *Node) Float64() (float64, bool) {
+	f, ok := n.v.(float64)
+	return f, ok
+}
+
+// StringValue returns the value of a string.
+func (n *Node) StringValue() (string, bool) {
+	s, ok := n.v.(string)
+	return s, ok
+}
+
+// Value returns a copy of n as decoded by UnmarshalJSON, with objects as
+// OrderedMap and arrays as []any.
+func (n *Node) Value() any {
+	switch n.kind {
+	case KindObject:
+		m := n.obj.empty()
+		for i, k := range n.obj.keys {
+			m.set(k, n.obj.valueAt(i).(*Node).Value())
+		}
+		return *m
+	case KindArray:
+		s := make([]any, len(n.arr))
+		for i, c := range n.arr {
+			s[i] = c.Value()
+		}
+		return s
+	}
+	return n.v
+}
+
+// Set sets the member key of an object to v, converted by NewNode.  New keys
+// are appended to the end of the order.
+func (n *Node) Set(key string, v any) error {
+	if n.kind != KindObject {
+		return fmt.Errorf("orderedmap: Set of %s Node", n.kind)
+	}
+	c, err := NewNode(v)
+	if err != nil {
+		return err
+	}
+	return n.obj.TrySet(key, c)
+}
+
+// Delete deletes the member key of an object.  It does nothing for other
+// kinds.
+func (n *Node) Delete(key string) {
+	if n.kind == KindObject {
+		n.obj.Delete(key)
+	}
+}
+
+// SetIndex sets element i of an array to v, converted by NewNode.  It panics
+// if i is out of range.
+func (n *Node) SetIndex(i int, v any) error {
+	if n.kind != KindArray {
+		return fmt.Errorf("orderedmap: SetIndex of %s Node", n.kind)
+	}
+	c, err := NewNode(v)
+	if err != nil {
+		return err
+	}
+	n.arr[i] = c
+	return nil
+}
+
+// Append appends v, converted by NewNode, to an array.
+func (n *Node) Append(v any) error {
+	if n.kind != KindArray {
+		return fmt.Errorf("orderedmap: Append to %s Node", n.kind)
+	}
+	c, err := NewNode(v)
+	if err != nil {
+		return err
+	}
+	n.arr = append(n.arr, c)
+	return nil
+}
+
+// MarshalJSON encodes n, with object members in order.
+func (n *Node) MarshalJSON() ([]byte, error) {
+	switch n.kind {
+	case KindObject:
+		return n.obj.MarshalJSON()
+	case KindArray:
+		return encodeCompact(n.arr)
+	}
+	return encodeCompact(n.v)
+}
+
+// UnmarshalJSON replaces n with the JSON value b, like ParseNode.
+func (n *Node) UnmarshalJSON(b []byte) error {
+	p, err := ParseNode(b)
+	if err != nil {
+		return err
+	}
+	*n = *p
+	return nil
+}
@@ -0,0 +1,107 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNode(t *testing.T) {
+	in := `{"payload":{"alg":"ES256","n":1.5,"ok":true},"sigs":[{"alg":"<a>"},null],"z":null}`
+	n, err := ParseNode([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Kind() != KindObject || n.Len() != 3 {
+		t.Fatalf("got %v of %d", n.Kind(), n.Len())
+	}
+	if s, ok := n.Path("payload.alg").StringValue(); !ok || s != "ES256" {
+		t.Errorf("payload.alg = %q, %v", s, ok)
+	}
+	if f, ok := n.Get("payload").Get("n").Float64(); !ok || f != 1.5 {
+		t.Errorf("payload.n = %v, %v", f, ok)
+	}
+	if b, ok := n.Path("payload.ok").Bool(); !ok || !b {
+		t.Errorf("payload.ok = %v, %v", b, ok)
+	}
+	if s, _ := n.Path("sigs[0].alg").StringValue(); s != "<a>" {
+		t.Errorf("sigs[0].alg = %q", s)
+	}
+	if k := n.Path("sigs[1]").Kind(); k != KindNull {
+		t.Errorf("sigs[1] kind %v", k)
+	}
+	for _, p := range []string{"missing", "sigs[2]", "sigs.a", "payload[0]", "sigs[x]", "sigs[0"} {
+		if n.Path(p) != nil {
+			t.Errorf("Path(%q) not nil", p)
+		}
+	}
+	if n.Path("") != n {
+		t.Error("empty path is not n")
+	}
+
+	b, err := n.MarshalJSON()
+	if err != nil || string(b) != in {
+		t.Errorf("got %s, %v, want %s", b, err, in)
+	}
+
+	if err := n.Get("payload").Set("alg", "EdDSA"); err != nil {
+		t.Fatal(err)
+	}
+	n.Set("new", []any{1, map[string]int{"a": 1}})
+	n.Delete("z")
+	sigs := n.Get("sigs")
+	sigs.Append("x")
+	sigs.SetIndex(1, New())
+	if err := n.Get("payload").Get("alg").Set("a", 1); err == nil {
+		t.Error("Set on string Node")
+	}
+	if err := n.Append(1); err == nil {
+		t.Error("Append to object Node")
+	}
+	want := `{"payload":{"alg":"EdDSA","n":1.5,"ok":true},"sigs":[{"alg":"<a>"},{},"x"],"new":[1,{"a":1}]}`
+	if b, _ := n.MarshalJSON(); string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	o := New()
+	if err := o.UnmarshalJSON([]byte(want)); err != nil {
+		t.Fatal(err)
+	}
+	v := n.Value().(OrderedMap)
+	if !o.EqualOrdered(&v) {
+		t.Errorf("Value %v not equal to decoded %v", v, o)
+	}
+
+	if _, err := ParseNode([]byte(`{"a":1,"a":2}`)); !errors.Is(err, ErrJSONDuplicate) {
+		t.Errorf("got %v, want ErrJSONDuplicate", err)
+	}
+	var s Node
+	if err := json.Unmarshal([]byte(` "s" `), &s); err != nil || s.Kind() != KindString {
+		t.Errorf("got %v, %v", s.Kind(), err)
+	}
+	if _, err := ParseNode([]byte(`1 2`)); err == nil {
+		t.Error("no error for data after value")
+	}
+}
@@ -0,0 +1,47 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// NormalizeKeys makes keys the same key if f maps them to the same string, so
+// that, with norm.NFC.String from golang.org/x/text/unicode/norm, keys that
+// are Unicode equivalent cannot be told apart, or with strings.TrimSpace or
+// strings.ToLower, keys differing by spaces or case.  f applies to Set, lookups
+// and UnmarshalJSON, which rejects keys that normalize alike as duplicates.
+// If original is true a key keeps the form it was first set with, which is
+// used for output, like CaseInsensitive; otherwise keys are stored and output
+// normalized.  f must be idempotent, and may be combined with
+// CaseInsensitive.
+func NormalizeKeys(f func(key string) string, original bool) Option {
+	return func(opts *options) {
+		opts.normalize = f
+		opts.original = original
+	}
+}
+
+// storedKey returns key as stored by Set.
+func (o *OrderedMap) storedKey(key string) string {
+	if o.opts == nil || o.opts.normalize == nil || o.opts.original {
+		return key
+	}
+	return o.opts.normalize(key)
+}
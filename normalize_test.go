@@ -0,0 +1,85 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// nfc composes "e" and a combining acute accent, as norm.NFC does.
+func nfc(s string) string {
+	return strings.ReplaceAll(s, "e\u0301", "\u00e9")
+}
+
+const (
+	composed   = "caf\u00e9"
+	decomposed = "cafe\u0301"
+)
+
+func TestNormalizeKeys(t *testing.T) {
+	for _, original := range []bool{false, true} {
+		for _, n := range []int{2, smallSize + 2} {
+			o := New(NormalizeKeys(nfc, original))
+			o.Set(decomposed, 1)
+			for i := range n - 1 {
+				o.Set(strings.Repeat("k", i+1), i)
+			}
+			o.Set(composed, 2)
+			if o.Len() != n || o.Get(composed) != 2 || o.Get(decomposed) != 2 {
+				t.Errorf("original %v, %d keys: Len %d, value %v", original, n, o.Len(), o.Get(composed))
+			}
+			want := composed
+			if original {
+				want = decomposed
+			}
+			if o.Keys()[0] != want {
+				t.Errorf("original %v: key %q, want %q", original, o.Keys()[0], want)
+			}
+			o.Delete(composed)
+			if o.Get(decomposed) != nil {
+				t.Error("Delete of equivalent key")
+			}
+		}
+	}
+
+	o := New(NormalizeKeys(strings.TrimSpace, false), CaseInsensitive())
+	if err := o.UnmarshalJSON([]byte(`{" Alg ":"ES256","tmb":"x"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"Alg":"ES256","tmb":"x"}` || o.Get("alg") != "ES256" {
+		t.Errorf("got %s", b)
+	}
+	e := o.Entry(" x ")
+	e.Set(1)
+	if o.Keys()[2] != "x" {
+		t.Errorf("Entry key %q", o.Keys()[2])
+	}
+
+	var de *DuplicateError
+	m := New(NormalizeKeys(nfc, true))
+	if err := m.UnmarshalJSON([]byte(`{"` + decomposed + `":1,"` + composed + `":2}`)); !errors.As(err, &de) || de.Key != composed {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// KeyOffsets makes UnmarshalJSON record the byte offset in its input of each
+// key, at any depth, for KeyOffset.  Use LineColumn to convert an offset for
+// messages about hand-written input.
+func KeyOffsets() Option {
+	return func(opts *options) { opts.offsets = true }
+}
+
+// KeyOffset returns the byte offset of the opening quote of key in the input
+// of the last UnmarshalJSON of o, and false if key is not set or was not
+// decoded with KeyOffsets.  The offsets of decoded nested maps are looked up
+// on the nested maps.  A key that was deleted and set again keeps its decoded
+// offset.
+func (o *OrderedMap) KeyOffset(key string) (int64, bool) {
+	off, ok := o.offsets[o.mapKey(key)]
+	if !ok || o.index(key) < 0 {
+		return 0, false
+	}
+	return off, true
+}
+
+// LineColumn returns the 1-based line and column, in bytes, of offset in b.
+func LineColumn(b []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset && i < int64(len(b)); i++ {
+		if b[i] == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// recordOffset records the offset of key, decoded after offset prev, if o
+// records offsets.  prev is fixed up by shiftOffsets once decoding is done.
+func (o *OrderedMap) recordOffset(key string, prev int64) {
+	if o.opts == nil || !o.opts.offsets {
+		return
+	}
+	if o.offsets == nil {
+		o.offsets = make(map[string]int64)
+	}
+	o.offsets[o.mapKey(key)] = prev
+}
+
+// shiftOffsets replaces the recorded offsets of o and its nested maps with
+// their result of f.
+func (o *OrderedMap) shiftOffsets(f func(off int64) int64) {
+	if o.opts == nil || !o.opts.offsets {
+		return
+	}
+	for k, off := range o.offsets {
+		o.offsets[k] = f(off)
+	}
+	for i := range o.keys {
+		shiftValueOffsets(o.rawAt(i), f)
+	}
+}
+
+func shiftValueOffsets(v any, f func(off int64) int64) {
+	if m, ok := asMap(v); ok {
+		m.shiftOffsets(f)
+	} else if s, ok := asSlice(v); ok {
+		for _, e := range s {
+			shiftValueOffsets(e, f)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOrderedMap_KeyOffset(t *testing.T) {
+	in := []byte("{\n  \"a\": 1,\n  \"b\": {\"c\": [{\"d\": true}]}\n}")
+	o := New(KeyOffsets())
+	if err := o.UnmarshalJSON(in); err != nil {
+		t.Fatal(err)
+	}
+	b := o.Get("b").(OrderedMap)
+	d := b.Get("c").([]any)[0].(OrderedMap)
+	for _, tc := range []struct {
+		m         *OrderedMap
+		key       string
+		line, col int
+	}{
+		{o, "a", 2, 3},
+		{o, "b", 3, 3},
+		{&b, "c", 3, 9},
+		{&d, "d", 3, 16},
+	} {
+		off, ok := tc.m.KeyOffset(tc.key)
+		if !ok {
+			t.Fatalf("%q: no offset", tc.key)
+		}
+		if !bytes.HasPrefix(in[off:], []byte(`"`+tc.key+`"`)) {
+			t.Errorf("%q: offset %d at %q", tc.key, off, in[off:])
+		}
+		if line, col := LineColumn(in, off); line != tc.line || col != tc.col {
+			t.Errorf("%q: got %d:%d, want %d:%d", tc.key, line, col, tc.line, tc.col)
+		}
+	}
+	o.Delete("a")
+	if _, ok := o.KeyOffset("a"); ok {
+		t.Error("offset of deleted key")
+	}
+	if _, ok := New().KeyOffset("a"); ok {
+		t.Error("offset without KeyOffsets")
+	}
+
+	lenient := []byte("{// c\n  a: 1, 'b': 2,}")
+	o = New(KeyOffsets(), Lenient())
+	if err := o.UnmarshalJSON(lenient); err != nil {
+		t.Fatal(err)
+	}
+	if off, _ := o.KeyOffset("a"); lenient[off] != 'a' {
+		t.Errorf("lenient a at %q", lenient[off:])
+	}
+	if off, _ := o.KeyOffset("b"); lenient[off] != '\'' {
+		t.Errorf("lenient b at %q", lenient[off:])
+	}
+
+	stream := []byte("{\"a\":1}\n{\"b\":2}")
+	maps, err := UnmarshalStream(stream, KeyOffsets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if off, _ := maps[1].KeyOffset("b"); off != 9 {
+		t.Errorf("stream offset %d, want 9", off)
+	}
+}
@@ -0,0 +1,166 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omhttp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// HeaderOrder returns the canonical names of the header fields of the raw
+// HTTP/1 request or response head in raw, in the order they first appear.  It
+// reads from after the start line to the first empty line, and skips
+// malformed lines, so that raw may be a prefix of the message.
+func HeaderOrder(raw []byte) []string {
+	var order []string
+	seen := make(map[string]bool)
+	s := bufio.NewScanner(bytes.NewReader(raw))
+	s.Buffer(nil, len(raw)+1)
+	for first := true; s.Scan(); first = false {
+		line := strings.TrimRight(s.Text(), "\r")
+		if line == "" {
+			break
+		}
+		name, _, ok := strings.Cut(line, ":")
+		if first || !ok || name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		if !seen[name] {
+			seen[name] = true
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
+// FromHTTPHeader returns h, such as a request's Header or Trailer, as a map of
+// canonical names to []string values.  Names are in the order of order, such
+// as from HeaderOrder, and names not in order follow, sorted.  Names in order
+// that are not in h are skipped.  Values of names of h that are not canonical
+// are appended to those of the canonical name.
+func FromHTTPHeader(h http.Header, order []string) *orderedmap.OrderedMap {
+	o := orderedmap.New()
+	for _, k := range order {
+		k = textproto.CanonicalMIMEHeaderKey(k)
+		if v, ok := h[k]; ok && o.Get(k) == nil {
+			o.Set(k, slices.Clone(v))
+		}
+	}
+	rest := make([]string, 0, len(h))
+	for k := range h {
+		if k != textproto.CanonicalMIMEHeaderKey(k) || o.Get(k) == nil {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+	for _, k := range rest {
+		c := textproto.CanonicalMIMEHeaderKey(k)
+		v, _ := o.Get(c).([]string)
+		o.Set(c, append(v, h[k]...))
+	}
+	return o
+}
+
+// ToHTTPHeader returns the header fields of o, which are string, []string or,
+// as decoded from JSON, []any of strings values.  Names must be HTTP tokens
+// and are canonicalized.  An http.Header is unordered, so use the keys of o,
+// or WriteHTTPHeader, where the order matters.
+func ToHTTPHeader(o *orderedmap.OrderedMap) (http.Header, error) {
+	h := make(http.Header, o.Len())
+	for k, v := range o.All() {
+		vs, err := headerValues(k, v)
+		if err != nil {
+			return nil, err
+		}
+		c := textproto.CanonicalMIMEHeaderKey(k)
+		h[c] = append(h[c], vs...)
+	}
+	return h, nil
+}
+
+// WriteHTTPHeader writes the header fields of o, as for ToHTTPHeader, in the
+// wire format, in the order of o, one line per value.
+func WriteHTTPHeader(w io.Writer, o *orderedmap.OrderedMap) error {
+	for k, v := range o.All() {
+		vs, err := headerValues(k, v)
+		if err != nil {
+			return err
+		}
+		k = textproto.CanonicalMIMEHeaderKey(k)
+		for _, s := range vs {
+			if strings.ContainsAny(s, "\r\n") {
+				return fmt.Errorf("omhttp: header %s value %q contains a line break", k, s)
+			}
+			if _, err := fmt.Fprintf(w, "%s: %s\r\n", k, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func headerValues(k string, v any) ([]string, error) {
+	if !validName(k) {
+		return nil, fmt.Errorf("omhttp: invalid header name %q", k)
+	}
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		vs := make([]string, len(v))
+		for i, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("omhttp: header %s value [%d] is %T, not string", k, i, e)
+			}
+			vs[i] = s
+		}
+		return vs, nil
+	}
+	return nil, fmt.Errorf("omhttp: header %s value is %T, not string or []string", k, v)
+}
+
+// validName reports whether k is a header field name, an RFC 9110 token.
+func validName(k string) bool {
+	if k == "" {
+		return false
+	}
+	for i := range len(k) {
+		c := k[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0) {
+			return false
+		}
+	}
+	return true
+}
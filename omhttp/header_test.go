@@ -0,0 +1,116 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omhttp
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+const rawRequest = "GET / HTTP/1.1\r\n" +
+	"Host: example.com\r\n" +
+	"x-signature: abc\r\n" +
+	"Accept: */*\r\n" +
+	"X-Signature: def\r\n" +
+	"Content-Type: application/json\r\n" +
+	"\r\n" +
+	"Body: not a header\r\n"
+
+func TestHeaderOrder(t *testing.T) {
+	want := []string{"Host", "X-Signature", "Accept", "Content-Type"}
+	if got := HeaderOrder([]byte(rawRequest)); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFromHTTPHeader(t *testing.T) {
+	r, err := http.ReadRequest(bufio.NewReader(strings.NewReader(rawRequest)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Z-Added", "1")
+	r.Header.Set("B-Added", "2")
+	o := FromHTTPHeader(r.Header, HeaderOrder([]byte(rawRequest)))
+	// Host is moved from the header to r.Host by ReadRequest.
+	want := []string{"X-Signature", "Accept", "Content-Type", "B-Added", "Z-Added"}
+	if got := o.Keys(); !slices.Equal(got, want) {
+		t.Errorf("keys %v, want %v", got, want)
+	}
+	if got := o.Get("X-Signature"); !slices.Equal(got.([]string), []string{"abc", "def"}) {
+		t.Error("X-Signature", got)
+	}
+	h := http.Header{"X-Foo": {"1"}, "x-foo": {"2"}, "b-bar": {"3"}}
+	o = FromHTTPHeader(h, []string{"X-Foo"})
+	if b, _ := o.MarshalJSON(); string(b) != `{"X-Foo":["1","2"],"B-Bar":["3"]}` {
+		t.Errorf("non-canonical names: %s", b)
+	}
+}
+
+func TestToHTTPHeader(t *testing.T) {
+	o := orderedmap.New()
+	if err := o.UnmarshalJSON([]byte(`{"x-b":["1","2"],"A":"3"}`)); err != nil {
+		t.Fatal(err)
+	}
+	h, err := ToHTTPHeader(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(h["X-B"], []string{"1", "2"}) || h.Get("A") != "3" {
+		t.Error(h)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTTPHeader(&buf, o); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "X-B: 1\r\nX-B: 2\r\nA: 3\r\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	o.Set("C", 1)
+	if _, err := ToHTTPHeader(o); err == nil {
+		t.Error("no error for number value")
+	}
+	o.Delete("C")
+	o.Set("D", "a\r\nE: b")
+	if err := WriteHTTPHeader(&buf, o); err == nil {
+		t.Error("no error for line break in value")
+	}
+
+	for _, k := range []string{"X-A\r\nSet-Cookie", "X A", "", "X:A", "X\x00"} {
+		o := orderedmap.New()
+		o.Set(k, "v")
+		if err := WriteHTTPHeader(&buf, o); err == nil {
+			t.Errorf("no error for name %q", k)
+		}
+		if _, err := ToHTTPHeader(o); err == nil {
+			t.Errorf("no error for name %q", k)
+		}
+	}
+}
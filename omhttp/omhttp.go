@@ -0,0 +1,134 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omhttp decodes HTTP request bodies to OrderedMaps and writes
+// OrderedMaps as responses, with the same limits and duplicate rejection in
+// every handler.
+package omhttp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// DefaultMaxBytes is the body size limit of DecodeRequest.
+const DefaultMaxBytes = 1 << 20
+
+// Error is an error decoding a request, with the HTTP status to respond with.
+type Error struct {
+	// Status is http.StatusRequestEntityTooLarge,
+	// http.StatusUnsupportedMediaType or http.StatusBadRequest.
+	Status int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("omhttp: %s: %v", http.StatusText(e.Status), e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Decoder decodes request bodies.  The zero value limits bodies to
+// DefaultMaxBytes.
+type Decoder struct {
+	// MaxBytes limits the body size, DefaultMaxBytes if 0.
+	MaxBytes int64
+	// Options configure the decoded maps, such as WithLimits for the depth
+	// and number of keys.
+	Options []orderedmap.Option
+}
+
+// DecodeRequest decodes the body of r with the zero Decoder.
+func DecodeRequest(r *http.Request) (*orderedmap.OrderedMap, error) {
+	return new(Decoder).DecodeRequest(r)
+}
+
+// DecodeRequest decodes the JSON object body of r, rejecting duplicate keys
+// and bodies that are not an object, including null.  A Content-Type, if
+// given, must be application/json or a +json type, in UTF-8.  Errors are
+// *Error, with the status to respond with.
+func (d *Decoder) DecodeRequest(r *http.Request) (*orderedmap.OrderedMap, error) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, params, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, &Error{http.StatusUnsupportedMediaType, err}
+		}
+		if mt != "application/json" && !strings.HasSuffix(mt, "+json") {
+			return nil, &Error{http.StatusUnsupportedMediaType, fmt.Errorf("media type %s is not JSON", mt)}
+		}
+		if cs, ok := params["charset"]; ok && !strings.EqualFold(cs, "utf-8") {
+			return nil, &Error{http.StatusUnsupportedMediaType, fmt.Errorf("charset %s is not UTF-8", cs)}
+		}
+	}
+	max := d.MaxBytes
+	if max <= 0 {
+		max = DefaultMaxBytes
+	}
+	if r.ContentLength > max {
+		return nil, &Error{http.StatusRequestEntityTooLarge, fmt.Errorf("body of %d bytes exceeds %d", r.ContentLength, max)}
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, max+1))
+	if err != nil {
+		return nil, &Error{http.StatusBadRequest, err}
+	}
+	if int64(len(b)) > max {
+		return nil, &Error{http.StatusRequestEntityTooLarge, fmt.Errorf("body exceeds %d bytes", max)}
+	}
+	switch t := bytes.TrimSpace(b); {
+	case len(t) == 0:
+		return nil, &Error{http.StatusBadRequest, errors.New("empty body")}
+	case string(t) == "null":
+		// UnmarshalJSON accepts null as an empty map.
+		return nil, &Error{http.StatusBadRequest, errors.New("body is null, expected object")}
+	}
+	o := orderedmap.New(d.Options...)
+	if err := o.UnmarshalJSON(b); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, orderedmap.ErrLimitExceeded) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		return nil, &Error{status, err}
+	}
+	return o, nil
+}
+
+// WriteResponse writes o as a JSON response with status code.  If o cannot be
+// encoded, nothing is written and the error is returned.
+func WriteResponse(w http.ResponseWriter, code int, o *orderedmap.OrderedMap) error {
+	b, err := o.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, err = w.Write(b)
+	return err
+}
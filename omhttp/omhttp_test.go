@@ -0,0 +1,130 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+func request(contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func TestDecodeRequest(t *testing.T) {
+	for _, ct := range []string{"", "application/json", "application/json; charset=utf-8", "application/problem+json"} {
+		o, err := DecodeRequest(request(ct, `{"b":1,"a":2}`))
+		if err != nil {
+			t.Fatal(ct, err)
+		}
+		if got := strings.Join(o.Keys(), ","); got != "b,a" {
+			t.Errorf("%q: keys %s", ct, got)
+		}
+	}
+}
+
+func TestDecodeRequestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, ct, body string
+		status         int
+	}{
+		{"text", "text/plain", `{}`, http.StatusUnsupportedMediaType},
+		{"charset", "application/json; charset=latin1", `{}`, http.StatusUnsupportedMediaType},
+		{"malformed type", "application/", `{}`, http.StatusUnsupportedMediaType},
+		{"empty", "application/json", " ", http.StatusBadRequest},
+		{"invalid", "application/json", `{"a":`, http.StatusBadRequest},
+		{"duplicate", "application/json", `{"a":1,"a":2}`, http.StatusBadRequest},
+		{"array", "application/json", `[1]`, http.StatusBadRequest},
+		{"null", "application/json", " null ", http.StatusBadRequest},
+		{"string", "application/json", `"a"`, http.StatusBadRequest},
+		{"large", "application/json", `{"a":"` + strings.Repeat("x", DefaultMaxBytes) + `"}`, http.StatusRequestEntityTooLarge},
+	} {
+		_, err := DecodeRequest(request(tt.ct, tt.body))
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("%s: error %v is not *Error", tt.name, err)
+		}
+		if e.Status != tt.status {
+			t.Errorf("%s: status %d, want %d: %v", tt.name, e.Status, tt.status, err)
+		}
+	}
+
+	_, err := DecodeRequest(request("", `{"a":1,"a":2}`))
+	if !errors.Is(err, orderedmap.ErrJSONDuplicate) {
+		t.Error("duplicate error is not ErrJSONDuplicate:", err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	d := Decoder{MaxBytes: 8}
+	r := request("", `{"a":123}`)
+	r.ContentLength = -1 // Unknown, so the body must be read to find the size.
+	_, err := d.DecodeRequest(r)
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusRequestEntityTooLarge {
+		t.Error("body over MaxBytes:", err)
+	}
+
+	d = Decoder{Options: []orderedmap.Option{orderedmap.WithLimits(orderedmap.Limits{MaxDepth: 1})}}
+	_, err = d.DecodeRequest(request("", `{"a":{"b":{}}}`))
+	if !errors.As(err, &e) || e.Status != http.StatusRequestEntityTooLarge || !errors.Is(err, orderedmap.ErrLimitExceeded) {
+		t.Error("depth over limit:", err)
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	o := orderedmap.New()
+	o.Set("z", 1)
+	o.Set("a", "<b>")
+	w := httptest.NewRecorder()
+	if err := WriteResponse(w, http.StatusCreated, o); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusCreated {
+		t.Error("code", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Error("Content-Type", ct)
+	}
+	if got := w.Body.String(); got != `{"z":1,"a":"<b>"}` {
+		t.Error("body", got)
+	}
+
+	o.Set("c", make(chan int))
+	w = httptest.NewRecorder()
+	if err := WriteResponse(w, http.StatusOK, o); err == nil {
+		t.Error("no error for unencodable value")
+	}
+	if w.Body.Len() != 0 || len(w.Header()) != 0 {
+		t.Error("wrote a response for unencodable map")
+	}
+}
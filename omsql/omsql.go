@@ -0,0 +1,199 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package omsql builds parameterized SQL filters on JSON columns from the
+// structure of an OrderedMap, for PostgreSQL jsonb and SQLite JSON.
+package omsql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// Dialect is the SQL dialect of generated clauses.
+type Dialect int
+
+const (
+	// Postgres generates clauses for PostgreSQL jsonb columns, with $n
+	// placeholders.
+	Postgres Dialect = iota
+	// SQLite generates clauses for SQLite 3.38 or later JSON columns, with ?
+	// placeholders.
+	SQLite
+)
+
+// Clause is a condition of a WHERE clause and its argument.
+type Clause struct {
+	// SQL is the condition, such as data->'a'->>'b' = $1.
+	SQL string
+	// Arg is the argument for the placeholder of SQL, or nil if SQL has none.
+	Arg any
+	// HasArg reports whether SQL has a placeholder.
+	HasArg bool
+}
+
+// ErrColumn is returned for a column name that is not an identifier.
+var ErrColumn = errors.New("omsql: column is not an identifier")
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// WhereClauses returns a condition for each value of o other than a map, at
+// any depth, in key order, that the value at its path in the JSON column is
+// equal to it.  Keys are escaped SQL string literals, so that an index on the
+// same expression can be used, and values are arguments.  column, such as
+// "data" or "t.data", is checked to be an identifier.  Placeholders are
+// numbered from first for Postgres.
+//
+// Strings are compared as text.  With Postgres, other values are compared as
+// jsonb, with their JSON as the argument.  With SQLite, numbers and bools are
+// compared as numbers, arrays as minified JSON and nil matches SQL NULL,
+// which includes missing keys.
+func WhereClauses(column string, o *orderedmap.OrderedMap, d Dialect, first int) ([]Clause, error) {
+	if !identifier.MatchString(column) {
+		return nil, fmt.Errorf("%w: %q", ErrColumn, column)
+	}
+	w := &whereBuilder{dialect: d, n: first}
+	if err := w.add(column, o); err != nil {
+		return nil, err
+	}
+	return w.clauses, nil
+}
+
+// Where is WhereClauses joined with AND, with placeholders numbered from 1,
+// and its arguments.  For a map without values it returns "TRUE".
+func Where(column string, o *orderedmap.OrderedMap, d Dialect) (string, []any, error) {
+	clauses, err := WhereClauses(column, o, d, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(clauses) == 0 {
+		return "TRUE", nil, nil
+	}
+	conds := make([]string, len(clauses))
+	var args []any
+	for i, c := range clauses {
+		conds[i] = c.SQL
+		if c.HasArg {
+			args = append(args, c.Arg)
+		}
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+type whereBuilder struct {
+	dialect Dialect
+	clauses []Clause
+	// n is the number of the next Postgres placeholder.
+	n int
+}
+
+// add adds the clauses of the values of o at the expression path.
+func (w *whereBuilder) add(path string, o *orderedmap.OrderedMap) error {
+	for k, v := range o.All() {
+		p := path + "->" + quote(k)
+		switch v := v.(type) {
+		case orderedmap.OrderedMap:
+			if err := w.add(p, &v); err != nil {
+				return err
+			}
+			continue
+		case *orderedmap.OrderedMap:
+			if v != nil {
+				if err := w.add(p, v); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := w.leaf(path, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaf adds the clause of value v of key k at path.
+func (w *whereBuilder) leaf(path, k string, v any) error {
+	if s, ok := v.(string); ok {
+		w.clause(path+"->>"+quote(k)+" = ", "", s)
+		return nil
+	}
+	if w.dialect == SQLite {
+		switch v.(type) {
+		case nil:
+			w.clauses = append(w.clauses, Clause{SQL: path + "->>" + quote(k) + " IS NULL"})
+			return nil
+		case bool, float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+			w.clause(path+"->>"+quote(k)+" = ", "", sqliteNumber(v))
+			return nil
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("omsql: value of %q: %w", k, err)
+	}
+	if w.dialect == SQLite {
+		w.clause(path+"->"+quote(k)+" = json(", ")", string(b))
+		return nil
+	}
+	w.clause(path+"->"+quote(k)+" = ", "::jsonb", string(b))
+	return nil
+}
+
+// clause adds the clause of prefix, the placeholder for arg and suffix.
+func (w *whereBuilder) clause(prefix, suffix string, arg any) {
+	ph := "?"
+	if w.dialect == Postgres {
+		ph = "$" + strconv.Itoa(w.n)
+		w.n++
+	}
+	w.clauses = append(w.clauses, Clause{SQL: prefix + ph + suffix, Arg: arg, HasArg: true})
+}
+
+// sqliteNumber returns v as SQLite returns the JSON of v from ->>.
+func sqliteNumber(v any) any {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
+
+// quote returns s as an SQL string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,112 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package omsql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+const filter = `{"b":"x","a":{"it's":1,"ok":true,"c":{"d":null}},"tags":["t1"]}`
+
+func TestWhere(t *testing.T) {
+	o := orderedmap.New()
+	if err := o.UnmarshalJSON([]byte(filter)); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		dialect Dialect
+		sql     string
+		args    []any
+	}{
+		{Postgres, `data->>'b' = $1 AND data->'a'->'it''s' = $2::jsonb AND data->'a'->'ok' = $3::jsonb AND ` +
+			`data->'a'->'c'->'d' = $4::jsonb AND data->'tags' = $5::jsonb`,
+			[]any{"x", "1", "true", "null", `["t1"]`}},
+		{SQLite, `data->>'b' = ? AND data->'a'->>'it''s' = ? AND data->'a'->>'ok' = ? AND ` +
+			`data->'a'->'c'->>'d' IS NULL AND data->'tags' = json(?)`,
+			[]any{"x", float64(1), 1, `["t1"]`}},
+	} {
+		sql, args, err := Where("data", o, tt.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != tt.sql {
+			t.Errorf("%d:\ngot  %s\nwant %s", tt.dialect, sql, tt.sql)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%d: args %#v, want %#v", tt.dialect, args, tt.args)
+		}
+	}
+}
+
+func TestWhere_Percent(t *testing.T) {
+	o := orderedmap.New()
+	if err := o.UnmarshalJSON([]byte(`{"discount%":"10%","a%s'b":{"%d":[1]},"n%%":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{Postgres, `data->>'discount%' = $1 AND data->'a%s''b'->'%d' = $2::jsonb AND data->'n%%' = $3::jsonb`},
+		{SQLite, `data->>'discount%' = ? AND data->'a%s''b'->'%d' = json(?) AND data->>'n%%' = ?`},
+	} {
+		sql, _, err := Where("data", o, tt.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != tt.sql {
+			t.Errorf("%d:\ngot  %s\nwant %s", tt.dialect, sql, tt.sql)
+		}
+	}
+}
+
+func TestWhereClauses(t *testing.T) {
+	o := orderedmap.New()
+	o.Set("a", "1")
+	o.Set("b", "2")
+	clauses, err := WhereClauses("t.data", o, Postgres, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Clause{{`t.data->>'a' = $3`, "1", true}, {`t.data->>'b' = $4`, "2", true}}
+	if !reflect.DeepEqual(clauses, want) {
+		t.Errorf("got %v, want %v", clauses, want)
+	}
+
+	if sql, args, err := Where("data", orderedmap.New(), SQLite); sql != "TRUE" || args != nil || err != nil {
+		t.Error("empty map", sql, args, err)
+	}
+	for _, col := range []string{"", "data; DROP TABLE t", `"data"`, "a.b.c"} {
+		if _, err := WhereClauses(col, o, Postgres, 1); !errors.Is(err, ErrColumn) {
+			t.Errorf("%q: %v", col, err)
+		}
+	}
+	o.Set("c", make(chan int))
+	if _, _, err := Where("data", o, Postgres); err == nil {
+		t.Error("no error for unencodable value")
+	}
+}
@@ -0,0 +1,88 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "strconv"
+
+// Op is the kind of change reported to an OnChange func.
+type Op int
+
+const (
+	// OpInsert is the Set of a new key.  old is nil.
+	OpInsert Op = iota
+	// OpSet is the Set of an existing key.
+	OpSet
+	// OpDelete is the Delete of an existing key.  new is nil.
+	OpDelete
+	// OpSort is a reordering by Sort or SortKeys.  key, old and new are zero.
+	OpSort
+	// OpUnmarshal is the replacement of all entries by UnmarshalJSON, which
+	// does not report the keys individually.  key, old and new are zero.
+	OpUnmarshal
+	// OpMove is a Move of key.  old and new are its former and new positions.
+	OpMove
+	// OpBatch is a Batch, recorded by History as a single change.  It is not
+	// reported to OnChange, which is given the changes of the Batch instead.
+	OpBatch
+)
+
+var opNames = [...]string{"insert", "set", "delete", "sort", "unmarshal", "move", "batch"}
+
+func (op Op) String() string {
+	if op < 0 || int(op) >= len(opNames) {
+		return "Op(" + strconv.Itoa(int(op)) + ")"
+	}
+	return opNames[op]
+}
+
+// OnChange sets f to be called after each change to o, replacing any previous
+// func.  A nil f removes it.  f is not called for changes to nested maps or
+// for values that are rejected by the validator.  It may modify o, which
+// calls f again.
+func (o *OrderedMap) OnChange(f func(op Op, key string, old, new any)) {
+	o.onChange = f
+}
+
+// observed reports whether changes to o are reported to OnChange or History.
+func (o *OrderedMap) observed() bool {
+	return o.onChange != nil || o.history != nil
+}
+
+// notify reports c to the OnChange func and History.
+func (o *OrderedMap) notify(c Change) {
+	if o.history != nil {
+		o.history.record(c)
+	}
+	if o.onChange != nil {
+		o.onChange(c.Op, c.Key, c.Old, c.New)
+	}
+}
+
+// notifySet reports the Set of key from old, if exists, to value.
+func (o *OrderedMap) notifySet(key string, old any, exists bool, value any) {
+	if exists {
+		o.notify(Change{Op: OpSet, Key: key, Old: old, New: value})
+	} else {
+		o.notify(Change{Op: OpInsert, Key: key, New: value})
+	}
+}
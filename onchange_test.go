@@ -0,0 +1,65 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOrderedMap_OnChange(t *testing.T) {
+	var got []string
+	o := New()
+	o.OnChange(func(op Op, key string, old, new any) {
+		got = append(got, fmt.Sprint(op, " ", key, " ", old, " ", new))
+	})
+	o.Set("b", 1)
+	o.Set("b", 2)
+	o.Set("a", 3)
+	o.Delete("missing")
+	o.Delete("a")
+	o.Entry("c").Set(4)
+	o.Entry("c").Delete()
+	o.SortKeys(sort.Strings)
+	if err := o.UnmarshalJSON([]byte(`{"x":{"y":1},"z":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	x := o.Get("x").(OrderedMap)
+	x.Set("y", 2) // nested maps do not report
+
+	want := []string{
+		"insert b <nil> 1",
+		"set b 1 2",
+		"insert a <nil> 3",
+		"delete a 3 <nil>",
+		"insert c <nil> 4",
+		"delete c 4 <nil>",
+		"sort  <nil> <nil>",
+		"unmarshal  <nil> <nil>",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,345 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package openapi reads and writes OpenAPI and Swagger JSON documents as
+// OrderedMaps, keeping the order of paths, operations, parameters and
+// properties that generated documentation follows.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+// ErrNotOpenAPI is returned for a document without an "openapi" or "swagger"
+// version.
+var ErrNotOpenAPI = errors.New("openapi: not an OpenAPI or Swagger document")
+
+// methods are the operation keys of a path item, in the order of the
+// specification.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is an OpenAPI 3 or Swagger 2 document.  Maps returned by its
+// methods are part of Root, so that modifying them modifies the document:
+// nested maps are stored as *OrderedMap, with TrySet, as they are returned.
+// If that fails, as Root is frozen or its validator rejects the pointer, a
+// frozen copy is returned instead.
+type Document struct {
+	Root *orderedmap.OrderedMap
+}
+
+// Parse decodes the JSON document b, rejecting duplicate keys.  opts
+// configure the maps, such as WithLimits for untrusted input.
+func Parse(b []byte, opts ...orderedmap.Option) (*Document, error) {
+	root := orderedmap.New(opts...)
+	if err := root.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	d := &Document{Root: root}
+	if d.Version() == "" {
+		return nil, ErrNotOpenAPI
+	}
+	return d, nil
+}
+
+// Read is Parse of the document read from r.
+func Read(r io.Reader, opts ...orderedmap.Option) (*Document, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b, opts...)
+}
+
+// Version returns the "openapi" version, or for Swagger 2 the "swagger"
+// version, or "" if there is none.
+func (d *Document) Version() string {
+	if v, ok := d.Root.Get("openapi").(string); ok {
+		return v
+	}
+	v, _ := d.Root.Get("swagger").(string)
+	return v
+}
+
+// Paths returns the paths of the document in order.
+func (d *Document) Paths() []string {
+	return keys(d.Root, "paths")
+}
+
+// PathItem returns the path item of path, or nil if there is none.
+func (d *Document) PathItem(path string) *orderedmap.OrderedMap {
+	return child(child(d.Root, "paths"), path)
+}
+
+// Operation is an operation of a path item.
+type Operation struct {
+	Path string
+	// Method is the lowercase key of the operation, such as "get".
+	Method string
+	Op     *orderedmap.OrderedMap
+}
+
+// Operations returns an iterator over the operations of the document, in the
+// order of the paths and of the methods within each path item.
+func (d *Document) Operations() iter.Seq[Operation] {
+	return func(yield func(Operation) bool) {
+		for _, path := range d.Paths() {
+			item := d.PathItem(path)
+			if item == nil {
+				continue
+			}
+			for _, k := range item.KeysCopy() {
+				if !slices.Contains(methods, k) {
+					continue
+				}
+				if op := child(item, k); op != nil && !yield(Operation{path, k, op}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Parameters returns the parameters of the operation in order.
+func (op Operation) Parameters() []*orderedmap.OrderedMap {
+	return maps(op.Op, "parameters")
+}
+
+// Schemas returns the schemas of components, or for Swagger 2 the
+// definitions, or nil if there are none.
+func (d *Document) Schemas() *orderedmap.OrderedMap {
+	if s := child(child(d.Root, "components"), "schemas"); s != nil {
+		return s
+	}
+	return child(d.Root, "definitions")
+}
+
+// Schema returns the named schema of Schemas, or nil.
+func (d *Document) Schema(name string) *orderedmap.OrderedMap {
+	return child(d.Schemas(), name)
+}
+
+// Properties returns the property names of schema in order.
+func Properties(schema *orderedmap.OrderedMap) []string {
+	return keys(schema, "properties")
+}
+
+// ResolveRefs replaces $ref objects with the documents they refer to, keeping
+// their position, as orderedmap's ResolveRefs.  Local references, such as
+// "#/components/schemas/Pet", are resolved in the document and others with
+// external, which may be nil to reject them.
+func (d *Document) ResolveRefs(external func(ref string) (*orderedmap.OrderedMap, error)) error {
+	return d.Root.ResolveRefs(func(ref string) (*orderedmap.OrderedMap, error) {
+		if strings.HasPrefix(ref, "#") {
+			return d.Pointer(strings.TrimPrefix(ref, "#"))
+		}
+		if external == nil {
+			return nil, fmt.Errorf("openapi: external reference %q", ref)
+		}
+		return external(ref)
+	})
+}
+
+// Pointer returns the map at the JSON pointer (RFC 6901) p in the document,
+// such as "/components/schemas/Pet".
+func (d *Document) Pointer(p string) (*orderedmap.OrderedMap, error) {
+	if p == "" {
+		return d.Root, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("openapi: invalid JSON pointer %q", p)
+	}
+	var v any = d.Root
+	for _, tok := range strings.Split(p[1:], "/") {
+		tok = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+		switch c := v.(type) {
+		case *orderedmap.OrderedMap:
+			e := c.Get(tok)
+			if e == nil {
+				return nil, fmt.Errorf("openapi: %q not found", p)
+			}
+			if m := child(c, tok); m != nil {
+				v = m
+			} else if a, ok := elements(c, tok); ok {
+				v = a
+			} else {
+				v = e
+			}
+		case elems:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, fmt.Errorf("openapi: %q not found", p)
+			}
+			v = c[i]
+			if m, ok := readOnly(c[i]); ok {
+				// An array in an array is not stored converted.
+				v = m
+			}
+		default:
+			return nil, fmt.Errorf("openapi: %q not found", p)
+		}
+	}
+	m, ok := v.(*orderedmap.OrderedMap)
+	if !ok {
+		return nil, fmt.Errorf("openapi: %q is not an object", p)
+	}
+	return m, nil
+}
+
+// MarshalJSON encodes the document in order.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	return d.Root.MarshalJSON()
+}
+
+// Write writes the document to w in order, indented by two spaces.
+func (d *Document) Write(w io.Writer) error {
+	b, err := d.Root.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// keys returns a copy of the keys of the map at key in m, or nil if m is nil or
+// the value is not a map.  m is not modified.
+func keys(m *orderedmap.OrderedMap, key string) []string {
+	if m == nil {
+		return nil
+	}
+	switch c := m.Get(key).(type) {
+	case orderedmap.OrderedMap:
+		return c.KeysCopy()
+	case *orderedmap.OrderedMap:
+		if c != nil {
+			return c.KeysCopy()
+		}
+	}
+	return nil
+}
+
+// child returns the map at key in m, or nil if m is nil or the value is not a
+// map.  It is stored as an *OrderedMap, so that it is part of m, or if m does
+// not accept it, returned as a frozen copy.
+func child(m *orderedmap.OrderedMap, key string) *orderedmap.OrderedMap {
+	if m == nil {
+		return nil
+	}
+	switch v := m.Get(key).(type) {
+	case *orderedmap.OrderedMap:
+		return v
+	case orderedmap.OrderedMap:
+		n := v.Snapshot() // v shares storage with the stored value
+		if err := m.TrySet(key, n); err != nil {
+			n.Freeze()
+		}
+		return n
+	}
+	return nil
+}
+
+// elems is an array of which the map elements are *OrderedMap values.
+type elems []any
+
+// elements returns the array at key in m, with map elements stored as
+// *OrderedMap.  A converted copy of the array, of the same type, is set with
+// TrySet, as the array may be shared with snapshots; if m does not accept it,
+// the map elements are frozen copies.
+func elements(m *orderedmap.OrderedMap, key string) (elems, bool) {
+	if m == nil {
+		return nil, false
+	}
+	v := m.Get(key)
+	var a []any
+	switch s := v.(type) {
+	case []any:
+		a = s
+	case orderedmap.OrderedArray:
+		a = s
+	default:
+		return nil, false
+	}
+	var c []any
+	var converted []*orderedmap.OrderedMap
+	for i, e := range a {
+		if e, ok := e.(orderedmap.OrderedMap); ok {
+			if c == nil {
+				c = slices.Clone(a)
+			}
+			n := e.Snapshot()
+			c[i] = n
+			converted = append(converted, n)
+		}
+	}
+	if c == nil {
+		return a, true
+	}
+	var stored any = c
+	if _, ok := v.(orderedmap.OrderedArray); ok {
+		stored = orderedmap.OrderedArray(c)
+	}
+	if err := m.TrySet(key, stored); err != nil {
+		for _, n := range converted {
+			n.Freeze()
+		}
+	}
+	return c, true
+}
+
+// readOnly returns the map v as a frozen copy.
+func readOnly(v any) (*orderedmap.OrderedMap, bool) {
+	switch m := v.(type) {
+	case *orderedmap.OrderedMap:
+		return m, m != nil
+	case orderedmap.OrderedMap:
+		n := m.Snapshot()
+		n.Freeze()
+		return n, true
+	}
+	return nil, false
+}
+
+// maps returns the maps of the array at key in m, stored as *OrderedMap.
+func maps(m *orderedmap.OrderedMap, key string) []*orderedmap.OrderedMap {
+	a, _ := elements(m, key)
+	var ms []*orderedmap.OrderedMap
+	for _, e := range a {
+		if n, ok := e.(*orderedmap.OrderedMap); ok && n != nil {
+			ms = append(ms, n)
+		}
+	}
+	return ms
+}
@@ -0,0 +1,201 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+const petstore = `{
+  "openapi": "3.1.0",
+  "info": {"title": "Petstore", "version": "1"},
+  "paths": {
+    "/pets": {
+      "post": {"operationId": "createPet", "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}},
+      "get": {"operationId": "listPets", "parameters": [{"name": "limit", "in": "query"}, {"name": "after", "in": "query"}]},
+      "summary": "Pets"
+    },
+    "/pets/{id}": {
+      "get": {"operationId": "getPet", "parameters": [{"$ref": "#/components/parameters/id~1path"}]}
+    }
+  },
+  "components": {
+    "parameters": {"id/path": {"name": "id", "in": "path"}},
+    "schemas": {"Pet": {"type": "object", "properties": {"name": {"type": "string"}, "id": {"type": "integer"}, "age": {"type": "integer"}}}}
+  }
+}`
+
+func TestDocument(t *testing.T) {
+	d, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Version() != "3.1.0" {
+		t.Error("version", d.Version())
+	}
+	if got := d.Paths(); !slices.Equal(got, []string{"/pets", "/pets/{id}"}) {
+		t.Error("paths", got)
+	}
+	var ops []string
+	for op := range d.Operations() {
+		ops = append(ops, op.Method+" "+op.Path+" "+op.Op.Get("operationId").(string))
+	}
+	if want := []string{"post /pets createPet", "get /pets listPets", "get /pets/{id} getPet"}; !slices.Equal(ops, want) {
+		t.Error("operations", ops)
+	}
+	for op := range d.Operations() {
+		if op.Op.Get("operationId") != "listPets" {
+			continue
+		}
+		var names []string
+		for _, p := range op.Parameters() {
+			names = append(names, p.Get("name").(string))
+		}
+		if !slices.Equal(names, []string{"limit", "after"}) {
+			t.Error("parameters", names)
+		}
+		// Returned maps are part of the document.
+		op.Parameters()[1].Set("required", true)
+	}
+	if got := Properties(d.Schema("Pet")); !slices.Equal(got, []string{"name", "id", "age"}) {
+		t.Error("properties", got)
+	}
+	if !strings.Contains(d.Root.String(), `{"name":"after","in":"query","required":true}`) {
+		t.Error("parameter not modified in document")
+	}
+
+	if _, err := Parse([]byte(`{"info":{}}`)); !errors.Is(err, ErrNotOpenAPI) {
+		t.Error("not OpenAPI:", err)
+	}
+	if _, err := Parse([]byte(`{"openapi":"3.0.0","paths":{},"paths":{}}`)); !errors.Is(err, orderedmap.ErrJSONDuplicate) {
+		t.Error("duplicate:", err)
+	}
+	sw, err := Parse([]byte(`{"swagger":"2.0","definitions":{"A":{"properties":{"z":{},"a":{}}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sw.Version() != "2.0" || !slices.Equal(Properties(sw.Schema("A")), []string{"z", "a"}) {
+		t.Error("swagger", sw.Version(), Properties(sw.Schema("A")))
+	}
+}
+
+func TestDocumentResolveRefs(t *testing.T) {
+	d, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ResolveRefs(nil); err != nil {
+		t.Fatal(err)
+	}
+	get := d.PathItem("/pets/{id}").GetOrCreateMap("get")
+	params := Operation{Op: get}.Parameters()
+	if len(params) != 1 || params[0].String() != `{"name":"id","in":"path"}` {
+		t.Error("parameter", params)
+	}
+	s := child(child(child(child(child(d.PathItem("/pets"), "post"), "requestBody"), "content"), "application/json"), "schema")
+	if got := Properties(s); !slices.Equal(got, []string{"name", "id", "age"}) {
+		t.Error("resolved schema", got)
+	}
+
+	d, err = Parse([]byte(`{"openapi":"3.0.0","a":{"$ref":"other.json"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ResolveRefs(nil); err == nil {
+		t.Error("no error for external reference")
+	}
+	if _, err := d.Pointer("/a/$ref"); err == nil {
+		t.Error("no error for pointer to a string")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	d, err := Parse([]byte(`{"openapi":"3.0.0","paths":{"/b":{},"/a":{}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"openapi\": \"3.0.0\",\n  \"paths\": {\n    \"/b\": {},\n    \"/a\": {}\n  }\n}\n"
+	if buf.String() != want {
+		t.Errorf("got %q", buf.String())
+	}
+	b, err := d.MarshalJSON()
+	if err != nil || string(b) != `{"openapi":"3.0.0","paths":{"/b":{},"/a":{}}}` {
+		t.Error(string(b), err)
+	}
+}
+
+func TestDocumentFrozenAndSnapshot(t *testing.T) {
+	d, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := d.Root.Snapshot()
+	want := snap.String()
+	for op := range d.Operations() {
+		for _, p := range op.Parameters() {
+			p.Set("x", 1)
+		}
+	}
+	if snap.String() != want {
+		t.Error("snapshot modified through Parameters")
+	}
+	if !strings.Contains(d.Root.String(), `"x":1`) {
+		t.Error("document not modified through Parameters")
+	}
+
+	for _, opts := range [][]orderedmap.Option{nil, {orderedmap.OrderedArrays()}} {
+		d, err := Parse([]byte(petstore), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Root.Freeze()
+		if len(d.Paths()) != 2 || d.Schemas() == nil {
+			t.Error("frozen getters", d.Paths())
+		}
+		n := 0
+		for op := range d.Operations() {
+			for _, p := range op.Parameters() {
+				if err := p.TrySet("x", 1); !errors.Is(err, orderedmap.ErrFrozen) {
+					t.Error("parameter of frozen document:", err)
+				}
+				n++
+			}
+		}
+		if n != 3 {
+			t.Error("parameters", n)
+		}
+		if m, err := d.Pointer("/paths/~1pets/get/parameters/0"); err != nil || m.Get("name") != "limit" {
+			t.Error("frozen pointer", m, err)
+		}
+	}
+}
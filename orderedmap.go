@@ -26,8 +26,15 @@ package orderedmap
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 type pair struct {
@@ -52,61 +59,506 @@ func (a byPair) Len() int           { return len(a.Pairs) }
 func (a byPair) Swap(i, j int)      { a.Pairs[i], a.Pairs[j] = a.Pairs[j], a.Pairs[i] }
 func (a byPair) Less(i, j int) bool { return a.LessFunc(a.Pairs[i], a.Pairs[j]) }
 
+// smallSize is the largest number of entries an OrderedMap holds without
+// allocating a backing Go map.  Small maps keep values in a slice parallel to
+// keys and look keys up by linear scan, which is cheaper than hashing for a
+// handful of entries and avoids the map allocation entirely.  Up to 16 entries,
+// the saved allocation outweighs slower lookups in decoding and building maps;
+// see the benchmarks.
+const smallSize = 16
+
 type OrderedMap struct {
-	keys   []string
+	keys []string
+	// vals holds values, parallel to keys, while the map is small (values is
+	// nil).
+	vals []any
+	// values is allocated once the map grows beyond smallSize.
 	values map[string]any
+	// opts is shared with nested maps decoded into o.  It is never modified
+	// once shared; setters replace it with a modified copy.
+	opts *options
+	// expires holds the expiry of keys set by SetWithTTL, keyed like values.
+	expires map[string]time.Time
+	// offsets holds the input offsets of keys recorded by UnmarshalJSON with
+	// KeyOffsets, keyed like values.  It is replaced, never modified, so it
+	// may be shared with snapshots.
+	offsets map[string]int64
+	// raws holds the input of values decoded with KeepRaw, keyed like values.
+	raws map[string]span
+	// layout holds the formatting of the input decoded with Faithful.  Like
+	// offsets it is never modified once decoded.
+	layout *layout
+	// onChange is set by OnChange.  Unlike opts it is not shared with nested
+	// maps.
+	onChange func(op Op, key string, old, new any)
+	// history is set by EnableHistory.
+	history *history
+	// shared is set when the storage is shared with a Snapshot, and must be
+	// copied before modification.
+	shared bool
+	// frozen is set by Freeze.
+	frozen bool
+}
+
+// options holds optional configuration of an OrderedMap.
+type options struct {
+	validator func(key string, value any) error
+	// fold enables case-insensitive keys.  values is keyed by foldKey.
+	fold bool
+	// normalize, if not nil, normalizes keys, and values is keyed by its
+	// result.  See NormalizeKeys.
+	normalize func(key string) string
+	// original keeps the first form of normalized keys.
+	original bool
+	limits   Limits
+	// types holds the factories set by RegisterType, keyed like values.
+	types map[string]func() any
+	// lazy enables lazy decoding.  See Lazy.
+	lazy bool
+	// lenient enables JSONC and JSON5 input.  See Lenient.
+	lenient bool
+	// arrays decodes arrays as OrderedArray.  See OrderedArrays.
+	arrays bool
+	// offsets records key offsets.  See KeyOffsets.
+	offsets bool
+	// raw keeps the input of values.  See KeepRaw.
+	raw bool
+	// faithful keeps the formatting of the input.  See Faithful.
+	faithful bool
+	// intern interns decoded keys.  See InternKeys.
+	intern bool
+	// alloc allocates decoded slices.  See WithAllocator.
+	alloc Allocator
+	// parallel is the minimum number of keys of a map encoded in parallel.
+	// See ParallelMarshal.
+	parallel int
+	// zeroCopy decodes strings referencing the input.  See ZeroCopy.
+	zeroCopy bool
+	// cache caches the encoding of members.  See CacheEncoding.
+	cache bool
+	// format formats encoded numbers and times.  See WithEncodeFormat.
+	format *EncodeFormat
+	// backend parses input in place of encoding/json.  See WithBackend.
+	backend Backend
+	// shuffle randomizes the order of iteration.  See ShuffleIteration.
+	shuffle *shuffler
+	// metrics counts operations.  See WithMetrics.
+	metrics *Metrics
+	// redact replaces values in MarshalJSON.  See Redact.
+	redact *redaction
+	// ijson rejects input that is not I-JSON.  See IJSON.
+	ijson bool
+}
+
+// Option configures an OrderedMap created by New.
+type Option func(*options)
+
+// CaseInsensitive makes key lookup case-insensitive, using Unicode simple case
+// folding like strings.EqualFold, similar to http.Header.  A key keeps the
+// casing it was first set with, which is used for output.  UnmarshalJSON
+// rejects keys that differ only by case as duplicates.
+func CaseInsensitive() Option {
+	return func(opts *options) { opts.fold = true }
+}
+
+// clone returns a copy of opts, or new options if opts is nil.
+func (opts *options) clone() *options {
+	if opts == nil {
+		return &options{}
+	}
+	c := *opts
+	return &c
+}
+
+// WithLimits sets the limits of UnmarshalJSON.
+func WithLimits(l Limits) Option {
+	return func(opts *options) { opts.limits = l }
+}
+
+// New returns an empty OrderedMap configured by opts.  The zero value is also
+// an empty, ready to use OrderedMap.
+func New(opts ...Option) *OrderedMap {
+	o := &OrderedMap{}
+	if len(opts) > 0 {
+		o.opts = &options{}
+		for _, opt := range opts {
+			opt(o.opts)
+		}
+	}
+	return o
+}
+
+// folded reports whether o has case-insensitive keys.
+func (o *OrderedMap) folded() bool {
+	return o.opts != nil && o.opts.fold
+}
+
+// mapsKeys reports whether keys are stored in values under another key, by
+// CaseInsensitive or NormalizeKeys.
+func (o *OrderedMap) mapsKeys() bool {
+	return o.opts != nil && (o.opts.fold || o.opts.normalize != nil)
+}
+
+// mapKey returns the key under which the value for key is stored in values.
+func (o *OrderedMap) mapKey(key string) string {
+	if o.opts == nil {
+		return key
+	}
+	if o.opts.normalize != nil {
+		key = o.opts.normalize(key)
+	}
+	if o.opts.fold {
+		key = foldKey(key)
+	}
+	return key
+}
+
+// foldKey maps each rune of s to the smallest rune of its Unicode simple case
+// folding orbit, so that foldKey(a) == foldKey(b) iff strings.EqualFold(a, b).
+func foldKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, s)
+}
+
+// index returns the position of key in keys, or -1.
+func (o *OrderedMap) index(key string) int {
+	if o.opts != nil && o.opts.normalize != nil {
+		mk := o.mapKey(key)
+		for i, k := range o.keys {
+			if k == key || o.mapKey(k) == mk {
+				return i
+			}
+		}
+		return -1
+	}
+	fold := o.folded()
+	for i, k := range o.keys {
+		if k == key || fold && strings.EqualFold(k, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// get returns the value for key and whether it exists.
+func (o *OrderedMap) get(key string) (any, bool) {
+	if o.values != nil {
+		mk := o.mapKey(key)
+		v, ok := o.values[mk]
+		if lv, isLazy := v.(lazyValue); isLazy {
+			v = o.parseLazy(lv)
+			o.values[mk] = v
+		}
+		return v, ok
+	}
+	if i := o.index(key); i >= 0 {
+		return o.valueAt(i), true
+	}
+	return nil, false
+}
+
+// valueAt returns the value at position pos.
+func (o *OrderedMap) valueAt(pos int) any {
+	v := o.rawAt(pos)
+	if lv, ok := v.(lazyValue); ok {
+		v = o.parseLazy(lv)
+		if o.values != nil {
+			o.values[o.mapKey(o.keys[pos])] = v
+		} else {
+			o.vals[pos] = v
+		}
+	}
+	return v
+}
+
+// peek is get without side effects: an expired key does not exist, and a lazy
+// value is parsed but not stored.
+func (o *OrderedMap) peek(key string) (any, bool) {
+	if o.expired(key) {
+		return nil, false
+	}
+	var v any
+	ok := false
+	if o.values != nil {
+		v, ok = o.values[o.mapKey(key)]
+	} else if i := o.index(key); i >= 0 {
+		v, ok = o.vals[i], true
+	}
+	if lv, isLazy := v.(lazyValue); isLazy {
+		v = o.parseLazy(lv)
+	}
+	return v, ok
+}
+
+// peekAt is valueAt without storing a parsed lazy value.
+func (o *OrderedMap) peekAt(pos int) any {
+	v := o.rawAt(pos)
+	if lv, ok := v.(lazyValue); ok {
+		v = o.parseLazy(lv)
+	}
+	return v
 }
 
-func New() *OrderedMap {
-	o := OrderedMap{}
-	o.keys = []string{}
-	o.values = map[string]any{}
-	return &o
+// rawAt returns the value at position pos, without parsing a lazy value.
+func (o *OrderedMap) rawAt(pos int) any {
+	if o.values != nil {
+		return o.values[o.mapKey(o.keys[pos])]
+	}
+	return o.vals[pos]
+}
+
+// grow moves a small map's values into a backing Go map.
+func (o *OrderedMap) grow() {
+	// The capacity of keys includes any reserved by Load.
+	o.values = make(map[string]any, cap(o.keys))
+	for i, k := range o.keys {
+		o.values[o.mapKey(k)] = o.vals[i]
+	}
+	o.vals = nil
 }
 
+// Get returns the value for key, or nil if it does not exist.  Get is not a
+// pure read: it deletes key if it has expired and stores a parsed lazy value,
+// so it is not safe for concurrent use.  Reads through ReadOnly are.
 func (o *OrderedMap) Get(key string) any {
-	return o.values[key]
+	if m := o.metrics(); m != nil {
+		m.Gets.Add(1)
+	}
+	o.expire(key)
+	v, _ := o.get(key)
+	return v
 }
 
+// Set sets the value for key.  New keys are appended to the end of the order
+// and existing keys keep their position.  If a validator is set and rejects
+// the pair, or o is frozen, Set panics with the error and o is not modified;
+// use TrySet to handle it.
 func (o *OrderedMap) Set(key string, value any) {
-	_, ok := o.values[key]
-	if !ok {
-		o.keys = append(o.keys, key)
+	if err := o.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+// TrySet is Set, but returns the error of a validator rejecting the pair, or
+// ErrFrozen, instead of panicking.
+func (o *OrderedMap) TrySet(key string, value any) error {
+	if m := o.metrics(); m != nil {
+		m.Sets.Add(1)
+	}
+	return o.store(key, value)
+}
+
+// store is Set without counting in Metrics, for decoding.
+func (o *OrderedMap) store(key string, value any) error {
+	key = o.storedKey(key)
+	if err := o.validate(key, value); err != nil {
+		return err
+	}
+	o.set(key, value)
+	return nil
+}
+
+// set is Set without validation.
+func (o *OrderedMap) set(key string, value any) {
+	key = o.storedKey(key)
+	o.own()
+	o.forget(key)
+	if o.observed() {
+		old, exists := o.get(key)
+		defer o.notifySet(key, old, exists, value)
+	}
+	if o.values != nil {
+		mk := o.mapKey(key)
+		_, ok := o.values[mk]
+		if !ok {
+			o.keys = append(o.keys, key)
+		}
+		o.values[mk] = value
+		return
+	}
+	if i := o.index(key); i >= 0 {
+		o.vals[i] = value
+		return
+	}
+	o.keys = append(o.keys, key)
+	o.vals = append(o.vals, value)
+	if len(o.keys) > smallSize {
+		o.grow()
+	}
+}
+
+// validate returns the validator's error for the pair, if any, or ErrFrozen.
+func (o *OrderedMap) validate(key string, value any) error {
+	if o.frozen {
+		return ErrFrozen
 	}
-	o.values[key] = value
+	if o.opts != nil && o.opts.validator != nil {
+		return o.opts.validator(key, value)
+	}
+	return nil
+}
+
+// SetValidator sets a func that validates every key and value subsequently
+// set, including by UnmarshalJSON, at any depth.  A non-nil error rejects the
+// pair, and is returned by TrySet or panicked with by Set.  It does not
+// validate existing entries.  A nil func removes the
+// validator.
+func (o *OrderedMap) SetValidator(f func(key string, value any) error) {
+	o.opts = o.opts.clone()
+	o.opts.validator = f
 }
 
 func (o *OrderedMap) Delete(key string) {
+	if m := o.metrics(); m != nil {
+		m.Deletes.Add(1)
+	}
+	o.own()
+	o.forget(key)
+	if o.observed() {
+		if old, ok := o.get(key); ok {
+			defer o.notify(Change{Op: OpDelete, Key: key, Old: old, pos: o.index(key)})
+		}
+	}
+	if o.values == nil {
+		if i := o.index(key); i >= 0 {
+			o.deleteSmall(i)
+		}
+		return
+	}
 	// check key is in use
-	_, ok := o.values[key]
+	mk := o.mapKey(key)
+	_, ok := o.values[mk]
 	if !ok {
 		return
 	}
 	// remove from keys
-	for i, k := range o.keys {
-		if k == key {
-			o.keys = append(o.keys[:i], o.keys[i+1:]...)
-			break
-		}
+	if i := o.index(key); i >= 0 {
+		o.keys = append(o.keys[:i], o.keys[i+1:]...)
 	}
 	// remove from values
-	delete(o.values, key)
+	delete(o.values, mk)
+}
+
+// deleteSmall deletes the entry at position i of a small map.
+func (o *OrderedMap) deleteSmall(i int) {
+	o.own()
+	o.keys = append(o.keys[:i], o.keys[i+1:]...)
+	copy(o.vals[i:], o.vals[i+1:])
+	o.vals[len(o.vals)-1] = nil // release the reference
+	o.vals = o.vals[:len(o.vals)-1]
 }
 
+// moveToBack moves the entry at position i to the end of the order.
+func (o *OrderedMap) moveToBack(i int) {
+	o.own()
+	k := o.keys[i]
+	copy(o.keys[i:], o.keys[i+1:])
+	o.keys[len(o.keys)-1] = k
+	if o.values == nil {
+		v := o.vals[i]
+		copy(o.vals[i:], o.vals[i+1:])
+		o.vals[len(o.vals)-1] = v
+	}
+}
+
+// Keys returns the keys in order.  The returned slice is the map's own and
+// must not be modified; use KeysCopy for a copy that may be.  It is clipped,
+// so appending to it does not affect the map.
 func (o *OrderedMap) Keys() []string {
-	return o.keys
+	if o.keys == nil {
+		return []string{}
+	}
+	return o.keys[:len(o.keys):len(o.keys)]
+}
+
+// KeysCopy returns a copy of the keys in order.
+func (o *OrderedMap) KeysCopy() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// All returns an iterator over the key-value pairs in order, without
+// allocating.  o must not be modified during iteration, except by setting
+// existing keys.
+func (o *OrderedMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		if o.shuffles() {
+			for _, i := range o.opts.shuffle.perm(len(o.keys)) {
+				if !yield(o.keys[i], o.valueAt(i)) {
+					return
+				}
+			}
+			return
+		}
+		for i, k := range o.keys {
+			if !yield(k, o.valueAt(i)) {
+				return
+			}
+		}
+	}
+}
+
+// AllKeys returns an iterator over the keys in order.  See All.
+func (o *OrderedMap) AllKeys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if o.shuffles() {
+			for _, i := range o.opts.shuffle.perm(len(o.keys)) {
+				if !yield(o.keys[i]) {
+					return
+				}
+			}
+			return
+		}
+		for _, k := range o.keys {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// AllValues returns an iterator over the values in order.  Unlike Values, it
+// does not allocate.  See All.
+func (o *OrderedMap) AllValues() iter.Seq[any] {
+	return func(yield func(any) bool) {
+		if o.shuffles() {
+			for _, i := range o.opts.shuffle.perm(len(o.keys)) {
+				if !yield(o.valueAt(i)) {
+					return
+				}
+			}
+			return
+		}
+		for i := range o.keys {
+			if !yield(o.valueAt(i)) {
+				return
+			}
+		}
+	}
 }
 
 func (o *OrderedMap) Values() []any {
 	v := make([]any, len(o.keys))
-	for i, k := range o.keys {
-		v[i] = o.values[k]
+	for i := range o.keys {
+		v[i] = o.valueAt(i)
 	}
 	return v
 }
 
+// KeysValues returns a copy of the keys and values as a Go map, so that
+// modifying it never modifies o.
 func (o *OrderedMap) KeysValues() map[string]any {
-	return o.values
+	m := make(map[string]any, len(o.keys))
+	for i, k := range o.keys {
+		m[k] = o.valueAt(i)
+	}
+	return m
 }
 
 func (o *OrderedMap) Len() int {
@@ -114,201 +566,495 @@ func (o *OrderedMap) Len() int {
 }
 
 func (o *OrderedMap) GetValueAt(pos int) any {
-	k := o.keys[pos]
-	return o.values[k]
+	return o.valueAt(pos)
 }
 
 func (o *OrderedMap) GetKeyAt(pos int) string {
 	return o.keys[pos]
 }
 
+// Front returns the first key and value, and false if o is empty.
+func (o *OrderedMap) Front() (key string, value any, ok bool) {
+	if len(o.keys) == 0 {
+		return "", nil, false
+	}
+	return o.keys[0], o.valueAt(0), true
+}
+
+// Back returns the last key and value, and false if o is empty.
+func (o *OrderedMap) Back() (key string, value any, ok bool) {
+	if len(o.keys) == 0 {
+		return "", nil, false
+	}
+	return o.keys[len(o.keys)-1], o.valueAt(len(o.keys) - 1), true
+}
+
+// Oldest is Front, the least recently inserted entry unless o was reordered.
+func (o *OrderedMap) Oldest() (key string, value any, ok bool) {
+	return o.Front()
+}
+
+// Newest is Back, the most recently inserted entry unless o was reordered.
+func (o *OrderedMap) Newest() (key string, value any, ok bool) {
+	return o.Back()
+}
+
+// ErrKeyNotFound is returned by methods that require an existing key.
+var ErrKeyNotFound = errors.New("orderedmap: key not found")
+
+// Move moves key to position pos, shifting the keys in between.  It returns an
+// error wrapping ErrKeyNotFound if key does not exist, and panics if pos is out
+// of range.
+func (o *OrderedMap) Move(key string, pos int) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	i := o.index(key)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+	if pos < 0 || pos >= len(o.keys) {
+		panic("orderedmap: Move position out of range")
+	}
+	o.own()
+	k := o.keys[i]
+	if pos < i {
+		copy(o.keys[pos+1:i+1], o.keys[pos:i])
+	} else {
+		copy(o.keys[i:pos], o.keys[i+1:pos+1])
+	}
+	o.keys[pos] = k
+	if o.values == nil {
+		v := o.vals[i]
+		if pos < i {
+			copy(o.vals[pos+1:i+1], o.vals[pos:i])
+		} else {
+			copy(o.vals[i:pos], o.vals[i+1:pos+1])
+		}
+		o.vals[pos] = v
+	}
+	if o.observed() {
+		o.notify(Change{Op: OpMove, Key: k, Old: i, New: pos})
+	}
+	return nil
+}
+
 // SortKeys sorts the map keys using the provided sort func.
 func (o *OrderedMap) SortKeys(sortFunc func(keys []string)) {
+	if o.observed() {
+		defer o.notify(Change{Op: OpSort, prev: o.saveState()})
+	}
+	o.own()
+	if o.values != nil {
+		sortFunc(o.keys)
+		return
+	}
+	// Small maps must realign values with the sorted keys.
+	oldKeys := append([]string(nil), o.keys...)
+	oldVals := append([]any(nil), o.vals...)
 	sortFunc(o.keys)
+	for i, k := range o.keys {
+		for j, ok := range oldKeys {
+			if ok == k {
+				o.vals[i] = oldVals[j]
+				break
+			}
+		}
+	}
 }
 
 // Sort sorts the map using the provided less func.
 func (o *OrderedMap) Sort(lessFunc func(a *pair, b *pair) bool) {
+	if o.observed() {
+		defer o.notify(Change{Op: OpSort, prev: o.saveState()})
+	}
+	o.own()
 	pairs := make([]*pair, len(o.keys))
 	for i, key := range o.keys {
-		pairs[i] = &pair{key, o.values[key]}
+		pairs[i] = &pair{key, o.valueAt(i)}
 	}
 
 	sort.Sort(byPair{pairs, lessFunc})
 
 	for i, pair := range pairs {
 		o.keys[i] = pair.key
+		if o.values == nil {
+			o.vals[i] = pair.value
+		}
 	}
 }
 
 // MarshalJSON must return no duplicates, and should since orderedMap keys are
-// unique.
+// unique.  The output is compact, as not all encoders compact the output of
+// json.Marshaler.
 func (o OrderedMap) MarshalJSON() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteByte('{')
-	encoder := json.NewEncoder(&buf)
-	encoder.SetEscapeHTML(false)
-	for i, k := range o.keys {
-		if i > 0 {
+	if o.redacts() {
+		return o.marshalRedacted()
+	}
+	if o.layout != nil {
+		return o.marshalFaithful()
+	}
+	if o.marshalsInParallel() {
+		return o.marshalParallel()
+	}
+	e := getEncodeState()
+	defer putEncodeState(e)
+	e.buf.WriteByte('{')
+	if err := o.encodeMembers(e, 0, len(o.keys), true); err != nil {
+		return nil, err
+	}
+	e.buf.WriteByte('}')
+	return bytes.Clone(e.buf.Bytes()), nil
+}
+
+// encodeMembers writes the members at positions i to j of o to e, separated by
+// commas.  If store is true it updates the cache of CacheEncoding.
+func (o *OrderedMap) encodeMembers(e *encodeState, i, j int, store bool) error {
+	buf, encoder := &e.buf, e.enc
+	for n := i; n < j; n++ {
+		k := o.keys[n]
+		if n > i {
 			buf.WriteByte(',')
 		}
+		v := o.rawAt(n)
+		if b, ok := o.cachedMember(k, v); ok {
+			buf.Write(b)
+			continue
+		}
+		mark := buf.Len()
 		// add key
 		if err := encoder.Encode(k); err != nil {
-			return nil, err
+			return err
 		}
+		buf.Truncate(buf.Len() - 1) // Encode's trailing newline
 		buf.WriteByte(':')
 		// add value
-		if err := encoder.Encode(o.values[k]); err != nil {
-			return nil, err
+		if raw, ok := o.rawValue(k, v); ok {
+			buf.Write(raw)
+			continue
+		}
+		if err := encoder.Encode(o.formatValue(v)); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		if store {
+			o.cacheMember(k, v, buf.Bytes()[mark:])
 		}
 	}
-	buf.WriteByte('}')
-	return buf.Bytes(), nil
+	return nil
 }
 
+// UnmarshalJSON replaces the contents of o with the JSON object b.  Nested
+// objects are decoded as OrderedMap and arrays as []any.  Input exceeding the
+// Limits set by WithLimits is rejected with a *LimitError.
 func (o *OrderedMap) UnmarshalJSON(b []byte) error {
-	err := CheckDuplicate(json.NewDecoder(bytes.NewReader(b)))
-	if err != nil {
+	if m := o.metrics(); m != nil {
+		err := o.unmarshal(b, nil)
+		m.countUnmarshal(err)
 		return err
 	}
+	return o.unmarshal(b, nil)
+}
 
-	if o.values == nil {
-		o.values = map[string]any{}
+// unmarshal is UnmarshalJSON.  If only is not nil, values of the top-level
+// keys not in only are kept raw.
+func (o *OrderedMap) unmarshal(b []byte, only map[string]bool) error {
+	if o.opts != nil && o.opts.lenient {
+		s, offsets := toStrict(b)
+		if err := o.unmarshalStrict(s, only); err != nil {
+			return offsets.rebase(err)
+		}
+		o.shiftOffsets(offsets.source)
+		return nil
+	}
+	return o.unmarshalStrict(b, only)
+}
+
+// unmarshalStrict is unmarshal of RFC 8259 JSON.
+func (o *OrderedMap) unmarshalStrict(b []byte, only map[string]bool) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	c := &dupChecker{src: b}
+	if o.mapsKeys() {
+		c.mapKey = o.mapKey
+	}
+	if o.opts != nil {
+		c.limits = o.opts.limits
 	}
-	err = json.Unmarshal(b, &o.values)
+	if c.limits.MaxSize > 0 && int64(len(b)) > c.limits.MaxSize {
+		return &LimitError{Limit: "size", Max: c.limits.MaxSize}
+	}
+	if o.opts != nil && o.opts.ijson && json.Valid(b) {
+		if err := checkIJSON(b); err != nil {
+			return err
+		}
+	}
+	if o.opts != nil && o.opts.backend != nil {
+		return o.unmarshalBackend(b)
+	}
+	if o.decodesZeroCopy(only) && json.Valid(b) {
+		return o.unmarshalZeroCopy(b)
+	}
+	err := c.check(json.NewDecoder(bytes.NewReader(b)), "")
 	if err != nil {
 		return err
 	}
+
 	dec := json.NewDecoder(bytes.NewReader(b))
-	if _, err = dec.Token(); err != nil { // skip '{'
+	token, err := dec.Token()
+	if err != nil {
 		return err
 	}
-	o.keys = make([]string, 0, len(o.values))
-	return decode(dec, o)
+	// By convention, null is a no-op.
+	if token != nil {
+		if delim, ok := token.(json.Delim); !ok || delim != '{' {
+			return fmt.Errorf("orderedmap: cannot unmarshal JSON %v into OrderedMap, expected object", token)
+		}
+		if err := o.replace(func() error { return decode(dec, o, only) }); err != nil {
+			return err
+		}
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return fmt.Errorf("orderedmap: invalid data after top-level JSON object")
+	}
+	o.shiftOffsets(func(off int64) int64 { return keyOffset(off, b, nil) })
+	if o.opts != nil && o.opts.raw {
+		src := bytes.Clone(b)
+		o.resolveRaws(src)
+		o.resolveEnds(src)
+	}
+	return nil
+}
+
+// replace empties o and refills it with fill, reporting the replacement once
+// rather than each key.
+func (o *OrderedMap) replace(fill func() error) error {
+	var prev *state
+	if o.observed() {
+		prev = o.saveState()
+	}
+	h, hist := o.onChange, o.history
+	o.onChange, o.history = nil, nil
+	o.keys, o.vals, o.values, o.expires, o.shared = nil, nil, nil, nil, false
+	o.offsets, o.raws, o.layout = nil, nil, nil
+	err := fill()
+	o.onChange, o.history = h, hist
+	if prev != nil {
+		o.notify(Change{Op: OpUnmarshal, prev: prev})
+	}
+	return err
 }
 
-// decodeOrderedMap
-func decode(dec *json.Decoder, o *OrderedMap) error {
-	hasKey := make(map[string]bool, len(o.values))
+// decode decodes the members of an object, after its opening '{', into o.
+// Values are decoded in a single pass from the token stream so that small
+// nested objects are never backed by a Go map.
+func decode(dec *json.Decoder, o *OrderedMap, only map[string]bool) error {
 	for {
+		prev := dec.InputOffset()
 		token, err := dec.Token()
 		if err != nil {
 			return err
 		}
 		if delim, ok := token.(json.Delim); ok && delim == '}' {
+			o.recordClose(dec.InputOffset() - 1)
 			return nil
 		}
-		key := token.(string)
-		if hasKey[key] {
-			// duplicate key
-			for j, k := range o.keys {
-				if k == key {
-					copy(o.keys[j:], o.keys[j+1:])
-					break
-				}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("orderedmap: invalid JSON object key %v", token)
+		}
+		if o.opts != nil && o.opts.intern {
+			key = intern(key)
+		}
+		o.recordOffset(key, prev)
+		start := dec.InputOffset()
+		if a := o.allocator(); a != nil {
+			o.reserve(a)
+		}
+
+		var value any
+		if only != nil && !only[o.mapKey(key)] {
+			// Skipped values are kept raw and not validated.
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return err
 			}
-			o.keys[len(o.keys)-1] = key
+			o.set(key, lazyValue(raw))
+			o.recordRaw(key, prev, start, dec.InputOffset())
+			continue
+		}
+		if f := o.typeFor(key); f != nil {
+			value = f()
+			if err = dec.Decode(value); err != nil {
+				return fmt.Errorf("orderedmap: decoding %q: %w", key, err)
+			}
+		} else if o.decodesLazily() {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return err
+			}
+			value = lazyValue(raw)
 		} else {
-			hasKey[key] = true
-			o.keys = append(o.keys, key)
+			token, err = dec.Token()
+			if err != nil {
+				return err
+			}
+			if value, err = decodeValue(dec, token, o); err != nil {
+				return err
+			}
 		}
-
-		token, err = dec.Token()
-		if err != nil {
+		if err = o.store(key, value); err != nil {
 			return err
 		}
-		if delim, ok := token.(json.Delim); ok {
-			switch delim {
-			case '{':
-				if values, ok := o.values[key].(map[string]any); ok {
-					newMap := OrderedMap{
-						keys:   make([]string, 0, len(values)),
-						values: values,
-					}
-					if err = decode(dec, &newMap); err != nil {
-						return err
-					}
-					o.values[key] = newMap
-				} else if oldMap, ok := o.values[key].(OrderedMap); ok {
-					newMap := OrderedMap{
-						keys:   make([]string, 0, len(oldMap.values)),
-						values: oldMap.values,
-					}
-					if err = decode(dec, &newMap); err != nil {
-						return err
-					}
-					o.values[key] = newMap
-				} else if err = decode(dec, &OrderedMap{}); err != nil {
-					return err
-				}
-			case '[':
-				if values, ok := o.values[key].([]any); ok {
-					if err = decodeSlice(dec, values); err != nil {
-						return err
-					}
-				} else if err = decodeSlice(dec, []any{}); err != nil {
-					return err
-				}
-			}
+		o.recordRaw(key, prev, start, dec.InputOffset())
+	}
+}
+
+// decodeValue decodes the value beginning with token.  Nested maps are
+// configured like parent.
+func decodeValue(dec *json.Decoder, token json.Token, parent *OrderedMap) (any, error) {
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		m := OrderedMap{opts: parent.opts}
+		if err := decode(dec, &m, nil); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case '[':
+		s, err := decodeSlice(dec, parent)
+		if err != nil || parent.opts == nil || !parent.opts.arrays {
+			return s, err
 		}
+		return OrderedArray(s), nil
 	}
+	return nil, fmt.Errorf("orderedmap: unexpected delimiter %q", delim)
 }
 
-func decodeSlice(dec *json.Decoder, s []any) error {
-	for index := 0; ; index++ {
+// decodeSlice decodes the elements of an array, after its opening '['.
+func decodeSlice(dec *json.Decoder, parent *OrderedMap) ([]any, error) {
+	s := []any{}
+	for {
 		token, err := dec.Token()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if delim, ok := token.(json.Delim); ok {
-			switch delim {
-			case '{':
-				if index < len(s) {
-					if values, ok := s[index].(map[string]any); ok {
-						newMap := OrderedMap{
-							keys:   make([]string, 0, len(values)),
-							values: values,
-						}
-						if err = decode(dec, &newMap); err != nil {
-							return err
-						}
-						s[index] = newMap
-					} else if oldMap, ok := s[index].(OrderedMap); ok {
-						newMap := OrderedMap{
-							keys:   make([]string, 0, len(oldMap.values)),
-							values: oldMap.values,
-						}
-						if err = decode(dec, &newMap); err != nil {
-							return err
-						}
-						s[index] = newMap
-					} else if err = decode(dec, &OrderedMap{}); err != nil {
-						return err
-					}
-				} else if err = decode(dec, &OrderedMap{}); err != nil {
-					return err
-				}
-			case '[':
-				if index < len(s) {
-					if values, ok := s[index].([]any); ok {
-						if err = decodeSlice(dec, values); err != nil {
-							return err
-						}
-					} else if err = decodeSlice(dec, []any{}); err != nil {
-						return err
-					}
-				} else if err = decodeSlice(dec, []any{}); err != nil {
-					return err
-				}
-			case ']':
-				return nil
-			}
+		if delim, ok := token.(json.Delim); ok && delim == ']' {
+			return s, nil
 		}
+		value, err := decodeValue(dec, token, parent)
+		if err != nil {
+			return nil, err
+		}
+		s = appendValue(parent.allocator(), s, value)
+	}
+}
+
+// ErrJSONDuplicate allows applications to check for JSON duplicate error, using
+// errors.Is.  Duplicate errors are of type *DuplicateError.
+var ErrJSONDuplicate = errors.New("Coze: JSON duplicate field")
+
+// DuplicateError reports a duplicate JSON object key.
+type DuplicateError struct {
+	// Key is the duplicate key as it appears at its second occurrence.
+	Key string
+	// First is the key as it appears at its first occurrence, which differs
+	// from Key for keys that are duplicates once normalized, such as by
+	// CaseInsensitive or CheckDuplicateStrict.
+	First string
+	// Path is the JSON path of the key, with "." separating object members and
+	// "[i]" array elements, e.g. `payload.headers.alg` or `sigs[1].alg`.
+	Path string
+	// Offset is the byte offset of the second occurrence of the key.
+	Offset int64
+}
+
+func (e *DuplicateError) Error() string {
+	if e.First != "" && e.First != e.Key {
+		return fmt.Sprintf("%v %q, same as %q, at %s (offset %d)", ErrJSONDuplicate, e.Key, e.First, e.Path, e.Offset)
+	}
+	return fmt.Sprintf("%v %q at %s (offset %d)", ErrJSONDuplicate, e.Key, e.Path, e.Offset)
+}
+
+// Is reports whether target is ErrJSONDuplicate.
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrJSONDuplicate
+}
+
+// Limits bounds JSON input, protecting against deeply nested or oversized
+// untrusted input.  Zero fields are not limited.  Regardless of MaxDepth,
+// encoding/json rejects nesting deeper than 10000.
+type Limits struct {
+	// MaxDepth is the maximum nesting depth of objects and arrays.
+	MaxDepth int
+	// MaxKeys is the maximum total number of object keys, at any depth.
+	MaxKeys int
+	// MaxSize is the maximum input size in bytes.
+	MaxSize int64
+	// MaxMemory is the maximum estimated memory in bytes of the decoded map,
+	// including its keys, values and nested maps.  It is checked before the
+	// map is built.
+	MaxMemory int64
+	// MaxObjectKeys is the maximum number of keys of an object.
+	MaxObjectKeys int
+	// MaxArrayLen is the maximum number of elements of an array.
+	MaxArrayLen int
+	// MaxStringLen is the maximum length in bytes of a decoded string, key
+	// or value.
+	MaxStringLen int
+}
+
+// checkString checks the length of the decoded string s at offset.
+func (l Limits) checkString(s string, offset int64) error {
+	if l.MaxStringLen > 0 && len(s) > l.MaxStringLen {
+		return &LimitError{Limit: "string length", Max: int64(l.MaxStringLen), Offset: offset}
+	}
+	return nil
+}
+
+// checkObject checks the number n of keys of an object so far at offset.
+func (l Limits) checkObject(n int, offset int64) error {
+	if l.MaxObjectKeys > 0 && n > l.MaxObjectKeys {
+		return &LimitError{Limit: "object keys", Max: int64(l.MaxObjectKeys), Offset: offset}
+	}
+	return nil
+}
+
+// checkArray checks the number n of elements of an array so far at offset.
+func (l Limits) checkArray(n int, offset int64) error {
+	if l.MaxArrayLen > 0 && n > l.MaxArrayLen {
+		return &LimitError{Limit: "array length", Max: int64(l.MaxArrayLen), Offset: offset}
 	}
+	return nil
+}
+
+// ErrLimitExceeded allows applications to check for exceeded Limits, using
+// errors.Is.  Limit errors are of type *LimitError.
+var ErrLimitExceeded = errors.New("orderedmap: JSON limit exceeded")
+
+// LimitError reports input exceeding Limits.
+type LimitError struct {
+	// Limit is the name of the exceeded limit: "depth", "keys", "size",
+	// "memory", "object keys", "array length" or "string length".
+	Limit string
+	// Max is the value of the limit.
+	Max int64
+	// Offset is the byte offset at which the limit was exceeded.
+	Offset int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s limit %d at offset %d", ErrLimitExceeded, e.Limit, e.Max, e.Offset)
 }
 
-// ErrJSONDuplicate allows applications to check for JSON duplicate error.
-type ErrJSONDuplicate error
+// Is reports whether target is ErrLimitExceeded.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
 
 // CheckDuplicate checks for JSON duplicates on ingest (unmarshal).  Note that
 // Go maps and structs and Javascript objects (ES6) already require unique
@@ -340,35 +1086,127 @@ type ErrJSONDuplicate error
 // Disallowing duplicates conforms to the small I-JSON RFC. The author of
 // I-JSON, Tim Bray, is also the author of current JSON specification (RFC
 // 8259).  See also https://github.com/json5/json5-spec/issues/38.
+//
+// Duplicates are reported as a *DuplicateError, which includes the JSON path
+// and byte offset of the duplicate key.
 func CheckDuplicate(d *json.Decoder) error {
+	return (&dupChecker{}).check(d, "")
+}
+
+// CheckDuplicateStrict is like CheckDuplicate, but also rejects keys that are
+// equal after Unicode case folding, and after normalize if it is not nil, such
+// as norm.NFKC.String from golang.org/x/text/unicode/norm.  Readers may treat
+// such keys as the same, or show them alike, so that they hide values much
+// like exact duplicates.  The *DuplicateError names both keys.
+func CheckDuplicateStrict(d *json.Decoder, normalize func(key string) string) error {
+	c := &dupChecker{mapKey: func(key string) string {
+		if normalize != nil {
+			key = normalize(key)
+		}
+		return foldKey(key)
+	}}
+	return c.check(d, "")
+}
+
+// CheckDuplicateLimits is like CheckDuplicate, but also rejects JSON exceeding
+// l with a *LimitError.
+func CheckDuplicateLimits(d *json.Decoder, l Limits) error {
+	return (&dupChecker{limits: l}).check(d, "")
+}
+
+// CheckDuplicateBytes is like CheckDuplicate for the JSON in b.
+func CheckDuplicateBytes(b []byte) error {
+	return (&dupChecker{src: b}).check(json.NewDecoder(bytes.NewReader(b)), "")
+}
+
+// dupChecker checks JSON for duplicate keys.
+type dupChecker struct {
+	// src, if not nil, is the input of the decoder and is used for offsets.
+	src []byte
+	// mapKey, if not nil, maps keys to the keys compared for duplicates, for
+	// CaseInsensitive and NormalizeKeys.
+	mapKey func(key string) string
+	limits Limits
+	// multi allows duplicates in the top-level object, for OrderedMultiMap.
+	multi  bool
+	depth  int
+	keys   int
+	memory int64
+}
+
+// check checks the next value of d, which is at path.
+func (c *dupChecker) check(d *json.Decoder, path string) error {
 	t, err := d.Token()
 	if err != nil {
 		return err
 	}
+	if c.limits.MaxSize > 0 && d.InputOffset() > c.limits.MaxSize {
+		return &LimitError{Limit: "size", Max: c.limits.MaxSize, Offset: d.InputOffset()}
+	}
+	if err := c.account(d, valueMemory(t)); err != nil {
+		return err
+	}
+	if s, ok := t.(string); ok {
+		return c.limits.checkString(s, d.InputOffset())
+	}
 
 	// Is it a delimiter?
 	delim, ok := t.(json.Delim)
 	if !ok {
 		return nil // scaler type, nothing to do
 	}
+	if c.depth++; c.limits.MaxDepth > 0 && c.depth > c.limits.MaxDepth {
+		return &LimitError{Limit: "depth", Max: int64(c.limits.MaxDepth), Offset: d.InputOffset()}
+	}
+	defer func() { c.depth-- }()
 
 	switch delim {
 	case '{':
-		keys := make(map[string]bool)
-		for d.More() {
+		keys := make(map[string]string) // first form of each key
+		for n := 1; d.More(); n++ {
+			prev := d.InputOffset()
+			var buffered io.Reader
+			if c.src == nil {
+				buffered = d.Buffered()
+			}
 			t, err := d.Token() // Get field key.
 			if err != nil {
 				return err
 			}
 
-			key := t.(string)
-			if keys[key] { // Check for duplicates.
-				return ErrJSONDuplicate(fmt.Errorf("Coze: JSON duplicate field %q", key))
+			key, ok := t.(string)
+			if !ok {
+				return fmt.Errorf("orderedmap: invalid JSON object key %v", t)
+			}
+			if err := c.account(d, keyMemory(key)); err != nil {
+				return err
+			}
+			if err := c.limits.checkString(key, d.InputOffset()); err != nil {
+				return err
+			}
+			if err := c.limits.checkObject(n, d.InputOffset()); err != nil {
+				return err
+			}
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			setKey := key
+			if c.mapKey != nil {
+				setKey = c.mapKey(key)
+			}
+			if first, dup := keys[setKey]; dup && !(c.multi && c.depth == 1) { // Check for duplicates.
+				return &DuplicateError{Key: key, First: first, Path: keyPath, Offset: keyOffset(prev, c.src, buffered)}
+			}
+			if _, dup := keys[setKey]; !dup {
+				keys[setKey] = key
+			}
+			if c.keys++; c.limits.MaxKeys > 0 && c.keys > c.limits.MaxKeys {
+				return &LimitError{Limit: "keys", Max: int64(c.limits.MaxKeys), Offset: d.InputOffset()}
 			}
-			keys[key] = true
 
 			// Recursive, Check value in case value is object.
-			err = CheckDuplicate(d)
+			err = c.check(d, keyPath)
 			if err != nil {
 				return err
 			}
@@ -379,8 +1217,14 @@ func CheckDuplicate(d *json.Decoder) error {
 		}
 
 	case '[':
-		for d.More() {
-			if err := CheckDuplicate(d); err != nil {
+		for i := 0; d.More(); i++ {
+			if err := c.account(d, sizeofAny); err != nil {
+				return err
+			}
+			if err := c.limits.checkArray(i+1, d.InputOffset()); err != nil {
+				return err
+			}
+			if err := c.check(d, path+"["+strconv.Itoa(i)+"]"); err != nil {
 				return err
 			}
 		}
@@ -391,3 +1235,27 @@ func CheckDuplicate(d *json.Decoder) error {
 	}
 	return nil
 }
+
+// keyOffset returns the offset of an object key, given the offset prev
+// following the previous token, by skipping whitespace and the member
+// separator in src, or in buffered, the decoder's buffered input at prev, if
+// src is nil.
+func keyOffset(prev int64, src []byte, buffered io.Reader) int64 {
+	if src != nil {
+		for prev < int64(len(src)) && isSeparator(src[prev]) {
+			prev++
+		}
+		return prev
+	}
+	var c [1]byte
+	for {
+		if n, _ := buffered.Read(c[:]); n == 0 || !isSeparator(c[0]) {
+			return prev
+		}
+		prev++
+	}
+}
+
+func isSeparator(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ','
+}
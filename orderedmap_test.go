@@ -24,11 +24,16 @@
 package orderedmap
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"math"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestOrderedMap(t *testing.T) {
@@ -128,6 +133,256 @@ func TestOrderedMapDelete(t *testing.T) {
 	}
 }
 
+// TestOrderedMapGrow tests that maps behave the same before and after growing
+// past smallSize.
+func TestOrderedMapGrow(t *testing.T) {
+	o := New()
+	var expectedKeys []string
+	for i := 0; i < smallSize*2; i++ {
+		k := string(rune('a' + i))
+		o.Set(k, i)
+		expectedKeys = append(expectedKeys, k)
+		if o.Get(k) != i {
+			t.Error("Get after Set", k, o.Get(k), "!=", i)
+		}
+	}
+	if o.values == nil {
+		t.Error("map did not grow past smallSize")
+	}
+	if !reflect.DeepEqual(o.Keys(), expectedKeys) {
+		t.Error("Keys after grow", o.Keys(), "!=", expectedKeys)
+	}
+	for i, v := range o.Values() {
+		if v != i {
+			t.Error("Values after grow", i, v)
+		}
+	}
+
+	o.Delete("a")
+	o.Delete("h")
+	if o.Len() != smallSize*2-2 || o.Get("a") != nil || o.GetKeyAt(0) != "b" || o.GetValueAt(6) != 8 {
+		t.Error("Delete after grow", o.Keys(), o.Values())
+	}
+
+	// Small map Delete and Sort keep values aligned with keys.
+	s := New()
+	s.Set("c", 3)
+	s.Set("a", 1)
+	s.Set("b", 2)
+	s.Delete("a")
+	s.Set("a", 1)
+	s.SortKeys(sort.Strings)
+	if !reflect.DeepEqual(s.Keys(), []string{"a", "b", "c"}) || !reflect.DeepEqual(s.Values(), []any{1, 2, 3}) {
+		t.Error("Small map SortKeys", s.Keys(), s.Values())
+	}
+	if !reflect.DeepEqual(s.KeysValues(), map[string]any{"a": 1, "b": 2, "c": 3}) {
+		t.Error("Small map KeysValues", s.KeysValues())
+	}
+}
+
+func TestOrderedMap_EmptyKeys(t *testing.T) {
+	if b, err := json.Marshal(New().Keys()); err != nil || string(b) != "[]" {
+		t.Errorf("got %s, %v, want []", b, err)
+	}
+}
+
+func TestOrderedMap_KeysValuesCopy(t *testing.T) {
+	for _, n := range []int{2, smallSize, 2 * smallSize} {
+		o := New()
+		for i := range n {
+			o.Set("k"+strconv.Itoa(i), i)
+		}
+		kv := o.KeysValues()
+		if len(kv) != n {
+			t.Errorf("%d keys: got %d", n, len(kv))
+		}
+		kv["k0"] = "changed"
+		kv["new"] = 1
+		if o.Get("k0") != 0 || o.Len() != n {
+			t.Errorf("%d keys: map modified through KeysValues", n)
+		}
+	}
+}
+
+func TestOrderedMap_SetValidator(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	o := New()
+	o.SetValidator(func(key string, value any) error {
+		if !utf8.ValidString(key) || key == "" {
+			return errInvalid
+		}
+		if f, ok := value.(float64); ok && math.IsNaN(f) {
+			return errInvalid
+		}
+		return nil
+	})
+	if err := o.TrySet("a", 1.0); err != nil {
+		t.Error("Set valid pair", err)
+	}
+	if err := o.TrySet("b", math.NaN()); err != errInvalid {
+		t.Error("Set did not reject NaN", err)
+	}
+	if err := o.TrySet("\xff", 1.0); err != errInvalid {
+		t.Error("Set did not reject invalid UTF-8 key", err)
+	}
+	if o.Len() != 1 {
+		t.Error("rejected pairs were set", o.Keys())
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != errInvalid {
+				t.Error("Set did not panic with validator error", r)
+			}
+		}()
+		o.Set("", 1.0)
+	}()
+
+	// Nested keys are validated on unmarshal.
+	err := json.Unmarshal([]byte(`{"a":{"":1}}`), o)
+	if !errors.Is(err, errInvalid) {
+		t.Error("Unmarshal did not reject nested empty key", err)
+	}
+
+	o.SetValidator(nil)
+	if err := o.TrySet("b", math.NaN()); err != nil {
+		t.Error("Set with removed validator", err)
+	}
+}
+
+func TestOrderedMap_CaseInsensitive(t *testing.T) {
+	o := New(CaseInsensitive())
+	for i := 0; i < smallSize*2; i++ { // large map
+		o.Set(string(rune('a'+i)), i)
+	}
+	for _, m := range []*OrderedMap{New(CaseInsensitive()), o} {
+		m.Set("Content-Type", "text/plain")
+		m.Set("CONTENT-TYPE", "application/json")
+		if m.Get("content-type") != "application/json" {
+			t.Error("case-insensitive Get", m.Get("content-type"))
+		}
+		if m.GetKeyAt(m.Len()-1) != "Content-Type" {
+			t.Error("original casing not preserved", m.Keys())
+		}
+		if _, ok := m.KeysValues()["Content-Type"]; !ok {
+			t.Error("KeysValues does not use original casing", m.KeysValues())
+		}
+		l := m.Len()
+		m.Delete("content-TYPE")
+		if m.Get("Content-Type") != nil || m.Len() != l-1 {
+			t.Error("case-insensitive Delete", m.Keys())
+		}
+	}
+
+	// Unicode folding, like strings.EqualFold.
+	o.Set("Straße", 1)
+	if o.Get("STRASSE") != nil || o.Get("strasse") != nil || o.Get("STRAßE") != 1 {
+		t.Error("folding is not simple case folding")
+	}
+
+	o = New(CaseInsensitive())
+	err := json.Unmarshal([]byte(`{"a":1,"b":{"x":1,"X":2}}`), o)
+	if err == nil {
+		t.Error("Unmarshal did not error on nested case-insensitive duplicate")
+	}
+	var de *DuplicateError
+	if !errors.As(err, &de) || de.Key != "X" || de.Path != "b.X" {
+		t.Error("case-insensitive duplicate error", err)
+	}
+	err = json.Unmarshal([]byte(`{"Alg":"ES256","b":{"X":1}}`), o)
+	if err != nil {
+		t.Error("Unmarshal", err)
+	}
+	b := o.Get("B").(OrderedMap)
+	if o.Get("alg") != "ES256" || b.Get("x") != float64(1) {
+		t.Error("Unmarshal case-insensitive lookup", o.Get("alg"), b.Get("x"))
+	}
+}
+
+func TestOrderedMap_KeysCopy(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Delete("b") // leave spare capacity
+
+	k := o.KeysCopy()
+	k[0] = "x"
+	_ = append(o.Keys(), "y")
+	o.Set("c", 3)
+	if !reflect.DeepEqual(o.Keys(), []string{"a", "c"}) {
+		t.Error("modifying copied or appended keys changed the map", o.Keys())
+	}
+}
+
+func TestOrderedMap_All(t *testing.T) {
+	o := New()
+	for i := 0; i < smallSize*2; i++ {
+		o.Set(string(rune('a'+i)), i)
+	}
+	var keys []string
+	var values []any
+	for k, v := range o.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	if !reflect.DeepEqual(keys, o.Keys()) || !reflect.DeepEqual(values, o.Values()) {
+		t.Error("All", keys, values)
+	}
+	keys, values = nil, nil
+	for k := range o.AllKeys() {
+		keys = append(keys, k)
+	}
+	for v := range o.AllValues() {
+		values = append(values, v)
+	}
+	if !reflect.DeepEqual(keys, o.Keys()) || !reflect.DeepEqual(values, o.Values()) {
+		t.Error("AllKeys and AllValues", keys, values)
+	}
+	for k := range o.All() {
+		if k != "a" {
+			t.Error("All did not stop")
+		}
+		break
+	}
+
+	sum := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, v := range o.All() {
+			sum += v.(int)
+		}
+		for v := range o.AllValues() {
+			sum += v.(int)
+		}
+	})
+	if allocs != 0 {
+		t.Error("iteration allocated", allocs)
+	}
+}
+
+func TestOrderedMap_FrontBack(t *testing.T) {
+	o := New()
+	if _, _, ok := o.Front(); ok {
+		t.Error("Front of empty map")
+	}
+	if _, _, ok := o.Newest(); ok {
+		t.Error("Newest of empty map")
+	}
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Set("c", 3)
+	if k, v, ok := o.Front(); k != "a" || v != 1 || !ok {
+		t.Error("Front", k, v, ok)
+	}
+	if k, v, ok := o.Back(); k != "c" || v != 3 || !ok {
+		t.Error("Back", k, v, ok)
+	}
+	if k, _, _ := o.Oldest(); k != "a" {
+		t.Error("Oldest", k)
+	}
+	if k, _, _ := o.Newest(); k != "c" {
+		t.Error("Newest", k)
+	}
+}
+
 func TestBlankMarshalJSON(t *testing.T) {
 	o := New()
 	// blank map
@@ -209,8 +464,8 @@ func TestMarshalJSONNoEscapeHTML(t *testing.T) {
 	if err != nil {
 		t.Error("Marshalling json", err)
 	}
-	s := strings.Replace(string(b), "\n", "", -1)
-	// check json is correctly ordered
+	s := string(b)
+	// check json is correctly ordered and compact
 	if s != `{"specialstring":"\\.<>[]{}_-"}` {
 		t.Error("JSON Marshal value is incorrect", s)
 	}
@@ -227,7 +482,7 @@ func TestMarshalJSONNoEscapeHTMLRecursive(t *testing.T) {
 	if err != nil {
 		t.Error("Marshalling json", err)
 	}
-	s := strings.Replace(string(b), "\n", "", -1)
+	s := string(b)
 	if s != src {
 		t.Error("JSON Marshal value is incorrect", s)
 	}
@@ -357,6 +612,128 @@ func TestOrderedMapUnmarshalJSONDuplicate(t *testing.T) {
 	if err == nil {
 		t.Errorf("orderedMap unmarshal did not error on duplicate key")
 	}
+	if !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("duplicate error is not ErrJSONDuplicate", err)
+	}
+	var de *DuplicateError
+	if !errors.As(err, &de) || de.Key != "b" || de.Path != "b" || de.Offset != int64(strings.LastIndex(s, `"b"`)) {
+		t.Errorf("duplicate error %#v", de)
+	}
+}
+
+func TestCheckDuplicateBytes(t *testing.T) {
+	s := `{"payload":{"headers":{"alg":"ES256", "alg":"ES384"}}}`
+	offset := strings.LastIndex(s, `"alg"`)
+	for _, err := range []error{
+		CheckDuplicateBytes([]byte(s)),
+		CheckDuplicate(json.NewDecoder(strings.NewReader(s))),
+	} {
+		if err == nil {
+			t.Fatal("CheckDuplicate did not error on duplicate key")
+		}
+		if !strings.Contains(err.Error(), "payload.headers.alg") || !strings.Contains(err.Error(), "offset "+strconv.Itoa(offset)) {
+			t.Error("duplicate error missing path or offset:", err)
+		}
+	}
+
+	s = `{"sigs":[{"alg":1},{"alg":1,` + "\n\t" + `"alg":2}]}`
+	offset = strings.LastIndex(s, `"alg"`)
+	err := CheckDuplicateBytes([]byte(s))
+	if err == nil || !strings.Contains(err.Error(), "sigs[1].alg") || !strings.Contains(err.Error(), "offset "+strconv.Itoa(offset)) {
+		t.Error("duplicate error in array", err)
+	}
+
+	if err := CheckDuplicateBytes([]byte(`{"a":[{"b":1},{"b":1}]}`)); err != nil {
+		t.Error("CheckDuplicateBytes errored on unique keys", err)
+	}
+}
+
+func TestCheckDuplicateStrict(t *testing.T) {
+	s := `{"a":[{"Alg":1,"ALG":2}]}`
+	var de *DuplicateError
+	err := CheckDuplicateStrict(json.NewDecoder(strings.NewReader(s)), nil)
+	if !errors.As(err, &de) || de.Key != "ALG" || de.First != "Alg" || de.Path != "a[0].ALG" {
+		t.Errorf("got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"ALG", same as "Alg"`) {
+		t.Errorf("error does not name both keys: %v", err)
+	}
+	if err := CheckDuplicate(json.NewDecoder(strings.NewReader(s))); err != nil {
+		t.Errorf("CheckDuplicate: %v", err)
+	}
+
+	s = `{" a":1,"a":2}`
+	if err := CheckDuplicateStrict(json.NewDecoder(strings.NewReader(s)), strings.TrimSpace); !errors.As(err, &de) || de.First != " a" {
+		t.Errorf("normalized: got %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	deep := strings.Repeat(`{"a":`, 101) + "1" + strings.Repeat("}", 101)
+	err := New(WithLimits(Limits{MaxDepth: 100})).UnmarshalJSON([]byte(deep))
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "depth" || le.Max != 100 {
+		t.Error("UnmarshalJSON did not reject MaxDepth", err)
+	}
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Error("limit error is not ErrLimitExceeded", err)
+	}
+
+	s := `{"a":[[1]],"b":{"c":2,"d":3}}`
+	for _, test := range []struct {
+		limits Limits
+		limit  string
+	}{
+		{Limits{MaxDepth: 2}, "depth"},
+		{Limits{MaxKeys: 3}, "keys"},
+		{Limits{MaxSize: 10}, "size"},
+		{Limits{MaxDepth: 3, MaxKeys: 4, MaxSize: int64(len(s))}, ""},
+	} {
+		err = CheckDuplicateLimits(json.NewDecoder(strings.NewReader(s)), test.limits)
+		err2 := New(WithLimits(test.limits)).UnmarshalJSON([]byte(s))
+		for _, err := range []error{err, err2} {
+			if test.limit == "" {
+				if err != nil {
+					t.Error("limits", test.limits, err)
+				}
+				continue
+			}
+			if !errors.As(err, &le) || le.Limit != test.limit {
+				t.Error("limits", test.limits, err)
+			}
+		}
+	}
+}
+
+func TestLimits_Shape(t *testing.T) {
+	s := `{"a":[1,2,3],"b":{"c":"long","d":3},"long key":true}`
+	for _, test := range []struct {
+		limits Limits
+		limit  string
+	}{
+		{Limits{MaxObjectKeys: 2}, "object keys"},
+		{Limits{MaxArrayLen: 2}, "array length"},
+		{Limits{MaxStringLen: 3}, "string length"},
+		{Limits{MaxObjectKeys: 3, MaxArrayLen: 3, MaxStringLen: 8}, ""},
+	} {
+		for _, opt := range []Option{WithLimits(test.limits), ZeroCopy(), WithBackend(tokenBackend{})} {
+			err := New(WithLimits(test.limits), opt).UnmarshalJSON([]byte(s))
+			if test.limit == "" {
+				if err != nil {
+					t.Error("limits", test.limits, err)
+				}
+				continue
+			}
+			var le *LimitError
+			if !errors.As(err, &le) || le.Limit != test.limit {
+				t.Error("limits", test.limits, err)
+			}
+		}
+		err := CheckDuplicateLimits(json.NewDecoder(strings.NewReader(s)), test.limits)
+		if (err == nil) != (test.limit == "") {
+			t.Error("CheckDuplicateLimits", test.limits, err)
+		}
+	}
 }
 
 func TestUnmarshalJSONSpecialChars(t *testing.T) {
@@ -545,3 +922,64 @@ func TestOrderedMap_empty_map(t *testing.T) {
 		t.Error("Got", marshalledStr)
 	}
 }
+
+func TestUnmarshalJSONMalformed(t *testing.T) {
+	for _, s := range []string{
+		`[1]`,
+		`[{"a":1}]`,
+		`"x"`,
+		`1`,
+		`{"a":1}{"b":2}`,
+		`{"a":1} x`,
+		`{"a":`,
+		`{1:2}`,
+		``,
+	} {
+		if err := New().UnmarshalJSON([]byte(s)); err == nil {
+			t.Errorf("UnmarshalJSON(%q) did not error", s)
+		}
+	}
+
+	o := New()
+	o.Set("a", 1)
+	if err := o.UnmarshalJSON([]byte("null")); err != nil || o.Get("a") != 1 {
+		t.Error("UnmarshalJSON null is not a no-op", err)
+	}
+}
+
+// FuzzUnmarshalJSON checks that UnmarshalJSON does not panic and that accepted
+// input round trips.
+func FuzzUnmarshalJSON(f *testing.F) {
+	for _, s := range []string{
+		`{}`,
+		`{"a":1,"b":[1,"2",{"c":null}],"d":{"e":true}}`,
+		`{"a":1,"a":2}`,
+		`[1]`,
+		`{"a":[[[]]]}`,
+		` { "\u0041" : "\ud83d\ude00" } `,
+		`{"a":1}{}`,
+		`null`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		o := New()
+		if err := o.UnmarshalJSON(b); err != nil || bytes.Equal(b, []byte("null")) {
+			return
+		}
+		if !json.Valid(b) {
+			t.Fatalf("UnmarshalJSON accepted invalid JSON %q", b)
+		}
+		m, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		o2 := New()
+		if err = o2.UnmarshalJSON(m); err != nil {
+			t.Fatalf("UnmarshalJSON of MarshalJSON output %q: %v", m, err)
+		}
+		if !o.EqualOrdered(o2) {
+			t.Fatalf("round trip of %q is not EqualOrdered: %q", b, m)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+)
+
+// ParallelMarshal makes MarshalJSON of maps with at least minKeys keys, at
+// any depth, encode ranges of keys concurrently, on up to GOMAXPROCS
+// goroutines, and join the results.  The output is the same as without it.
+// It pays off for maps of thousands of keys or large values, which must be
+// safe to encode concurrently.
+func ParallelMarshal(minKeys int) Option {
+	return func(opts *options) { opts.parallel = minKeys }
+}
+
+// marshalsInParallel reports whether MarshalJSON of o encodes in parallel.
+func (o *OrderedMap) marshalsInParallel() bool {
+	return o.opts != nil && o.opts.parallel > 0 && len(o.keys) >= o.opts.parallel && runtime.GOMAXPROCS(0) > 1
+}
+
+// marshalParallel is MarshalJSON encoding ranges of keys concurrently.
+func (o *OrderedMap) marshalParallel() ([]byte, error) {
+	n := min(runtime.GOMAXPROCS(0), len(o.keys))
+	states := make([]*encodeState, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for c := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			states[c] = getEncodeState()
+			errs[c] = o.encodeMembers(states[c], c*len(o.keys)/n, (c+1)*len(o.keys)/n, false)
+		}()
+	}
+	wg.Wait()
+	defer func() {
+		for _, e := range states {
+			putEncodeState(e)
+		}
+	}()
+
+	size := n + 1
+	for c, e := range states {
+		if errs[c] != nil {
+			return nil, errs[c]
+		}
+		size += e.buf.Len()
+	}
+	var buf bytes.Buffer
+	buf.Grow(size)
+	buf.WriteByte('{')
+	for c, e := range states {
+		if c > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(e.buf.Bytes())
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,75 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestParallelMarshal(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	for _, n := range []int{1, 3, 4, 5, 100} {
+		o, p := New(), New(ParallelMarshal(1))
+		for i := range n {
+			k := "k" + strconv.Itoa(i)
+			nested := New()
+			nested.Set("i", i)
+			o.Set(k, nested)
+			p.Set(k, nested)
+		}
+		want, _ := o.MarshalJSON()
+		got, err := p.MarshalJSON()
+		if err != nil || string(got) != string(want) {
+			t.Errorf("n %d: got %s, %v, want %s", n, got, err, want)
+		}
+	}
+
+	p := New(ParallelMarshal(1))
+	p.Set("a", 1)
+	p.Set("b", func() {})
+	if _, err := p.MarshalJSON(); err == nil {
+		t.Error("no error for unsupported value")
+	}
+}
+
+func BenchmarkParallelMarshal(b *testing.B) {
+	for _, opts := range [][]Option{nil, {ParallelMarshal(1000)}} {
+		o := New(opts...)
+		for i := range 10000 {
+			o.Set(strconv.Itoa(i), []any{"value", float64(i), true, nil})
+		}
+		name := "serial"
+		if opts != nil {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			for range b.N {
+				if _, err := o.MarshalJSON(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetPath sets the value at path, a "."-separated list of keys such as
+// "a.b.c", creating nested maps with the options of o as needed.  New keys are
+// appended to the end of the order like Set.  Nested OrderedMap values are
+// copied before being modified, while *OrderedMap values are modified in
+// place.  If a key of path other than the last has a value that is not a map,
+// SetPath returns an error wrapping ErrKeyCollision and o is not modified.
+// Keys containing "." cannot be set by SetPath.
+func (o *OrderedMap) SetPath(path string, value any) error {
+	return setPath(o, strings.Split(path, "."), value)
+}
+
+func setPath(o *OrderedMap, keys []string, value any) error {
+	if len(keys) == 1 {
+		return o.TrySet(keys[0], value)
+	}
+	o.expire(keys[0])
+	v, ok := o.get(keys[0])
+	if !ok {
+		n := o.empty()
+		if err := setPath(n, keys[1:], value); err != nil {
+			return err
+		}
+		return o.TrySet(keys[0], *n)
+	}
+	switch m := v.(type) {
+	case *OrderedMap:
+		if m != nil {
+			return setPath(m, keys[1:], value)
+		}
+	case OrderedMap:
+		// m shares storage with the stored value, so copy it on write.
+		m.shared = true
+		if err := setPath(&m, keys[1:], value); err != nil {
+			return err
+		}
+		return o.TrySet(keys[0], m)
+	}
+	return fmt.Errorf("%w: %q is not a map", ErrKeyCollision, keys[0])
+}
@@ -0,0 +1,69 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderedMap_SetPath(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"a":{"x":1},"s":"v"}`)); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.Snapshot()
+	p := New()
+	o.Set("p", p)
+
+	for _, tc := range []struct {
+		path  string
+		value any
+	}{
+		{"a.y", 2},
+		{"a.x", 3},
+		{"b.c.d", true},
+		{"b.c.e", nil},
+		{"p.q", "r"},
+		{"top", 0},
+	} {
+		if err := o.SetPath(tc.path, tc.value); err != nil {
+			t.Fatalf("SetPath(%q): %v", tc.path, err)
+		}
+	}
+	b, _ := o.MarshalJSON()
+	want := `{"a":{"x":3,"y":2},"s":"v","p":{"q":"r"},"b":{"c":{"d":true,"e":null}},"top":0}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	if b, _ = snap.MarshalJSON(); string(b) != `{"a":{"x":1},"s":"v"}` {
+		t.Errorf("snapshot modified: %s", b)
+	}
+
+	if err := o.SetPath("s.t", 1); !errors.Is(err, ErrKeyCollision) {
+		t.Errorf("got %v, want ErrKeyCollision", err)
+	}
+	if err := o.SetPath("a.x.y", 1); !errors.Is(err, ErrKeyCollision) {
+		t.Errorf("got %v, want ErrKeyCollision", err)
+	}
+}
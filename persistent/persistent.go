@@ -0,0 +1,442 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package persistent provides an immutable ordered map.  Set and Delete return
+// new versions that share structure with the old, so keeping many versions of
+// similar maps costs memory proportional to their differences.
+//
+// Keys are stored in a hash array mapped trie (HAMT) and their order in a
+// persistent AVL tree keyed by insertion sequence.  Set, Delete and Get are
+// O(log n).
+package persistent
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/maphash"
+	"iter"
+	"math/bits"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+var seed = maphash.MakeSeed()
+
+// Map is an immutable ordered map.  A nil *Map is an empty map.  Maps are safe
+// for concurrent use.
+type Map struct {
+	root  *node
+	order *tnode
+	// next is the sequence of the next inserted key.
+	next uint64
+	len  int
+}
+
+// New returns an empty Map.
+func New() *Map {
+	return &Map{}
+}
+
+// FromOrderedMap returns a Map with the entries of o in order.  Nested maps are
+// not converted.
+func FromOrderedMap(o *orderedmap.OrderedMap) *Map {
+	m := New()
+	for k, v := range o.All() {
+		m = m.Set(k, v)
+	}
+	return m
+}
+
+// OrderedMap returns an OrderedMap with the entries of m in order.
+func (m *Map) OrderedMap() *orderedmap.OrderedMap {
+	o := orderedmap.New()
+	for k, v := range m.All() {
+		o.Set(k, v)
+	}
+	return o
+}
+
+// Len returns the number of entries.
+func (m *Map) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.len
+}
+
+// Get returns the value for key and whether it exists.
+func (m *Map) Get(key string) (any, bool) {
+	if m == nil {
+		return nil, false
+	}
+	if e := m.root.lookup(0, hash(key), key); e != nil {
+		return e.value, true
+	}
+	return nil, false
+}
+
+// Set returns a Map with key set to value.  New keys are appended to the end
+// of the order and existing keys keep their position.  m is not modified.
+func (m *Map) Set(key string, value any) *Map {
+	if m == nil {
+		m = New()
+	}
+	e := &entry{key: key, value: value, seq: m.next}
+	root, old := m.root.insert(0, hash(key), e)
+	n := &Map{root: root, order: m.order, next: m.next, len: m.len}
+	if old != nil {
+		e.seq = old.seq // keeps its position
+		return n
+	}
+	n.order = n.order.insert(e.seq, key)
+	n.next++
+	n.len++
+	return n
+}
+
+// Delete returns a Map without key.  If key does not exist it returns m.
+func (m *Map) Delete(key string) *Map {
+	if m == nil {
+		return m
+	}
+	root, old := m.root.remove(0, hash(key), key)
+	if old == nil {
+		return m
+	}
+	return &Map{root: root, order: m.order.remove(old.seq), next: m.next, len: m.len - 1}
+}
+
+// All returns an iterator over the key-value pairs in order.
+func (m *Map) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		if m == nil {
+			return
+		}
+		m.order.walk(func(key string) bool {
+			v, _ := m.Get(key)
+			return yield(key, v)
+		})
+	}
+}
+
+// Keys returns the keys in order.
+func (m *Map) Keys() []string {
+	keys := make([]string, 0, m.Len())
+	for k := range m.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// MarshalJSON encodes m as a JSON object in order, like OrderedMap.
+func (m *Map) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	i := 0
+	for k, v := range m.All() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		i++
+		b, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		buf.WriteByte(':')
+		if b, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// hash is replaced by tests to cause collisions.
+var hash = func(key string) uint64 {
+	return maphash.String(seed, key)
+}
+
+// HAMT
+
+const (
+	bitsPerLevel = 5
+	levelMask    = 1<<bitsPerLevel - 1
+)
+
+type entry struct {
+	key   string
+	value any
+	seq   uint64
+}
+
+// node is an interior node of the HAMT.  Each child is a *node or a *leaf.
+type node struct {
+	bitmap   uint32
+	children []any
+}
+
+// leaf holds the entries of keys with the same hash.
+type leaf struct {
+	hash    uint64
+	entries []*entry
+}
+
+// slot returns the bit of h at shift and the position of its child.
+func (n *node) slot(shift uint, h uint64) (uint32, int) {
+	bit := uint32(1) << ((h >> shift) & levelMask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *node) lookup(shift uint, h uint64, key string) *entry {
+	for n != nil {
+		bit, pos := n.slot(shift, h)
+		if n.bitmap&bit == 0 {
+			return nil
+		}
+		switch c := n.children[pos].(type) {
+		case *node:
+			n, shift = c, shift+bitsPerLevel
+		case *leaf:
+			if c.hash != h {
+				return nil
+			}
+			for _, e := range c.entries {
+				if e.key == key {
+					return e
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// insert returns a copy of n with e, and the entry it replaced, if any.
+func (n *node) insert(shift uint, h uint64, e *entry) (*node, *entry) {
+	if n == nil {
+		n = &node{}
+	}
+	bit, pos := n.slot(shift, h)
+	if n.bitmap&bit == 0 {
+		c := &node{bitmap: n.bitmap | bit, children: make([]any, len(n.children)+1)}
+		copy(c.children, n.children[:pos])
+		c.children[pos] = &leaf{hash: h, entries: []*entry{e}}
+		copy(c.children[pos+1:], n.children[pos:])
+		return c, nil
+	}
+	var child any
+	var old *entry
+	switch c := n.children[pos].(type) {
+	case *node:
+		child, old = c.insert(shift+bitsPerLevel, h, e)
+	case *leaf:
+		if c.hash != h {
+			child = merge(shift+bitsPerLevel, c, &leaf{hash: h, entries: []*entry{e}})
+			break
+		}
+		l := &leaf{hash: h, entries: append([]*entry(nil), c.entries...)}
+		for i, x := range l.entries {
+			if x.key == e.key {
+				old, l.entries[i] = x, e
+				break
+			}
+		}
+		if old == nil {
+			l.entries = append(l.entries, e)
+		}
+		child = l
+	}
+	return n.with(pos, child), old
+}
+
+// merge returns a node holding leaves a and b, whose hashes differ.
+func merge(shift uint, a, b *leaf) *node {
+	ia, ib := (a.hash>>shift)&levelMask, (b.hash>>shift)&levelMask
+	if ia == ib {
+		return &node{bitmap: 1 << ia, children: []any{merge(shift+bitsPerLevel, a, b)}}
+	}
+	if ia > ib {
+		a, b = b, a
+	}
+	return &node{bitmap: 1<<ia | 1<<ib, children: []any{a, b}}
+}
+
+// with returns a copy of n with the child at pos replaced.
+func (n *node) with(pos int, child any) *node {
+	c := &node{bitmap: n.bitmap, children: append([]any(nil), n.children...)}
+	c.children[pos] = child
+	return c
+}
+
+// without returns a copy of n without the child at pos and bit, or nil if it
+// would be empty.
+func (n *node) without(bit uint32, pos int) *node {
+	if n.bitmap == bit {
+		return nil
+	}
+	c := &node{bitmap: n.bitmap &^ bit, children: make([]any, 0, len(n.children)-1)}
+	c.children = append(append(c.children, n.children[:pos]...), n.children[pos+1:]...)
+	return c
+}
+
+// remove returns a copy of n without key, and the removed entry, or n and nil
+// if key does not exist.
+func (n *node) remove(shift uint, h uint64, key string) (*node, *entry) {
+	if n == nil {
+		return nil, nil
+	}
+	bit, pos := n.slot(shift, h)
+	if n.bitmap&bit == 0 {
+		return n, nil
+	}
+	switch c := n.children[pos].(type) {
+	case *node:
+		child, old := c.remove(shift+bitsPerLevel, h, key)
+		if old == nil {
+			return n, nil
+		}
+		if child == nil {
+			return n.without(bit, pos), old
+		}
+		// Collapse a node left holding only a leaf.
+		if len(child.children) == 1 {
+			if l, ok := child.children[0].(*leaf); ok {
+				return n.with(pos, l), old
+			}
+		}
+		return n.with(pos, child), old
+	case *leaf:
+		if c.hash != h {
+			return n, nil
+		}
+		for i, e := range c.entries {
+			if e.key != key {
+				continue
+			}
+			if len(c.entries) == 1 {
+				return n.without(bit, pos), e
+			}
+			l := &leaf{hash: h, entries: make([]*entry, 0, len(c.entries)-1)}
+			l.entries = append(append(l.entries, c.entries[:i]...), c.entries[i+1:]...)
+			return n.with(pos, l), e
+		}
+	}
+	return n, nil
+}
+
+// Order tree
+
+// tnode is a node of a persistent AVL tree of keys by insertion sequence.
+type tnode struct {
+	seq         uint64
+	key         string
+	left, right *tnode
+	height      int
+}
+
+func (t *tnode) h() int {
+	if t == nil {
+		return 0
+	}
+	return t.height
+}
+
+// with returns a copy of t's entry with the given children.
+func (t *tnode) with(left, right *tnode) *tnode {
+	return &tnode{seq: t.seq, key: t.key, left: left, right: right, height: max(left.h(), right.h()) + 1}
+}
+
+// balance returns t's entry with the given children, rebalanced.  The
+// children's heights differ by at most 2.
+func (t *tnode) balance(left, right *tnode) *tnode {
+	switch d := left.h() - right.h(); {
+	case d > 1:
+		if left.left.h() < left.right.h() {
+			left = left.rotateLeft()
+		}
+		return t.with(left, right).rotateRight()
+	case d < -1:
+		if right.right.h() < right.left.h() {
+			right = right.rotateRight()
+		}
+		return t.with(left, right).rotateLeft()
+	}
+	return t.with(left, right)
+}
+
+func (t *tnode) rotateRight() *tnode {
+	l := t.left
+	return l.with(l.left, t.with(l.right, t.right))
+}
+
+func (t *tnode) rotateLeft() *tnode {
+	r := t.right
+	return r.with(t.with(t.left, r.left), r.right)
+}
+
+func (t *tnode) insert(seq uint64, key string) *tnode {
+	if t == nil {
+		return &tnode{seq: seq, key: key, height: 1}
+	}
+	if seq < t.seq {
+		return t.balance(t.left.insert(seq, key), t.right)
+	}
+	return t.balance(t.left, t.right.insert(seq, key))
+}
+
+func (t *tnode) remove(seq uint64) *tnode {
+	switch {
+	case t == nil:
+		return nil
+	case seq < t.seq:
+		return t.balance(t.left.remove(seq), t.right)
+	case seq > t.seq:
+		return t.balance(t.left, t.right.remove(seq))
+	case t.left == nil:
+		return t.right
+	case t.right == nil:
+		return t.left
+	}
+	min := t.right
+	for min.left != nil {
+		min = min.left
+	}
+	return min.balance(t.left, t.right.removeMin())
+}
+
+func (t *tnode) removeMin() *tnode {
+	if t.left == nil {
+		return t.right
+	}
+	return t.balance(t.left.removeMin(), t.right)
+}
+
+// walk calls f with each key in order until f returns false, and reports
+// whether f always returned true.
+func (t *tnode) walk(f func(key string) bool) bool {
+	if t == nil {
+		return true
+	}
+	return t.left.walk(f) && f(t.key) && t.right.walk(f)
+}
@@ -0,0 +1,119 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package persistent
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/cyphrme/orderedmap"
+)
+
+func TestMap(t *testing.T) {
+	var m *Map
+	m1 := m.Set("b", 1).Set("a", 2)
+	m2 := m1.Set("b", 3).Set("c", 4)
+	m3 := m2.Delete("a")
+
+	if m.Len() != 0 || !reflect.DeepEqual(m1.Keys(), []string{"b", "a"}) {
+		t.Error("m1", m1.Keys())
+	}
+	if v, _ := m1.Get("b"); v != 1 {
+		t.Error("m1 modified", v)
+	}
+	if v, _ := m2.Get("b"); v != 3 || !reflect.DeepEqual(m2.Keys(), []string{"b", "a", "c"}) {
+		t.Error("m2", m2.Keys(), v)
+	}
+	if _, ok := m3.Get("a"); ok || m3.Len() != 2 || !reflect.DeepEqual(m3.Keys(), []string{"b", "c"}) {
+		t.Error("m3", m3.Keys())
+	}
+	if m3.Delete("missing") != m3 {
+		t.Error("Delete of missing key returned a new map")
+	}
+
+	b, err := m3.MarshalJSON()
+	if err != nil || string(b) != `{"b":3,"c":4}` {
+		t.Error("MarshalJSON", string(b), err)
+	}
+	o := m3.OrderedMap()
+	if !reflect.DeepEqual(FromOrderedMap(o).Keys(), o.Keys()) {
+		t.Error("OrderedMap round trip", o.Keys())
+	}
+}
+
+// TestMapRandom checks Map against OrderedMap over random operations, keeping
+// every version.
+func TestMapRandom(t *testing.T) {
+	testMapRandom(t)
+	// Collide all keys with the same low bits, and some entirely.
+	defer func(h func(string) uint64) { hash = h }(hash)
+	hash = func(key string) uint64 { return uint64(len(key)) << 60 }
+	testMapRandom(t)
+}
+
+func testMapRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	versions := []*Map{New()}
+	models := []*orderedmap.OrderedMap{orderedmap.New()}
+	for i := 0; i < 2000; i++ {
+		m, o := versions[len(versions)-1], models[len(models)-1]
+		o2 := orderedmap.New()
+		for k, v := range o.All() {
+			o2.Set(k, v)
+		}
+		k := strconv.Itoa(r.Intn(300))
+		if r.Intn(3) == 0 {
+			m = m.Delete(k)
+			o2.Delete(k)
+		} else {
+			m = m.Set(k, i)
+			o2.Set(k, i)
+		}
+		versions, models = append(versions, m), append(models, o2)
+	}
+	for i, m := range versions {
+		o := models[i]
+		if !balanced(m.order) {
+			t.Fatalf("version %d: order tree unbalanced", i)
+		}
+		if m.Len() != o.Len() || !slices.Equal(m.Keys(), o.Keys()) {
+			t.Fatalf("version %d: keys differ", i)
+		}
+		for k, v := range o.All() {
+			if got, ok := m.Get(k); !ok || got != v {
+				t.Fatalf("version %d: Get(%q) = %v, want %v", i, k, got, v)
+			}
+		}
+	}
+}
+
+func balanced(t *tnode) bool {
+	if t == nil {
+		return true
+	}
+	d := t.left.h() - t.right.h()
+	return d >= -1 && d <= 1 && t.height == max(t.left.h(), t.right.h())+1 && balanced(t.left) && balanced(t.right)
+}
@@ -0,0 +1,84 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+var mapPool = sync.Pool{New: func() any { return new(OrderedMap) }}
+
+// Acquire returns an empty OrderedMap from a pool, reusing the storage of maps
+// previously returned by Release.  Use it with Release for maps that are
+// created and discarded at high rates.
+func Acquire() *OrderedMap {
+	return mapPool.Get().(*OrderedMap)
+}
+
+// Release empties o and returns it to the pool used by Acquire.  o must not be
+// used after Release.  Nested maps are not released.
+func (o *OrderedMap) Release() {
+	if o.shared {
+		// Leave the storage to the snapshot.
+		o.keys, o.vals, o.values, o.shared = nil, nil, nil, false
+	}
+	clear(o.keys)
+	clear(o.vals)
+	clear(o.values)
+	o.expires, o.onChange, o.history, o.frozen = nil, nil, nil, false
+	o.offsets, o.raws, o.layout = nil, nil, nil
+	o.keys, o.vals = o.keys[:0], o.vals[:0]
+	o.opts = nil
+	mapPool.Put(o)
+}
+
+// encodeState is the reusable state of MarshalJSON.
+type encodeState struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// maxPooledBuffer is the largest buffer capacity kept for reuse, so that an
+// occasional large document does not stay allocated.
+const maxPooledBuffer = 64 << 10
+
+var encodePool = sync.Pool{New: func() any {
+	e := new(encodeState)
+	e.enc = json.NewEncoder(&e.buf)
+	e.enc.SetEscapeHTML(false)
+	return e
+}}
+
+func getEncodeState() *encodeState {
+	e := encodePool.Get().(*encodeState)
+	e.buf.Reset()
+	return e
+}
+
+func putEncodeState(e *encodeState) {
+	if e.buf.Cap() <= maxPooledBuffer {
+		encodePool.Put(e)
+	}
+}
@@ -0,0 +1,66 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		o := Acquire()
+		if o.Len() != 0 || o.Get("a") != nil {
+			t.Fatal("Acquire returned a non-empty map", o.Keys())
+		}
+		if err := json.Unmarshal([]byte(`{"a":1,"b":{"c":2}}`), o); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < smallSize*i; j++ {
+			o.Set(string(rune('A'+j)), j)
+		}
+		b, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 && string(b) != `{"a":1,"b":{"c":2}}` {
+			t.Error("Marshal", string(b))
+		}
+		o.Release()
+	}
+}
+
+func BenchmarkAcquireRelease(b *testing.B) {
+	src := []byte(`{"alg":"ES256","iat":1623132000,"tmb":"cLj8vsYtMBwYkzoFVZHBZo6SNL8wSdCIjCKAwXNuhOk","typ":"cyphr.me/msg"}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		o := Acquire()
+		if err := o.UnmarshalJSON(src); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := o.MarshalJSON(); err != nil {
+			b.Fatal(err)
+		}
+		o.Release()
+	}
+}
@@ -0,0 +1,111 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"iter"
+	"strings"
+)
+
+// PrefixRange returns an iterator over the key-value pairs whose keys begin
+// with prefix, in order.  Keys are matched case-sensitively.  o must not be
+// modified during iteration, except by setting existing keys.
+func (o *OrderedMap) PrefixRange(prefix string) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for i, k := range o.keys {
+			if strings.HasPrefix(k, prefix) && !yield(k, o.valueAt(i)) {
+				return
+			}
+		}
+	}
+}
+
+// DeletePrefix deletes the keys that begin with prefix, in a single pass, and
+// returns the number deleted.
+func (o *OrderedMap) DeletePrefix(prefix string) int {
+	if o.observed() || o.expires != nil {
+		// Delete reports each key.
+		var keys []string
+		for k := range o.PrefixRange(prefix) {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			o.Delete(k)
+		}
+		return len(keys)
+	}
+	o.own()
+	n := 0
+	for i, k := range o.keys {
+		if strings.HasPrefix(k, prefix) {
+			if o.values != nil {
+				delete(o.values, o.mapKey(k))
+			}
+			continue
+		}
+		o.keys[n] = k
+		if o.values == nil {
+			o.vals[n] = o.vals[i]
+		}
+		n++
+	}
+	deleted := len(o.keys) - n
+	clear(o.keys[n:])
+	o.keys = o.keys[:n]
+	if o.values == nil {
+		clear(o.vals[n:])
+		o.vals = o.vals[:n]
+	}
+	return deleted
+}
+
+// PrefixRange returns an iterator over the key-value pairs whose keys begin
+// with prefix, in order.  With the default lexicographic order it is
+// O(log n) plus the number of matches, as the keys are contiguous.
+func (s *SortedMap) PrefixRange(prefix string) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		hasPrefix := func(k string) bool { return strings.HasPrefix(k, prefix) }
+		if s.less == nil {
+			s.root.ascend(prefix, hasPrefix, true, s.lessFunc(), yield)
+			return
+		}
+		for k, v := range s.All() {
+			if hasPrefix(k) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// DeletePrefix deletes the keys that begin with prefix and returns the number
+// deleted.
+func (s *SortedMap) DeletePrefix(prefix string) int {
+	var keys []string
+	for k := range s.PrefixRange(prefix) {
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		s.Delete(k)
+	}
+	return len(keys)
+}
@@ -0,0 +1,83 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestPrefix(t *testing.T) {
+	for _, n := range []int{2, smallSize} {
+		o := New()
+		s := NewSorted(nil)
+		var want []string
+		for i := 0; i < n; i++ {
+			for _, k := range []string{"payload." + strconv.Itoa(i), "header." + strconv.Itoa(i)} {
+				o.Set(k, i)
+				s.Set(k, i)
+			}
+			want = append(want, "payload."+strconv.Itoa(i))
+		}
+
+		var got []string
+		for k, v := range o.PrefixRange("payload.") {
+			if v != o.Get(k) {
+				t.Error("PrefixRange value", k, v)
+			}
+			got = append(got, k)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("n %d: PrefixRange %v", n, got)
+		}
+		got = nil
+		for k := range s.PrefixRange("payload.") {
+			got = append(got, k)
+		}
+		want = slices.Sorted(slices.Values(want))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("n %d: SortedMap PrefixRange %v", n, got)
+		}
+
+		if d := o.DeletePrefix("header."); d != n || o.Len() != n || o.Get("payload.0") != 0 {
+			t.Errorf("n %d: DeletePrefix %d %v", n, d, o.Keys())
+		}
+		if d := s.DeletePrefix("payload."); d != n || s.Len() != n {
+			t.Errorf("n %d: SortedMap DeletePrefix %d %v", n, d, s.Keys())
+		}
+	}
+
+	// Observed maps report each deleted key.
+	o := New()
+	o.Set("a.1", 1)
+	o.Set("b", 2)
+	o.Set("a.2", 3)
+	var deleted []string
+	o.OnChange(func(op Op, key string, old, new any) { deleted = append(deleted, key) })
+	o.DeletePrefix("a.")
+	if !reflect.DeepEqual(deleted, []string{"a.1", "a.2"}) || !reflect.DeepEqual(o.Keys(), []string{"b"}) {
+		t.Error("observed DeletePrefix", deleted, o.Keys())
+	}
+}
@@ -0,0 +1,164 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+)
+
+// PriorityMap is an ordered map in which each key has a priority, and keys are
+// ordered by ascending priority, then by insertion.  Keys have priority 0
+// unless set otherwise, so a map without priorities is in insertion order.  It
+// suits explicitly weighted content, such as the sections of a settings page.
+// The zero value is an empty map ready to use.  A PriorityMap is not safe for
+// concurrent use.
+type PriorityMap struct {
+	m     OrderedMap
+	ranks map[string]rank
+	// seq counts insertions, for the order of keys of equal priority.
+	seq int
+}
+
+// rank is the position of a key in a PriorityMap.
+type rank struct {
+	priority, seq int
+}
+
+func (r rank) less(s rank) bool {
+	return r.priority < s.priority || r.priority == s.priority && r.seq < s.seq
+}
+
+// NewPriorityMap returns an empty PriorityMap.
+func NewPriorityMap() *PriorityMap {
+	return new(PriorityMap)
+}
+
+// Set sets the value for key.  A new key has priority 0 and follows the keys
+// of priority 0 or less.
+func (p *PriorityMap) Set(key string, value any) {
+	if _, ok := p.ranks[key]; ok {
+		p.m.Set(key, value)
+		return
+	}
+	p.SetWithPriority(key, value, 0)
+}
+
+// SetWithPriority sets the value and priority of key.
+func (p *PriorityMap) SetWithPriority(key string, value any, priority int) {
+	if p.ranks == nil {
+		p.ranks = make(map[string]rank)
+	}
+	r, ok := p.ranks[key]
+	if !ok {
+		p.seq++
+		r.seq = p.seq
+	}
+	r.priority = priority
+	p.ranks[key] = r
+	p.m.Set(key, value)
+	p.place(key)
+}
+
+// SetPriority sets the priority of key, moving it among keys of other
+// priorities.  Among keys of equal priority it keeps its insertion order.  It
+// returns an error wrapping ErrKeyNotFound if key does not exist.
+func (p *PriorityMap) SetPriority(key string, priority int) error {
+	r, ok := p.ranks[key]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+	r.priority = priority
+	p.ranks[key] = r
+	p.place(key)
+	return nil
+}
+
+// place moves key to its position by rank.
+func (p *PriorityMap) place(key string) {
+	r := p.ranks[key]
+	pos := 0
+	for _, k := range p.m.keys {
+		if k != key && p.ranks[k].less(r) {
+			pos++
+		}
+	}
+	p.m.Move(key, pos)
+}
+
+// Priority returns the priority of key and whether it exists.
+func (p *PriorityMap) Priority(key string) (int, bool) {
+	r, ok := p.ranks[key]
+	return r.priority, ok
+}
+
+// Get returns the value for key and whether it exists.
+func (p *PriorityMap) Get(key string) (any, bool) {
+	return p.m.get(key)
+}
+
+// Delete deletes key, if it exists.
+func (p *PriorityMap) Delete(key string) {
+	delete(p.ranks, key)
+	p.m.Delete(key)
+}
+
+// Len returns the number of keys.
+func (p *PriorityMap) Len() int {
+	return p.m.Len()
+}
+
+// Keys returns the keys in order.  The slice must not be modified.
+func (p *PriorityMap) Keys() []string {
+	return p.m.Keys()
+}
+
+// All returns an iterator over the key-value pairs in order.
+func (p *PriorityMap) All() iter.Seq2[string, any] {
+	return p.m.All()
+}
+
+// MarshalJSON encodes p as a JSON object in order.  Priorities are not
+// encoded.
+func (p PriorityMap) MarshalJSON() ([]byte, error) {
+	return p.m.MarshalJSON()
+}
+
+// UnmarshalJSON replaces the contents of p with the JSON object b, with keys
+// of priority 0 in input order.  On error p is not modified.  By convention,
+// null is a no-op.
+func (p *PriorityMap) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return nil
+	}
+	m := New()
+	if err := m.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	*p = PriorityMap{}
+	for k, v := range m.All() {
+		p.Set(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPriorityMap(t *testing.T) {
+	var p PriorityMap
+	p.Set("general", 1)
+	p.SetWithPriority("advanced", 2, 10)
+	p.Set("display", 3)
+	p.SetWithPriority("account", 4, -1)
+	want := []string{"account", "general", "display", "advanced"}
+	if !slices.Equal(p.Keys(), want) {
+		t.Errorf("Keys = %v, want %v", p.Keys(), want)
+	}
+
+	p.SetPriority("general", 10) // ties keep insertion order
+	p.Set("display", 5)          // existing keys keep their position
+	want = []string{"account", "display", "general", "advanced"}
+	if !slices.Equal(p.Keys(), want) {
+		t.Errorf("Keys = %v, want %v", p.Keys(), want)
+	}
+	if pr, ok := p.Priority("general"); !ok || pr != 10 {
+		t.Errorf("Priority = %d, %v", pr, ok)
+	}
+	if err := p.SetPriority("x", 1); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("got %v", err)
+	}
+	p.Delete("account")
+	if b, _ := p.MarshalJSON(); string(b) != `{"display":5,"general":1,"advanced":2}` {
+		t.Errorf("got %s", b)
+	}
+
+	if err := p.UnmarshalJSON([]byte(`{"b":1,"a":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	p.SetPriority("b", 1)
+	if !slices.Equal(p.Keys(), []string{"a", "b"}) {
+		t.Errorf("Keys = %v", p.Keys())
+	}
+	if err := p.UnmarshalJSON([]byte("null")); err != nil || p.Len() != 2 {
+		t.Errorf("null: %v, Len %d", err, p.Len())
+	}
+}
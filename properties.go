@@ -0,0 +1,225 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// MarshalProperties encodes o as a Java .properties file of key=value lines,
+// in depth-first order, with the keys of nested maps joined by ".", such as
+// "a.b=value" for key b of nested map a.  Values are formatted like
+// ToCSVRecord.  Keys and values are escaped for java.util.Properties.load,
+// with characters beyond ASCII as \uXXXX escapes.  Keys giving the same
+// dotted key are rejected with an error wrapping ErrKeyCollision.
+func (o OrderedMap) MarshalProperties() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeProperties(&buf, &o, "", make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeProperties(buf *bytes.Buffer, o *OrderedMap, prefix string, seen map[string]bool) error {
+	for i, k := range o.keys {
+		v := o.valueAt(i)
+		key := prefix + k
+		if m, ok := asMap(v); ok && m.Len() > 0 {
+			if err := writeProperties(buf, m, key+".", seen); err != nil {
+				return err
+			}
+			continue
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: %q", ErrKeyCollision, key)
+		}
+		seen[key] = true
+		s, err := csvField(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(escapeProperty(key, true))
+		buf.WriteByte('=')
+		buf.WriteString(escapeProperty(s, false))
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// escapeProperty escapes s as a key, if key, or a value of a .properties file.
+func escapeProperty(s string, key bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			b.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r), i == 0 && (r == '#' || r == '!'):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7E:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&b, `\u%04X`, u)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// UnmarshalProperties replaces the contents of o with the Java .properties
+// file b, splitting keys at "." into nested maps, reversing
+// MarshalProperties.  Values are strings.  It follows
+// java.util.Properties.load: comment lines beginning with '#' or '!', lines
+// continued by a trailing '\', keys ending at the first unescaped '=', ':' or
+// white space, and escapes including \uXXXX.  Repeated keys are rejected with
+// an error wrapping ErrJSONDuplicate, and a key that is both a value and a
+// prefix of another with an error wrapping ErrKeyCollision.  On error o is not
+// modified.
+func (o *OrderedMap) UnmarshalProperties(b []byte) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	flat := o.empty()
+	lines := strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		lineNum := n + 1
+		l := strings.TrimLeft(lines[n], " \t\f")
+		if l == "" || l[0] == '#' || l[0] == '!' {
+			continue
+		}
+		// Join continued lines, which end in an odd number of backslashes.
+		for continued(l) && n+1 < len(lines) {
+			n++
+			l = l[:len(l)-1] + strings.TrimLeft(lines[n], " \t\f")
+		}
+		k, v, err := splitProperty(l)
+		if err != nil {
+			return fmt.Errorf("orderedmap: properties line %d: %w", lineNum, err)
+		}
+		if _, dup := flat.get(k); dup {
+			return fmt.Errorf("%w %q at properties line %d", ErrJSONDuplicate, k, lineNum)
+		}
+		flat.set(k, v)
+	}
+	u, err := flat.Unflatten(".")
+	if err != nil {
+		return err
+	}
+	return o.replace(func() error {
+		o.keys, o.vals, o.values = u.keys, u.vals, u.values
+		return nil
+	})
+}
+
+// continued reports whether l ends in an odd number of backslashes.
+func continued(l string) bool {
+	n := len(l) - len(strings.TrimRight(l, `\`))
+	return n%2 == 1
+}
+
+// splitProperty returns the unescaped key and value of the logical line l.
+func splitProperty(l string) (string, string, error) {
+	end := len(l)
+	for i := 0; i < len(l); i++ {
+		if l[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", l[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	key, rest := l[:end], strings.TrimLeft(l[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	k, err := unescapeProperty(key)
+	if err != nil {
+		return "", "", err
+	}
+	v, err := unescapeProperty(rest)
+	return k, v, err
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	var units []uint16
+	flush := func() {
+		b.WriteString(string(utf16.Decode(units)))
+		units = units[:0]
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			flush()
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if s[i] == 'u' {
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uXXXX escape")
+			}
+			u, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uXXXX escape")
+			}
+			units = append(units, uint16(u))
+			i += 4
+			continue
+		}
+		flush()
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	flush()
+	return b.String(), nil
+}
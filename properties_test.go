@@ -0,0 +1,90 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMarshalProperties(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"server":{"port":8080,"host":"h"},"greeting":" Grüße 😀","a key:x":"#v\\n","on":true}`)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := o.MarshalProperties()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "server.port=8080\nserver.host=h\n" +
+		`greeting=\ Gr\u00FC\u00DFe \uD83D\uDE00` + "\n" +
+		`a\ key\:x=\#v\\n` + "\n" +
+		"on=true\n"
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+
+	m := New()
+	if err := m.UnmarshalProperties(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.String(), `{"server":{"port":"8080","host":"h"},"greeting":" Grüße 😀","a key:x":"#v\\n","on":"true"}`; got != want {
+		t.Errorf("round trip %s, want %s", got, want)
+	}
+
+	o.Set("server.port", 1)
+	if _, err := o.MarshalProperties(); !errors.Is(err, ErrKeyCollision) {
+		t.Error("collision:", err)
+	}
+}
+
+func TestUnmarshalProperties(t *testing.T) {
+	const file = "# comment\n" +
+		"  ! also a comment\n" +
+		"a.b = 1\n" +
+		"a.c:2\n" +
+		"d e\n" +
+		"long = one, \\\n" +
+		"       two\n" +
+		"tab\\tkey=\\u0041\\\\\n" +
+		"empty\n" +
+		"x==y\r\n"
+	o := New()
+	if err := o.UnmarshalProperties([]byte(file)); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":{"b":"1","c":"2"},"d":"e","long":"one, two","tab\tkey":"A\\","empty":"","x":"=y"}`
+	if got := o.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	for _, s := range []string{"a=1\na=2", "a=1\na.b=2", `a=\u12`} {
+		err := New().UnmarshalProperties([]byte(s))
+		if err == nil {
+			t.Errorf("no error for %q", s)
+		}
+	}
+	if err := New().UnmarshalProperties([]byte("a=1\na=2")); !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("duplicate:", err)
+	}
+}
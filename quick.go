@@ -0,0 +1,81 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+)
+
+// Generate implements testing/quick.Generator, so that quick.Check can generate
+// *OrderedMap arguments.  It returns a random map of up to size keys, with
+// values as decoded by UnmarshalJSON nested up to 3 deep, whose numbers and
+// strings round trip through JSON.  Each level of nesting halves the size.
+// The receiver is not used.  See package maptest for more control.
+func (*OrderedMap) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(generateMap(r, size, 3))
+}
+
+func generateMap(r *rand.Rand, size, depth int) *OrderedMap {
+	o := New()
+	for range r.Intn(size + 1) {
+		o.Set(generateString(r), generateValue(r, size/2, depth-1))
+	}
+	return o
+}
+
+func generateValue(r *rand.Rand, size, depth int) any {
+	kinds := 6
+	if depth <= 0 {
+		kinds = 4 // only scalars
+	}
+	switch r.Intn(kinds) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(r.NormFloat64()*1e3, 'g', 6, 64), 64)
+		return f
+	case 3:
+		return generateString(r)
+	case 4:
+		s := make([]any, r.Intn(size+1))
+		for i := range s {
+			s[i] = generateValue(r, size/2, depth-1)
+		}
+		return s
+	}
+	return *generateMap(r, size, depth)
+}
+
+func generateString(r *rand.Rand) string {
+	const chars = "abcxyz019 _\"\\/\n\u0000é€😀<&"
+	rs := []rune(chars)
+	s := make([]rune, r.Intn(6))
+	for i := range s {
+		s[i] = rs[r.Intn(len(rs))]
+	}
+	return string(s)
+}
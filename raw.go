@@ -0,0 +1,113 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// KeepRaw makes UnmarshalJSON keep the input of each value, at any depth, for
+// RawValue.  MarshalJSON writes the kept input of values other than maps, so
+// that unchanged values keep their number formatting and string escaping, and
+// maps are written member by member.  Setting or deleting a key discards its
+// input.  Modifications of slices in place, other than by Walk, are not
+// detected, so set the key again after modifying one.  With Lenient, the
+// input kept is its conversion to strict JSON.
+func KeepRaw() Option {
+	return func(opts *options) { opts.raw = true }
+}
+
+// span is the input of a value, at offsets start to end of the input during
+// decoding and then b, or if cached is set, the encoding b of the member key
+// cached by CacheEncoding.
+type span struct {
+	start, end int64
+	b          []byte
+	key        string
+	cached     bool
+}
+
+// RawValue returns the input of the value of key as decoded by UnmarshalJSON
+// with KeepRaw, and false if key is not set, has been set since, or was not
+// decoded with KeepRaw.  The input of a map does not reflect changes to the
+// map.  The returned slice must not be modified.
+func (o *OrderedMap) RawValue(key string) ([]byte, bool) {
+	r, ok := o.raws[o.mapKey(key)]
+	if !ok || r.b == nil || r.cached || o.index(key) < 0 {
+		return nil, false
+	}
+	return r.b, true
+}
+
+// rawValue returns the kept input, if any, to be written by MarshalJSON for
+// the value v of key.
+func (o *OrderedMap) rawValue(key string, v any) ([]byte, bool) {
+	if o.raws == nil {
+		return nil, false
+	}
+	if _, isMap := asMap(v); isMap {
+		return nil, false
+	}
+	r, ok := o.raws[o.mapKey(key)]
+	return r.b, ok && r.b != nil && !r.cached
+}
+
+// recordRaw records the input offsets of the value of key, if o keeps raw
+// input.  prev is the offset following the previous member, and start the
+// offset following the key.  The input is set by resolveRaws once decoding is
+// done.
+func (o *OrderedMap) recordRaw(key string, prev, start, end int64) {
+	if o.opts == nil || !o.opts.raw {
+		return
+	}
+	o.recordMember(key, prev, start, end)
+	if o.raws == nil {
+		o.raws = make(map[string]span)
+	}
+	o.raws[o.mapKey(key)] = span{start: start, end: end}
+}
+
+// resolveRaws sets the input of the recorded values of o and its nested maps
+// from src.
+func (o *OrderedMap) resolveRaws(src []byte) {
+	for k, r := range o.raws {
+		if r.cached {
+			continue
+		}
+		for r.start < r.end && (isSpace(src[r.start]) || src[r.start] == ':') {
+			r.start++
+		}
+		r.b = src[r.start:r.end:r.end]
+		o.raws[k] = r
+	}
+	o.resolveLayout(src)
+	for i := range o.keys {
+		resolveValueRaws(o.rawAt(i), src)
+	}
+}
+
+func resolveValueRaws(v any, src []byte) {
+	if m, ok := asMap(v); ok {
+		m.resolveRaws(src)
+	} else if s, ok := asSlice(v); ok {
+		for _, e := range s {
+			resolveValueRaws(e, src)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "testing"
+
+func TestKeepRaw(t *testing.T) {
+	in := `{"n":1.0,"e":1E2,"s":"é\/","a":[1.50, 2],"m":{"x":10.00,"y":"A"}}`
+	o := New(KeepRaw())
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := o.RawValue("a"); !ok || string(raw) != `[1.50, 2]` {
+		t.Errorf("RawValue(a) = %s, %v", raw, ok)
+	}
+	if raw, ok := o.RawValue("m"); !ok || string(raw) != `{"x":10.00,"y":"A"}` {
+		t.Errorf("RawValue(m) = %s, %v", raw, ok)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"n":1.0,"e":1E2,"s":"é\/","a":[1.50, 2],"m":{"x":10.00,"y":"A"}}` {
+		t.Errorf("got %s", b)
+	}
+
+	snap := o.Snapshot()
+	o.Set("n", 1.0)
+	m := o.Get("m").(OrderedMap)
+	m.Set("z", true)
+	o.Set("m", m)
+	want := `{"n":1,"e":1E2,"s":"é\/","a":[1.50, 2],"m":{"x":10.00,"y":"A","z":true}}`
+	if b, _ := o.MarshalJSON(); string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	if _, ok := o.RawValue("n"); ok {
+		t.Error("RawValue of set key")
+	}
+	if raw, _ := snap.RawValue("n"); string(raw) != "1.0" {
+		t.Errorf("snapshot RawValue(n) = %s", raw)
+	}
+	o.Walk(func(path []string, key string, value any) error {
+		if value == 2.0 {
+			return Replace(3)
+		}
+		return nil
+	})
+	if b, _ := o.MarshalJSON(); string(b) != `{"n":1,"e":1E2,"s":"é\/","a":[1.5,3],"m":{"x":10.00,"y":"A","z":true}}` {
+		t.Errorf("after Walk got %s", b)
+	}
+	o.Delete("e")
+	o.Set("e", 2)
+	if _, ok := o.RawValue("e"); ok {
+		t.Error("RawValue of deleted key")
+	}
+
+	o = New(KeepRaw())
+	o.EnableHistory(0)
+	o.UnmarshalJSON([]byte(`{"a":1.0}`))
+	o.UnmarshalJSON([]byte(`{"a":2.0}`))
+	o.Undo()
+	if b, _ := o.MarshalJSON(); string(b) != `{"a":1.0}` {
+		t.Errorf("after Undo got %s", b)
+	}
+
+	o = New(KeepRaw(), Lenient())
+	if err := o.UnmarshalJSON([]byte(`{a: 1.0, /* c */ b: 'x',}`)); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"a":1.0,"b":"x"}` {
+		t.Errorf("lenient got %s", b)
+	}
+
+	o = New(KeepRaw())
+	if err := o.UnmarshalJSONKeys([]byte(`{"a":{"b":1.0},"c":2.0}`), "c"); err != nil {
+		t.Fatal(err)
+	}
+	a := o.Get("a").(OrderedMap)
+	if raw, ok := a.RawValue("b"); ok {
+		t.Errorf("RawValue of lazily parsed value: %s", raw)
+	}
+}
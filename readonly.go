@@ -0,0 +1,157 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "iter"
+
+// ReadOnlyMap is a view of an OrderedMap, returned by ReadOnly, with only its
+// accessor methods, so that ordered data can be handed out without copying
+// it.  Values that are maps are returned as ReadOnlyMap views too.  Other
+// values, such as []any, are shared with the map and must not be modified.
+type ReadOnlyMap interface {
+	Getter
+	// Keys of OrderedIterator returns a copy.
+	OrderedIterator
+	// Lookup returns the value for key and whether it exists.
+	Lookup(key string) (any, bool)
+	// GetKeyAt returns the key at position pos, panicking if it is out of
+	// range.
+	GetKeyAt(pos int) string
+	// GetValueAt returns the value at position pos, panicking if it is out of
+	// range.
+	GetValueAt(pos int) any
+	// ToMap returns a copy of the contents as plain Go maps.
+	ToMap() map[string]any
+	// Snapshot returns a modifiable copy that is cheap until either is
+	// modified.  See OrderedMap.Snapshot.
+	Snapshot() *OrderedMap
+	MarshalJSON() ([]byte, error)
+}
+
+// ReadOnly returns a read-only view of o.  The view reflects later
+// modifications of o, and like o is not safe for concurrent use with them.
+// Reads through the view do not modify o, so they may be concurrent: expired
+// keys are missing without being deleted, and lazy values are parsed on each
+// read without being stored.  MarshalJSON with CacheEncoding and Snapshot
+// are the exceptions, as they update the cache and mark the storage shared.
+func (o *OrderedMap) ReadOnly() ReadOnlyMap {
+	return readOnly{o}
+}
+
+type readOnly struct {
+	o *OrderedMap
+}
+
+// readOnlyValue returns v with a map replaced by its read-only view.
+func readOnlyValue(v any) any {
+	switch m := v.(type) {
+	case OrderedMap:
+		// The copy shares storage with the stored value, which is never
+		// modified through the view.
+		return readOnly{&m}
+	case *OrderedMap:
+		if m != nil {
+			return readOnly{m}
+		}
+	}
+	return v
+}
+
+func (r readOnly) Len() int {
+	return r.o.Len()
+}
+
+func (r readOnly) Get(key string) any {
+	if m := r.o.metrics(); m != nil {
+		m.Gets.Add(1)
+	}
+	v, _ := r.o.peek(key)
+	return readOnlyValue(v)
+}
+
+func (r readOnly) Lookup(key string) (any, bool) {
+	v, ok := r.o.peek(key)
+	return readOnlyValue(v), ok
+}
+
+func (r readOnly) GetKeyAt(pos int) string {
+	return r.o.GetKeyAt(pos)
+}
+
+func (r readOnly) GetValueAt(pos int) any {
+	return readOnlyValue(r.o.peekAt(pos))
+}
+
+func (r readOnly) Keys() []string {
+	return r.o.KeysCopy()
+}
+
+func (r readOnly) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		o := r.o
+		if o.shuffles() {
+			for _, i := range o.opts.shuffle.perm(len(o.keys)) {
+				if !yield(o.keys[i], readOnlyValue(o.peekAt(i))) {
+					return
+				}
+			}
+			return
+		}
+		for i, k := range o.keys {
+			if !yield(k, readOnlyValue(o.peekAt(i))) {
+				return
+			}
+		}
+	}
+}
+
+func (r readOnly) ToMap() map[string]any {
+	m := make(map[string]any, len(r.o.keys))
+	for i, k := range r.o.keys {
+		m[k] = plainView(r.o.peekAt(i))
+	}
+	return m
+}
+
+// plainView is toPlain for a value read through a view.
+func plainView(v any) any {
+	if m, ok := asMap(v); ok {
+		return readOnly{m}.ToMap()
+	}
+	if s, ok := asSlice(v); ok {
+		c := make([]any, len(s))
+		for i, e := range s {
+			c[i] = plainView(e)
+		}
+		return c
+	}
+	return v
+}
+
+func (r readOnly) Snapshot() *OrderedMap {
+	return r.o.Snapshot()
+}
+
+func (r readOnly) MarshalJSON() ([]byte, error) {
+	return r.o.MarshalJSON()
+}
@@ -0,0 +1,125 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadOnly(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"b":{"c":1},"a":[1]}`)); err != nil {
+		t.Fatal(err)
+	}
+	r := o.ReadOnly()
+	if _, ok := r.(interface{ Set(string, any) error }); ok {
+		t.Error("view has Set")
+	}
+	nested, ok := r.Get("b").(ReadOnlyMap)
+	if !ok || nested.Get("c") != 1.0 {
+		t.Fatalf("nested = %#v", r.Get("b"))
+	}
+	keys := r.Keys()
+	keys[0] = "x"
+	if o.Keys()[0] != "b" {
+		t.Error("Keys is not a copy")
+	}
+
+	o.Set("d", true)
+	if r.Len() != 3 || r.GetKeyAt(2) != "d" || r.GetValueAt(2) != true {
+		t.Error("view does not reflect modification")
+	}
+	if v, ok := r.Lookup("x"); ok || v != nil {
+		t.Error("Lookup of missing key")
+	}
+	var got []string
+	for k, v := range r.All() {
+		if _, isMap := asMap(v); isMap {
+			t.Errorf("All yields map %s", k)
+		}
+		got = append(got, k)
+	}
+	if !slices.Equal(got, []string{"b", "a", "d"}) {
+		t.Errorf("All = %v", got)
+	}
+
+	s := r.Snapshot()
+	s.Set("e", 1)
+	if r.Len() != 3 {
+		t.Error("Snapshot modified the map")
+	}
+	b, err := json.Marshal(r)
+	if err != nil || string(b) != `{"b":{"c":1},"a":[1],"d":true}` {
+		t.Errorf("got %s, %v", b, err)
+	}
+	if b, _ := json.Marshal(nested); string(b) != `{"c":1}` {
+		t.Errorf("nested got %s", b)
+	}
+}
+
+// TestReadOnly_Concurrent is meant for -race: reads through a view do not
+// delete expired keys or store parsed lazy values.
+func TestReadOnly_Concurrent(t *testing.T) {
+	clock := time.Unix(0, 0)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	o := New(Lazy())
+	if err := o.UnmarshalJSON([]byte(`{"a":{"b":[1,2]},"c":[{"d":3}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.SetWithTTL("t", 1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(time.Hour)
+
+	r := o.ReadOnly()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := r.Lookup("t"); ok || v != nil || r.Get("t") != nil {
+				t.Error("expired key visible", v)
+			}
+			if a, ok := r.Get("a").(ReadOnlyMap); !ok || a.Len() != 1 {
+				t.Errorf("a = %#v", r.Get("a"))
+			}
+			for range r.All() {
+			}
+			r.ToMap()
+			r.GetValueAt(1)
+		}()
+	}
+	wg.Wait()
+	if o.Len() != 3 {
+		t.Error("expired key deleted", o.Keys())
+	}
+	if _, ok := o.rawAt(0).(lazyValue); !ok {
+		t.Errorf("lazy value stored: %T", o.rawAt(0))
+	}
+}
@@ -0,0 +1,126 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"path"
+	"strings"
+)
+
+// Redact makes MarshalJSON encode replacement in place of the values of the
+// keys matching any of keys, at any depth, without modifying the map, such as
+// for logging payloads holding tokens.  Each of keys is a "."-separated path
+// from the map, such as "auth.token", of path.Match patterns, such as
+// "x-*-secret", or "**", which matches any number of keys: "**.password"
+// matches "password" at any depth.  Maps in arrays have the path of the array.
+//
+// Redacted maps do not keep the formatting of Faithful or the input of KeepRaw.
+func Redact(keys []string, replacement any) Option {
+	r := &redaction{replacement: replacement}
+	for _, k := range keys {
+		r.patterns = append(r.patterns, strings.Split(k, "."))
+	}
+	return func(opts *options) { opts.redact = r }
+}
+
+// redaction is the configuration of Redact.
+type redaction struct {
+	patterns    [][]string
+	replacement any
+}
+
+// redacts reports whether MarshalJSON of o redacts values.
+func (o *OrderedMap) redacts() bool {
+	return o.opts != nil && o.opts.redact != nil
+}
+
+// marshalRedacted is MarshalJSON of a map configured with Redact.
+func (o *OrderedMap) marshalRedacted() ([]byte, error) {
+	r := o.opts.redact
+	opts := o.opts.clone()
+	opts.redact = nil
+	return redactMap(o, r, opts, nil).MarshalJSON()
+}
+
+// redactMap returns a copy of o at path p with the options opts, with the
+// values of matching keys replaced.
+func redactMap(o *OrderedMap, r *redaction, opts *options, p []string) *OrderedMap {
+	m := &OrderedMap{opts: opts}
+	m.reserveKeys(len(o.keys))
+	for i, k := range o.keys {
+		kp := append(p[:len(p):len(p)], k)
+		if r.matches(kp) {
+			m.set(k, r.replacement)
+			continue
+		}
+		m.set(k, redactValue(o.valueAt(i), r, opts, kp))
+	}
+	return m
+}
+
+func redactValue(v any, r *redaction, opts *options, p []string) any {
+	if n, ok := asMap(v); ok {
+		return redactMap(n, r, opts, p)
+	}
+	a, ok := asSlice(v)
+	if !ok {
+		return v
+	}
+	c := make([]any, len(a))
+	for i, e := range a {
+		c[i] = redactValue(e, r, opts, p)
+	}
+	if _, ok := v.(OrderedArray); ok {
+		return OrderedArray(c)
+	}
+	return c
+}
+
+// matches reports whether any pattern matches the keys of p.
+func (r *redaction) matches(p []string) bool {
+	for _, pat := range r.patterns {
+		if matchPath(pat, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(pat, p []string) bool {
+	if len(pat) == 0 {
+		return len(p) == 0
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(p); i++ {
+			if matchPath(pat[1:], p[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(p) == 0 {
+		return false
+	}
+	ok, err := path.Match(pat[0], p[0])
+	return ok && err == nil && matchPath(pat[1:], p[1:])
+}
@@ -0,0 +1,89 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const redactInput = `{"user":"bob","token":"t1","auth":{"token":"t2","password":"p1"},` +
+	`"items":[{"password":"p2","id":1}],"x-api-secret":"s1","deep":{"a":{"password":"p3"}}}`
+
+func TestRedact(t *testing.T) {
+	for _, tt := range []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"token"}, `{"user":"bob","token":"***","auth":{"token":"t2","password":"p1"},` +
+			`"items":[{"password":"p2","id":1}],"x-api-secret":"s1","deep":{"a":{"password":"p3"}}}`},
+		{[]string{"auth.token", "x-*-secret"}, `{"user":"bob","token":"t1","auth":{"token":"***","password":"p1"},` +
+			`"items":[{"password":"p2","id":1}],"x-api-secret":"***","deep":{"a":{"password":"p3"}}}`},
+		{[]string{"**.password", "*.token"}, `{"user":"bob","token":"t1","auth":{"token":"***","password":"***"},` +
+			`"items":[{"password":"***","id":1}],"x-api-secret":"s1","deep":{"a":{"password":"***"}}}`},
+		{[]string{"auth"}, `{"user":"bob","token":"t1","auth":"***",` +
+			`"items":[{"password":"p2","id":1}],"x-api-secret":"s1","deep":{"a":{"password":"p3"}}}`},
+	} {
+		o := New(Redact(tt.keys, "***"), KeepRaw())
+		if err := o.UnmarshalJSON([]byte(redactInput)); err != nil {
+			t.Fatal(err)
+		}
+		b, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("%v:\ngot  %s\nwant %s", tt.keys, b, tt.want)
+		}
+		// Through encoding/json, nested in another value.
+		b, err = json.Marshal(map[string]any{"m": o})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"m":` + tt.want + `}`; string(b) != want {
+			t.Errorf("%v: encoding/json got %s", tt.keys, b)
+		}
+	}
+}
+
+func TestRedactNotModified(t *testing.T) {
+	o := New(Redact([]string{"**.password"}, nil))
+	if err := o.UnmarshalJSON([]byte(redactInput)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.MarshalJSON(); err != nil {
+		t.Fatal(err)
+	}
+	auth := o.Get("auth").(OrderedMap)
+	if auth.Get("password") != "p1" {
+		t.Error("map modified", auth.Get("password"))
+	}
+	// A nested map marshals with paths from itself.
+	b, err := auth.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"token":"t2","password":null}` {
+		t.Error(string(b))
+	}
+}
@@ -0,0 +1,165 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// ErrRefCycle is returned by ResolveRefs for a $ref that refers to itself,
+// directly or through other references.
+var ErrRefCycle = errors.New("orderedmap: $ref cycle")
+
+// ResolveRefs replaces each map among the values of o, at any depth, that has
+// a "$ref" member with a string value, such as in OpenAPI and JSON Schema
+// documents, by the document loader returns for the reference.  The other
+// members of the map, if any, are then set on the document, in their order,
+// after its members.  References in loaded documents are resolved too.  Maps
+// keep their position, so the order of o is preserved.
+//
+// Modified nested maps are replaced by copies, so the documents returned by
+// loader, which may be cached, are not modified.  Errors of loader are
+// wrapped with the path of the reference.  Values are set as by TrySet, so a
+// validator may reject them, and a frozen o returns ErrFrozen.  On error o is
+// partially resolved.
+func (o *OrderedMap) ResolveRefs(loader func(ref string) (*OrderedMap, error)) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	r := &refResolver{loader: loader}
+	for i, k := range o.keys {
+		v, changed, err := r.resolve(o.valueAt(i), k)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if err := o.store(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type refResolver struct {
+	loader func(ref string) (*OrderedMap, error)
+	// refs are the references being resolved, for detecting cycles.
+	refs []string
+}
+
+// resolve returns v at path with its references resolved, and whether it
+// changed.
+func (r *refResolver) resolve(v any, path string) (any, bool, error) {
+	if a, ok := asSlice(v); ok {
+		var c []any
+		for i, e := range a {
+			e, changed, err := r.resolve(e, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, false, err
+			}
+			if changed && c == nil {
+				c = slices.Clone(a)
+			}
+			if changed {
+				c[i] = e
+			}
+		}
+		if c == nil {
+			return v, false, nil
+		}
+		if _, ok := v.(OrderedArray); ok {
+			return OrderedArray(c), true, nil
+		}
+		return c, true, nil
+	}
+	m, ok := asMap(v)
+	if !ok {
+		return v, false, nil
+	}
+	_, isValue := v.(OrderedMap)
+	keep := func(m *OrderedMap) any {
+		if isValue {
+			return *m
+		}
+		return m
+	}
+
+	if ref, ok := getString(m, "$ref"); ok {
+		if slices.Contains(r.refs, ref) {
+			return nil, false, fmt.Errorf("%w: %q at %s", ErrRefCycle, ref, path)
+		}
+		doc, err := r.loader(ref)
+		if err != nil {
+			return nil, false, fmt.Errorf("orderedmap: $ref %q at %s: %w", ref, path, err)
+		}
+		c := doc.Snapshot()
+		for i, k := range m.keys {
+			if k != "$ref" {
+				if err := c.store(k, m.valueAt(i)); err != nil {
+					return nil, false, err
+				}
+			}
+		}
+		r.refs = append(r.refs, ref)
+		resolved, _, err := r.resolve(c, path)
+		r.refs = r.refs[:len(r.refs)-1]
+		if err != nil {
+			return nil, false, err
+		}
+		return keep(resolved.(*OrderedMap)), true, nil
+	}
+
+	var c *OrderedMap
+	for i, k := range m.keys {
+		kp := k
+		if path != "" {
+			kp = path + "." + k
+		}
+		e, changed, err := r.resolve(m.valueAt(i), kp)
+		if err != nil {
+			return nil, false, err
+		}
+		if changed {
+			if c == nil {
+				c = m.Snapshot()
+			}
+			if err := c.store(k, e); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if c == nil {
+		return v, false, nil
+	}
+	return keep(c), true, nil
+}
+
+// getString returns the value of key in m if it is a string.
+func getString(m *OrderedMap, key string) (string, bool) {
+	v, _ := m.get(key)
+	s, ok := v.(string)
+	return s, ok
+}
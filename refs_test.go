@@ -0,0 +1,144 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const openAPI = `{
+	"paths": {"/pets": {"get": {"responses": {"200": {"$ref": "#/components/responses/Pets", "description": "override"}}}}},
+	"components": {
+		"responses": {"Pets": {"description": "pets", "content": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}},
+		"schemas": {"Pet": {"type": "object", "properties": {"name": {"type": "string"}}}}
+	}
+}`
+
+// pointerLoader resolves "#/a/b" references in root.
+func pointerLoader(root *OrderedMap, loads *int) func(ref string) (*OrderedMap, error) {
+	return func(ref string) (*OrderedMap, error) {
+		*loads++
+		m := root
+		for _, k := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			n, ok := m.Get(k).(OrderedMap)
+			if !ok {
+				return nil, fmt.Errorf("%s not found", ref)
+			}
+			m = &n
+		}
+		return m, nil
+	}
+}
+
+func TestResolveRefs(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(openAPI)); err != nil {
+		t.Fatal(err)
+	}
+	root := New()
+	if err := root.UnmarshalJSON([]byte(openAPI)); err != nil {
+		t.Fatal(err)
+	}
+	var loads int
+	if err := o.ResolveRefs(pointerLoader(root, &loads)); err != nil {
+		t.Fatal(err)
+	}
+	paths := o.Get("paths").(OrderedMap)
+	b, err := paths.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"/pets":{"get":{"responses":{"200":{"description":"override","content":{"schema":{"type":"array",` +
+		`"items":{"type":"object","properties":{"name":{"type":"string"}}}}}}}}}}`
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+	if loads != 3 {
+		t.Error("loads", loads)
+	}
+	// The loaded documents are not modified.
+	if !strings.Contains(root.String(), `"items":{"$ref":"#/components/schemas/Pet"}`) {
+		t.Error("loaded document modified", root.String())
+	}
+}
+
+func TestResolveRefsErrors(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"a":[1,{"b":{"$ref":"#/a"}}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	var loads int
+	err := o.ResolveRefs(pointerLoader(New(), &loads))
+	if err == nil || !strings.Contains(err.Error(), `$ref "#/a" at a[1].b`) {
+		t.Error(err)
+	}
+
+	cyclic := New()
+	if err := cyclic.UnmarshalJSON([]byte(`{"x":{"$ref":"#/y"},"y":{"z":{"$ref":"#/x"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	err = cyclic.Snapshot().ResolveRefs(pointerLoader(cyclic, &loads))
+	if !errors.Is(err, ErrRefCycle) {
+		t.Error("cycle:", err)
+	}
+
+	// Non-string $ref values are not references.
+	m := New()
+	if err := m.UnmarshalJSON([]byte(`{"a":{"$ref":1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ResolveRefs(nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResolveRefs_Rejected(t *testing.T) {
+	const doc = `{"a":{"$ref":"#/b"},"b":{"c":1}}`
+	var loads int
+	o := New()
+	if err := o.UnmarshalJSON([]byte(doc)); err != nil {
+		t.Fatal(err)
+	}
+	o.Freeze()
+	if err := o.ResolveRefs(pointerLoader(o, &loads)); err != ErrFrozen {
+		t.Error("frozen:", err)
+	}
+
+	errReject := errors.New("reject")
+	v := New()
+	if err := v.UnmarshalJSON([]byte(doc)); err != nil {
+		t.Fatal(err)
+	}
+	v.SetValidator(func(key string, value any) error {
+		if key == "a" {
+			return errReject
+		}
+		return nil
+	})
+	if err := v.ResolveRefs(pointerLoader(v.Snapshot(), &loads)); err != errReject {
+		t.Error("validator:", err)
+	}
+}
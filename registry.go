@@ -0,0 +1,54 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "maps"
+
+// RegisterType makes UnmarshalJSON decode the values of key, at any depth,
+// with encoding/json into the value returned by factory, typically a pointer
+// to a struct, which is stored as the value.  For example:
+//
+//	o.RegisterType("header", func() any { return new(Header) })
+//
+// A nil factory removes the registration.  Like SetValidator, the registry is
+// shared with nested maps decoded into o.
+func (o *OrderedMap) RegisterType(key string, factory func() any) {
+	o.opts = o.opts.clone()
+	o.opts.types = maps.Clone(o.opts.types)
+	if factory == nil {
+		delete(o.opts.types, o.mapKey(key))
+		return
+	}
+	if o.opts.types == nil {
+		o.opts.types = make(map[string]func() any)
+	}
+	o.opts.types[o.mapKey(key)] = factory
+}
+
+// typeFor returns the factory registered for key, or nil.
+func (o *OrderedMap) typeFor(key string) func() any {
+	if o.opts == nil || o.opts.types == nil {
+		return nil
+	}
+	return o.opts.types[o.mapKey(key)]
+}
@@ -0,0 +1,68 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderedMap_RegisterType(t *testing.T) {
+	type Header struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+	}
+	o := New()
+	o.RegisterType("head", func() any { return new(Header) })
+	err := o.UnmarshalJSON([]byte(`{"head":{"alg":"ES256","typ":"x"},"m":{"head":{"alg":"Ed25519"}},"n":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, ok := o.Get("head").(*Header); !ok || *h != (Header{"ES256", "x"}) {
+		t.Errorf("top-level %#v", o.Get("head"))
+	}
+	m := o.Get("m").(OrderedMap)
+	if h, ok := m.Get("head").(*Header); !ok || h.Alg != "Ed25519" {
+		t.Errorf("nested %#v", m.Get("head"))
+	}
+	b, err := o.MarshalJSON()
+	if err != nil || string(b) != `{"head":{"alg":"ES256","typ":"x"},"m":{"head":{"alg":"Ed25519","typ":""}},"n":1}` {
+		t.Error("MarshalJSON", string(b), err)
+	}
+
+	// Duplicates within typed values are still rejected.
+	if err = o.UnmarshalJSON([]byte(`{"head":{"alg":"a","alg":"b"}}`)); !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("duplicate", err)
+	}
+	if err = o.UnmarshalJSON([]byte(`{"head":[1]}`)); err == nil {
+		t.Error("type mismatch not rejected")
+	}
+
+	o.RegisterType("head", nil)
+	if err = o.UnmarshalJSON([]byte(`{"head":{"alg":"a"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := o.Get("head").(OrderedMap); !ok {
+		t.Errorf("unregistered %T", o.Get("head"))
+	}
+}
@@ -0,0 +1,146 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// RelaxedFormat configures MarshalRelaxed.
+type RelaxedFormat struct {
+	// Indent is the indentation of each level, two spaces if empty.
+	Indent string
+	// AlignKeys pads keys so that the values of an object are aligned.
+	AlignKeys bool
+	// TrailingCommas adds a comma after the last member or element.
+	TrailingCommas bool
+	// UnquotedKeys omits the quotes of keys that are identifiers.
+	UnquotedKeys bool
+}
+
+// MarshalRelaxed returns o formatted for people, such as for generated
+// configuration files, with one member or element per line.  Nested maps and
+// []any are formatted likewise and other values are encoded as compact JSON.
+// With TrailingCommas or UnquotedKeys the output is not strict JSON but is
+// accepted by UnmarshalJSON with the Lenient option.
+func (o *OrderedMap) MarshalRelaxed(f RelaxedFormat) ([]byte, error) {
+	if f.Indent == "" {
+		f.Indent = "  "
+	}
+	var buf bytes.Buffer
+	if err := writeRelaxed(&buf, o, 0, &f); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeRelaxed(buf *bytes.Buffer, v any, depth int, f *RelaxedFormat) error {
+	if m, ok := asMap(v); ok {
+		return writeRelaxedMap(buf, m, depth, f)
+	}
+	if s, ok := asSlice(v); ok {
+		if len(s) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, e := range s {
+			buf.WriteString(strings.Repeat(f.Indent, depth+1))
+			if err := writeRelaxed(buf, e, depth+1, f); err != nil {
+				return err
+			}
+			if i < len(s)-1 || f.TrailingCommas {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(strings.Repeat(f.Indent, depth))
+		buf.WriteByte(']')
+		return nil
+	}
+	b, err := encodeCompact(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+func writeRelaxedMap(buf *bytes.Buffer, o *OrderedMap, depth int, f *RelaxedFormat) error {
+	if o.Len() == 0 {
+		buf.WriteString("{}")
+		return nil
+	}
+	keys := make([]string, len(o.keys))
+	width := 0
+	for i, k := range o.keys {
+		if f.UnquotedKeys && isIdent(k) {
+			keys[i] = k
+		} else {
+			b, err := encodeCompact(k)
+			if err != nil {
+				return err
+			}
+			keys[i] = string(b)
+		}
+		width = max(width, utf8.RuneCountInString(keys[i]))
+	}
+	buf.WriteString("{\n")
+	for i, k := range keys {
+		buf.WriteString(strings.Repeat(f.Indent, depth+1))
+		buf.WriteString(k)
+		buf.WriteString(": ")
+		if f.AlignKeys {
+			buf.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(k)))
+		}
+		if err := writeRelaxed(buf, o.valueAt(i), depth+1, f); err != nil {
+			return err
+		}
+		if i < len(keys)-1 || f.TrailingCommas {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(strings.Repeat(f.Indent, depth))
+	buf.WriteByte('}')
+	return nil
+}
+
+// encodeCompact returns the compact JSON encoding of v, without HTML escaping
+// like MarshalJSON.
+func encodeCompact(v any) ([]byte, error) {
+	e := getEncodeState()
+	defer putEncodeState(e)
+	if err := e.enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(e.buf.Bytes()[:e.buf.Len()-1]), nil
+}
+
+// isIdent reports whether s is an identifier accepted unquoted by Lenient.
+func isIdent(s string) bool {
+	return s != "" && isIdentStart([]byte(s)) && identEnd([]byte(s), 0) == len(s)
+}
@@ -0,0 +1,70 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"testing"
+)
+
+func TestOrderedMap_MarshalRelaxed(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"name":"x","long-key":[1,{"a":true}],"e":{},"s":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := o.MarshalRelaxed(RelaxedFormat{AlignKeys: true, TrailingCommas: true, UnquotedKeys: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  name:       "x",
+  "long-key": [
+    1,
+    {
+      a: true,
+    },
+  ],
+  e:          {},
+  s:          [],
+}
+`
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+
+	// The output round-trips with Lenient.
+	l := New(Lenient())
+	if err = l.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if !o.EqualOrdered(l) {
+		t.Error("round trip differs")
+	}
+
+	// The default format is strict JSON.
+	if b, err = o.MarshalRelaxed(RelaxedFormat{Indent: "\t"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = New().UnmarshalJSON(b); err != nil {
+		t.Error("default format not strict JSON", err)
+	}
+}
@@ -0,0 +1,187 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Policy is how ReorderTo handles keys that are not in the given order.
+type Policy int
+
+const (
+	// UnknownLast places unknown keys after the known keys, in their existing
+	// relative order.
+	UnknownLast Policy = iota
+	// UnknownFirst places unknown keys before the known keys, in their existing
+	// relative order.
+	UnknownFirst
+	// UnknownError rejects unknown keys with an error wrapping ErrUnknownKey.
+	UnknownError
+)
+
+// ErrUnknownKey is returned by ReorderTo with UnknownError for a key that is
+// not in the order.
+var ErrUnknownKey = errors.New("orderedmap: unknown key")
+
+// ReorderTo reorders the keys of o to match order, a canonical field list such
+// as Coze's or JWS's conventional order, placing keys not in order according
+// to unknown.  Keys in order that do not exist in o are ignored.  On error o is
+// not modified.
+func (o *OrderedMap) ReorderTo(order []string, unknown Policy) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	pos := make(map[string]int, len(order))
+	for i, k := range order {
+		if _, ok := pos[o.mapKey(k)]; !ok {
+			pos[o.mapKey(k)] = i
+		}
+	}
+	// slots[p] is the current position of the key at position p of order.
+	slots := make([]int, len(order))
+	for i := range slots {
+		slots[i] = -1
+	}
+	var rest []int
+	for i, k := range o.keys {
+		if p, ok := pos[o.mapKey(k)]; ok {
+			slots[p] = i
+			continue
+		}
+		if unknown == UnknownError {
+			return fmt.Errorf("%w: %q", ErrUnknownKey, k)
+		}
+		rest = append(rest, i)
+	}
+	perm := make([]int, 0, len(o.keys))
+	if unknown == UnknownFirst {
+		perm = append(perm, rest...)
+	}
+	for _, i := range slots {
+		if i >= 0 {
+			perm = append(perm, i)
+		}
+	}
+	if unknown != UnknownFirst {
+		perm = append(perm, rest...)
+	}
+
+	if o.observed() {
+		defer o.notify(Change{Op: OpSort, prev: o.saveState()})
+	}
+	o.own()
+	keys := make([]string, len(perm))
+	for j, i := range perm {
+		keys[j] = o.keys[i]
+	}
+	if o.values == nil {
+		vals := make([]any, len(perm))
+		for j, i := range perm {
+			vals[j] = o.vals[i]
+		}
+		copy(o.vals, vals)
+	}
+	copy(o.keys, keys)
+	return nil
+}
+
+// OrderLike reorders the keys of o to match their order in other, such as a
+// request that a response should mirror.  Keys not in other follow, in their
+// existing relative order.
+func (o *OrderedMap) OrderLike(other *OrderedMap) error {
+	return o.ReorderTo(other.keys, UnknownLast)
+}
+
+// OrderLikeDeep is OrderLike applied at every depth: the value of each key
+// that is a map in both o and other is ordered like the value in other, and
+// each map in an array is ordered like the map at the same index in the
+// corresponding array of other, or if there is none, like its first element.
+// Copied values are set again as by TrySet, so a validator may reject them.
+func (o *OrderedMap) OrderLikeDeep(other *OrderedMap) error {
+	if err := o.OrderLike(other); err != nil {
+		return err
+	}
+	for i, k := range o.keys {
+		like, ok := other.get(k)
+		if !ok {
+			continue
+		}
+		v, changed, err := orderLike(o.valueAt(i), like)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if err := o.store(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// orderLike returns v ordered like the value like, and whether v must be set
+// again as it was copied.
+func orderLike(v, like any) (any, bool, error) {
+	if lm, ok := asMap(like); ok {
+		switch m := v.(type) {
+		case *OrderedMap:
+			return m, false, m.OrderLikeDeep(lm)
+		case OrderedMap:
+			// m shares storage with the stored value, so copy it on write.
+			m.shared = true
+			return m, true, m.OrderLikeDeep(lm)
+		}
+		return v, false, nil
+	}
+	ls, ok := asSlice(like)
+	s, isSlice := asSlice(v)
+	if !ok || !isSlice || len(ls) == 0 {
+		return v, false, nil
+	}
+	var c []any
+	for i, e := range s {
+		l := ls[0]
+		if i < len(ls) {
+			l = ls[i]
+		}
+		ne, changed, err := orderLike(e, l)
+		if err != nil {
+			return nil, false, err
+		}
+		if changed && c == nil {
+			c = append([]any(nil), s...)
+		}
+		if c != nil {
+			c[i] = ne
+		}
+	}
+	if c == nil {
+		return v, false, nil
+	}
+	if _, ok := v.(OrderedArray); ok {
+		return OrderedArray(c), true, nil
+	}
+	return c, true, nil
+}
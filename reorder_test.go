@@ -0,0 +1,123 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_ReorderTo(t *testing.T) {
+	coze := []string{"alg", "iat", "tmb", "typ"}
+	for _, extra := range []int{0, smallSize} {
+		o := New()
+		for _, k := range []string{"x", "tmb", "typ", "alg", "y"} {
+			o.Set(k, k)
+		}
+		var big []string
+		for i := 0; i < extra; i++ {
+			k := string(rune('A' + i))
+			o.Set(k, k)
+			big = append(big, k)
+		}
+
+		if err := o.ReorderTo(coze, UnknownError); !errors.Is(err, ErrUnknownKey) || o.Keys()[0] != "x" {
+			t.Error("UnknownError", err, o.Keys())
+		}
+		o.ReorderTo(coze, UnknownLast)
+		if want := append([]string{"alg", "tmb", "typ", "x", "y"}, big...); !reflect.DeepEqual(o.Keys(), want) {
+			t.Errorf("UnknownLast got %v, want %v", o.Keys(), want)
+		}
+		o.ReorderTo(coze, UnknownFirst)
+		if want := append(append([]string{"x", "y"}, big...), "alg", "tmb", "typ"); !reflect.DeepEqual(o.Keys(), want) {
+			t.Errorf("UnknownFirst got %v, want %v", o.Keys(), want)
+		}
+		for i, k := range o.Keys() {
+			if o.GetValueAt(i) != k {
+				t.Errorf("value of %s misaligned", k)
+			}
+		}
+	}
+}
+
+func TestOrderedMap_OrderLike(t *testing.T) {
+	req := New()
+	if err := req.UnmarshalJSON([]byte(`{"id":1,"user":{"name":"","age":0},"items":[{"sku":"","qty":0}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	in := `{"extra":true,"items":[{"qty":1,"sku":"a"},{"qty":2,"sku":"b"}],"user":{"age":3,"name":"x"},"id":7}`
+
+	o := New()
+	if err := o.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.OrderLike(req); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"id":7,"user":{"age":3,"name":"x"},"items":[{"qty":1,"sku":"a"},{"qty":2,"sku":"b"}],"extra":true}` {
+		t.Errorf("OrderLike: got %s", b)
+	}
+
+	snap := o.Snapshot()
+	if err := o.OrderLikeDeep(req); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"id":7,"user":{"name":"x","age":3},"items":[{"sku":"a","qty":1},{"sku":"b","qty":2}],"extra":true}` {
+		t.Errorf("OrderLikeDeep: got %s", b)
+	}
+	if b, _ := snap.MarshalJSON(); string(b) != `{"id":7,"user":{"age":3,"name":"x"},"items":[{"qty":1,"sku":"a"},{"qty":2,"sku":"b"}],"extra":true}` {
+		t.Errorf("snapshot modified: %s", b)
+	}
+}
+
+func TestOrderedMap_OrderLikeDeep_Rejected(t *testing.T) {
+	like := New()
+	if err := like.UnmarshalJSON([]byte(`{"a":{"x":0,"y":0}}`)); err != nil {
+		t.Fatal(err)
+	}
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"a":{"y":1,"x":2}}`)); err != nil {
+		t.Fatal(err)
+	}
+	o.Freeze()
+	if err := o.OrderLikeDeep(like); err != ErrFrozen {
+		t.Error("frozen:", err)
+	}
+
+	errReject := errors.New("reject")
+	v := New()
+	if err := v.UnmarshalJSON([]byte(`{"a":[{"y":1,"x":2}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	v.SetValidator(func(key string, value any) error {
+		if key == "a" {
+			return errReject
+		}
+		return nil
+	})
+	like.Set("a", []any{like.Get("a")})
+	if err := v.OrderLikeDeep(like); err != errReject {
+		t.Error("validator:", err)
+	}
+}
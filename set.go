@@ -0,0 +1,145 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// OrderedSet is a set that keeps the insertion order of its elements, encoded
+// in JSON as an array.  Like OrderedMap, small sets are not backed by a Go
+// map.  The zero value is an empty set ready to use.  An OrderedSet is not safe
+// for concurrent use.
+type OrderedSet[T comparable] struct {
+	items []T
+	// index holds the items once there are more than smallSize.
+	index map[T]struct{}
+}
+
+// NewOrderedSet returns a set of items, in order, ignoring repeated items.
+func NewOrderedSet[T comparable](items ...T) *OrderedSet[T] {
+	s := new(OrderedSet[T])
+	for _, v := range items {
+		s.Add(v)
+	}
+	return s
+}
+
+// Add appends v unless it is in s, and reports whether it was added.  Like a
+// map key, v must be comparable at run time: Add panics for a slice or map in
+// an OrderedSet[any].
+func (s *OrderedSet[T]) Add(v T) bool {
+	if s.Has(v) {
+		return false
+	}
+	s.items = append(s.items, v)
+	if s.index != nil {
+		s.index[v] = struct{}{}
+	} else if len(s.items) > smallSize {
+		s.index = make(map[T]struct{}, len(s.items))
+		for _, e := range s.items {
+			s.index[e] = struct{}{}
+		}
+	}
+	return true
+}
+
+// Has reports whether v is in s.
+func (s *OrderedSet[T]) Has(v T) bool {
+	if s.index != nil {
+		_, ok := s.index[v]
+		return ok
+	}
+	return slices.Contains(s.items, v)
+}
+
+// Delete removes v, and reports whether it was in s.  Later elements keep
+// their order.
+func (s *OrderedSet[T]) Delete(v T) bool {
+	if !s.Has(v) {
+		return false
+	}
+	delete(s.index, v)
+	i := slices.Index(s.items, v)
+	s.items = slices.Delete(s.items, i, i+1)
+	return true
+}
+
+// Len returns the number of elements in s.
+func (s *OrderedSet[T]) Len() int {
+	return len(s.items)
+}
+
+// Values returns a copy of the elements of s in order.
+func (s *OrderedSet[T]) Values() []T {
+	return slices.Clone(s.items)
+}
+
+// All returns an iterator over the elements of s in order.
+func (s *OrderedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range s.items {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON encodes s as an array of its elements in order.
+func (s OrderedSet[T]) MarshalJSON() ([]byte, error) {
+	if s.items == nil {
+		return []byte("[]"), nil
+	}
+	return encodeCompact(s.items)
+}
+
+// UnmarshalJSON replaces the contents of s with the elements of the JSON array
+// b.  Repeated elements are rejected with an error matching ErrJSONDuplicate,
+// like duplicate keys by OrderedMap, and s is not modified, as are elements
+// that are not comparable, such as arrays and objects in an OrderedSet[any].
+// By convention, null is a no-op.
+func (s *OrderedSet[T]) UnmarshalJSON(b []byte) error {
+	var items []T
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	if items == nil {
+		return nil
+	}
+	n := new(OrderedSet[T])
+	for i, v := range items {
+		if rv := reflect.ValueOf(v); rv.IsValid() && !rv.Comparable() {
+			return fmt.Errorf("orderedmap: set element %T at [%d] is not comparable", v, i)
+		}
+		if !n.Add(v) {
+			return fmt.Errorf("%w %v at [%d]", ErrJSONDuplicate, v, i)
+		}
+	}
+	*s = *n
+	return nil
+}
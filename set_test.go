@@ -0,0 +1,90 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	var z OrderedSet[int]
+	if b, _ := json.Marshal(z); string(b) != "[]" {
+		t.Errorf("zero set = %s", b)
+	}
+
+	s := NewOrderedSet("c", "a", "c", "b")
+	if s.Add("a") || !s.Add("d") {
+		t.Error("Add")
+	}
+	if !s.Has("b") || s.Has("x") {
+		t.Error("Has")
+	}
+	if !s.Delete("a") || s.Delete("a") {
+		t.Error("Delete")
+	}
+	if got := slices.Collect(s.All()); !slices.Equal(got, []string{"c", "b", "d"}) || s.Len() != 3 {
+		t.Errorf("All = %v", got)
+	}
+	b, err := json.Marshal(s)
+	if err != nil || string(b) != `["c","b","d"]` {
+		t.Fatalf("got %s, %v", b, err)
+	}
+
+	var u OrderedSet[string]
+	if err := json.Unmarshal([]byte(`["x","<y>"]`), &u); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.UnmarshalJSON([]byte(`["a","b","a"]`)); !errors.Is(err, ErrJSONDuplicate) {
+		t.Errorf("got %v", err)
+	}
+	if err := u.UnmarshalJSON([]byte("null")); err != nil {
+		t.Error(err)
+	}
+	if b, _ := u.MarshalJSON(); string(b) != `["x","<y>"]` {
+		t.Errorf("got %s", b)
+	}
+
+	var anys OrderedSet[any]
+	for _, in := range []string{`[[1],[2]]`, `[1,{"a":1}]`} {
+		if err := anys.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("%s: no error", in)
+		}
+	}
+	if err := anys.UnmarshalJSON([]byte(`[1,"a",null,true]`)); err != nil || anys.Len() != 4 {
+		t.Error(anys.Values(), err)
+	}
+
+	// Past smallSize, backed by a map.
+	large := NewOrderedSet[string]()
+	for i := range 100 {
+		large.Add(strconv.Itoa(i % 50))
+	}
+	large.Delete("7")
+	if large.Len() != 49 || large.Has("7") || !large.Has("49") || large.Values()[7] != "8" {
+		t.Errorf("large set: %v", large.Values())
+	}
+}
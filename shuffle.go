@@ -0,0 +1,70 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Shuffle randomizes the order of the keys of o using r, or the global source
+// of math/rand if r is nil.  A seeded r gives a reproducible order.
+func (o *OrderedMap) Shuffle(r *rand.Rand) {
+	o.SortKeys(func(keys []string) {
+		swap := func(i, j int) { keys[i], keys[j] = keys[j], keys[i] }
+		if r == nil {
+			rand.Shuffle(len(keys), swap)
+			return
+		}
+		r.Shuffle(len(keys), swap)
+	})
+}
+
+// ShuffleIteration makes All, AllKeys and AllValues iterate in a new random
+// order each time, from the given seed, like the iteration of Go maps.  It is
+// for tests, to detect code that relies on the order of maps it should not,
+// such as code that should use Keys for their order.  Keys, MarshalJSON and
+// the order of o are not affected.  Nested maps decoded by UnmarshalJSON share
+// the source of randomness.
+func ShuffleIteration(seed int64) Option {
+	return func(opts *options) {
+		opts.shuffle = &shuffler{r: rand.New(rand.NewSource(seed))}
+	}
+}
+
+// shuffler is a source of permutations safe for concurrent iteration.
+type shuffler struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (s *shuffler) perm(n int) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Perm(n)
+}
+
+// shuffles reports whether o iterates in a random order.
+func (o *OrderedMap) shuffles() bool {
+	return o.opts != nil && o.opts.shuffle != nil
+}
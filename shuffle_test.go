@@ -0,0 +1,98 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestShuffle(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	shuffled := func(seed int64) *OrderedMap {
+		o := New()
+		for i, k := range keys {
+			o.Set(k, i)
+		}
+		o.Shuffle(rand.New(rand.NewSource(seed)))
+		return o
+	}
+	o := shuffled(1)
+	if slices.Equal(o.Keys(), keys) {
+		t.Error("not shuffled", o.Keys())
+	}
+	if !slices.Equal(o.Keys(), shuffled(1).Keys()) {
+		t.Error("same seed gave a different order")
+	}
+	for i, k := range o.Keys() {
+		if o.Get(k) != slices.Index(keys, k) || o.GetValueAt(i) != o.Get(k) {
+			t.Errorf("value of %s moved", k)
+		}
+	}
+	o.Shuffle(nil)
+	if o.Len() != len(keys) {
+		t.Error("len", o.Len())
+	}
+}
+
+func TestShuffleIteration(t *testing.T) {
+	o := New(ShuffleIteration(1))
+	if err := o.UnmarshalJSON([]byte(`{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":{"x":1,"y":2}}`)); err != nil {
+		t.Fatal(err)
+	}
+	orders := make(map[string]bool)
+	for range 10 {
+		var keys []string
+		for k, v := range o.All() {
+			keys = append(keys, k)
+			if k != "h" && v != o.Get(k) {
+				t.Errorf("value of %s is %v", k, v)
+			}
+		}
+		slices.Sort(keys)
+		if !slices.Equal(keys, o.Keys()) {
+			t.Fatal("iterated keys", keys)
+		}
+		orders[fmtKeys(o)] = true
+	}
+	if len(orders) < 2 {
+		t.Error("iteration order not randomized")
+	}
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":{"x":1,"y":2}}` {
+		t.Error("marshal", string(b))
+	}
+}
+
+// fmtKeys returns the keys of o in the order of AllKeys.
+func fmtKeys(o *OrderedMap) string {
+	var s string
+	for k := range o.AllKeys() {
+		s += k
+	}
+	return s
+}
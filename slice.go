@@ -0,0 +1,79 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "fmt"
+
+// GetMapSlice returns the value for key as a slice of maps, such as a decoded
+// JSON array of objects.  It returns false if key is not set or its value is
+// not a []any or OrderedArray of OrderedMap or *OrderedMap elements.
+// Elements that are OrderedMap values are returned as pointers to copies,
+// which share storage with the stored elements until modified, so modifying
+// them does not modify o.  Set the key again to store modified copies.
+func (o *OrderedMap) GetMapSlice(key string) ([]*OrderedMap, bool) {
+	a, ok := asSlice(o.Get(key))
+	if !ok {
+		return nil, false
+	}
+	ms := make([]*OrderedMap, len(a))
+	for i, v := range a {
+		switch m := v.(type) {
+		case OrderedMap:
+			m.shared = true
+			ms[i] = &m
+		case *OrderedMap:
+			if m == nil {
+				return nil, false
+			}
+			ms[i] = m
+		default:
+			return nil, false
+		}
+	}
+	return ms, true
+}
+
+// AppendToSlice appends v to the []any or OrderedArray value for key, setting
+// key to a new slice, an OrderedArray with OrderedArrays, if it is not set.
+// The stored slice is not modified; a new slice of the same type is set like
+// Set, so slices shared with snapshots are unaffected.  If the value for key
+// is not a slice, AppendToSlice returns an error and o is not modified.
+func (o *OrderedMap) AppendToSlice(key string, v any) error {
+	o.expire(key)
+	cur, ok := o.get(key)
+	if !ok {
+		if o.opts != nil && o.opts.arrays {
+			return o.TrySet(key, OrderedArray{v})
+		}
+		return o.TrySet(key, []any{v})
+	}
+	a, ok := asSlice(cur)
+	if !ok {
+		return fmt.Errorf("orderedmap: value for %q is %T, not a slice", key, cur)
+	}
+	a = append(a[:len(a):len(a)], v)
+	if _, ok := cur.([]any); ok {
+		return o.TrySet(key, []any(a))
+	}
+	return o.TrySet(key, a)
+}
@@ -0,0 +1,92 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "testing"
+
+func TestOrderedMap_GetMapSlice(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"items":[{"id":1},{"id":2}],"mixed":[{"id":1},2],"s":"v"}`)); err != nil {
+		t.Fatal(err)
+	}
+	ms, ok := o.GetMapSlice("items")
+	if !ok || len(ms) != 2 || ms[1].Get("id") != 2.0 {
+		t.Fatalf("got %v, %v", ms, ok)
+	}
+	ms[0].Set("id", 9)
+	if b, _ := o.MarshalJSON(); string(b) != `{"items":[{"id":1},{"id":2}],"mixed":[{"id":1},2],"s":"v"}` {
+		t.Errorf("o modified: %s", b)
+	}
+	for _, k := range []string{"mixed", "s", "missing"} {
+		if _, ok := o.GetMapSlice(k); ok {
+			t.Errorf("GetMapSlice(%q) ok", k)
+		}
+	}
+}
+
+func TestOrderedMap_AppendToSlice(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"items":[1],"s":"v"}`)); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.Snapshot()
+	o.AppendToSlice("items", 2)
+	snap.AppendToSlice("items", 3)
+	if err := o.AppendToSlice("new", New()); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"items":[1,2],"s":"v","new":[{}]}` {
+		t.Errorf("got %s", b)
+	}
+	if b, _ := snap.MarshalJSON(); string(b) != `{"items":[1,3],"s":"v"}` {
+		t.Errorf("snapshot got %s", b)
+	}
+	if err := o.AppendToSlice("s", 1); err == nil {
+		t.Error("appended to string")
+	}
+}
+
+func TestOrderedMap_SliceOrderedArrays(t *testing.T) {
+	o := New(OrderedArrays())
+	if err := o.UnmarshalJSON([]byte(`{"items":[{"id":1},{"id":2}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	ms, ok := o.GetMapSlice("items")
+	if !ok || len(ms) != 2 || ms[1].Get("id") != 2.0 {
+		t.Fatalf("got %v, %v", ms, ok)
+	}
+	if err := o.AppendToSlice("items", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.AppendToSlice("new", 4); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"items", "new"} {
+		if _, ok := o.Get(k).(OrderedArray); !ok {
+			t.Errorf("%s is %T", k, o.Get(k))
+		}
+	}
+	if b, _ := o.MarshalJSON(); string(b) != `{"items":[{"id":1},{"id":2},3],"new":[4]}` {
+		t.Errorf("got %s", b)
+	}
+}
@@ -0,0 +1,65 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "maps"
+
+// Snapshot returns a point-in-time copy of o in O(1).  o and the snapshot
+// share storage until either is modified, which first copies it, so taking
+// many snapshots between modifications is cheap.  The copy is shallow: nested
+// maps and slices are shared.  The snapshot has the options of o but not its
+// OnChange func, and is not frozen.  Like o, a snapshot is not safe for
+// concurrent use with modifications of o.
+func (o *OrderedMap) Snapshot() *OrderedMap {
+	o.shared = true
+	return &OrderedMap{
+		keys:    o.keys,
+		vals:    o.vals,
+		values:  o.values,
+		opts:    o.opts,
+		expires: o.expires,
+		offsets: o.offsets,
+		raws:    o.raws,
+		layout:  o.layout,
+		shared:  true,
+	}
+}
+
+// own copies storage shared with a Snapshot so that o can be modified.  Every
+// modification calls it, and it panics if o is frozen.
+func (o *OrderedMap) own() {
+	if o.frozen {
+		panic(ErrFrozen)
+	}
+	if !o.shared {
+		return
+	}
+	o.keys = append([]string(nil), o.keys...)
+	if o.vals != nil {
+		o.vals = append([]any(nil), o.vals...)
+	}
+	o.values = maps.Clone(o.values)
+	o.expires = maps.Clone(o.expires)
+	o.raws = maps.Clone(o.raws)
+	o.shared = false
+}
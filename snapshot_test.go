@@ -0,0 +1,61 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestOrderedMap_Snapshot(t *testing.T) {
+	for _, n := range []int{3, smallSize * 2} {
+		o := New()
+		for i := 0; i < n; i++ {
+			o.Set(strconv.Itoa(i), i)
+		}
+		s := o.Snapshot()
+		s2 := o.Snapshot()
+		wantKeys := o.KeysCopy()
+		wantValues := o.Values()
+
+		o.Set("0", "changed")
+		o.Set("new", true)
+		o.Delete("1")
+		o.SortKeys(func(k []string) { sort.Sort(sort.Reverse(sort.StringSlice(k))) })
+		o.Entry("2").Set("entry")
+
+		for _, s := range []*OrderedMap{s, s2} {
+			if !reflect.DeepEqual(s.Keys(), wantKeys) || !reflect.DeepEqual(s.Values(), wantValues) {
+				t.Errorf("n %d: snapshot modified: %v %v", n, s.Keys(), s.Values())
+			}
+		}
+
+		// Modifying a snapshot does not modify o.
+		s.Set("snap", 1)
+		if o.Get("snap") != nil || s2.Get("snap") != nil {
+			t.Errorf("n %d: snapshot modification visible", n)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"iter"
+	"sort"
+)
+
+// degree is the minimum degree of the B-tree of a SortedMap.  Nodes other than
+// the root hold between degree-1 and 2*degree-1 entries.
+const degree = 16
+
+// SortedMap is a map that keeps keys in comparator order rather than insertion
+// order, in a B-tree.  Get, Set and Delete are O(log n).  It encodes and decodes
+// JSON like OrderedMap.  The zero value is an empty map in lexicographic order.
+type SortedMap struct {
+	root *bnode
+	less func(a, b string) bool
+	len  int
+}
+
+type bnode struct {
+	pairs []pair
+	// children is nil for leaves, and otherwise has one more element than
+	// pairs.
+	children []*bnode
+}
+
+// NewSorted returns an empty SortedMap ordered by less, or lexicographically if
+// less is nil.
+func NewSorted(less func(a, b string) bool) *SortedMap {
+	return &SortedMap{less: less}
+}
+
+func (s *SortedMap) lessFunc() func(a, b string) bool {
+	if s.less == nil {
+		return func(a, b string) bool { return a < b }
+	}
+	return s.less
+}
+
+// find returns the position of the first pair of n not less than key, and
+// whether it is key.
+func (n *bnode) find(key string, less func(a, b string) bool) (int, bool) {
+	i := sort.Search(len(n.pairs), func(i int) bool { return !less(n.pairs[i].key, key) })
+	return i, i < len(n.pairs) && !less(key, n.pairs[i].key)
+}
+
+func (n *bnode) leaf() bool {
+	return n.children == nil
+}
+
+// Get returns the value for key, or nil.
+func (s *SortedMap) Get(key string) any {
+	v, _ := s.Lookup(key)
+	return v
+}
+
+// Lookup returns the value for key and whether it exists.
+func (s *SortedMap) Lookup(key string) (any, bool) {
+	less := s.lessFunc()
+	for n := s.root; n != nil; {
+		i, found := n.find(key, less)
+		if found {
+			return n.pairs[i].value, true
+		}
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+	return nil, false
+}
+
+// Set sets the value for key.
+func (s *SortedMap) Set(key string, value any) {
+	if s.root == nil {
+		s.root = &bnode{pairs: []pair{{key, value}}}
+		s.len++
+		return
+	}
+	if len(s.root.pairs) == 2*degree-1 {
+		s.root = &bnode{children: []*bnode{s.root}}
+		s.root.split(0)
+	}
+	if s.root.insert(pair{key, value}, s.lessFunc()) {
+		s.len++
+	}
+}
+
+// split splits the full child i of n, moving its median pair into n.
+func (n *bnode) split(i int) {
+	c := n.children[i]
+	median := c.pairs[degree-1]
+	right := &bnode{pairs: append([]pair(nil), c.pairs[degree:]...)}
+	if !c.leaf() {
+		right.children = append([]*bnode(nil), c.children[degree:]...)
+		clear(c.children[degree:])
+		c.children = c.children[:degree]
+	}
+	clear(c.pairs[degree-1:])
+	c.pairs = c.pairs[:degree-1]
+
+	n.pairs = append(n.pairs, pair{})
+	copy(n.pairs[i+1:], n.pairs[i:])
+	n.pairs[i] = median
+	n.children = append(n.children, nil)
+	copy(n.children[i+2:], n.children[i+1:])
+	n.children[i+1] = right
+}
+
+// insert sets p in the subtree of n, which is not full, and reports whether
+// the key is new.
+func (n *bnode) insert(p pair, less func(a, b string) bool) bool {
+	i, found := n.find(p.key, less)
+	if found {
+		n.pairs[i].value = p.value
+		return false
+	}
+	if n.leaf() {
+		n.pairs = append(n.pairs, pair{})
+		copy(n.pairs[i+1:], n.pairs[i:])
+		n.pairs[i] = p
+		return true
+	}
+	if len(n.children[i].pairs) == 2*degree-1 {
+		n.split(i)
+		switch {
+		case !less(p.key, n.pairs[i].key) && !less(n.pairs[i].key, p.key):
+			n.pairs[i].value = p.value
+			return false
+		case less(n.pairs[i].key, p.key):
+			i++
+		}
+	}
+	return n.children[i].insert(p, less)
+}
+
+// Delete deletes key, if it exists.
+func (s *SortedMap) Delete(key string) {
+	if s.root == nil {
+		return
+	}
+	if s.root.remove(key, s.lessFunc()) {
+		s.len--
+	}
+	if len(s.root.pairs) == 0 {
+		if s.root.leaf() {
+			s.root = nil
+		} else {
+			s.root = s.root.children[0]
+		}
+	}
+}
+
+// remove removes key from the subtree of n, whose children have at least
+// degree pairs unless n is the root, and reports whether it existed.
+func (n *bnode) remove(key string, less func(a, b string) bool) bool {
+	i, found := n.find(key, less)
+	if n.leaf() {
+		if !found {
+			return false
+		}
+		copy(n.pairs[i:], n.pairs[i+1:])
+		n.pairs[len(n.pairs)-1] = pair{}
+		n.pairs = n.pairs[:len(n.pairs)-1]
+		return true
+	}
+	if found {
+		switch left, right := n.children[i], n.children[i+1]; {
+		case len(left.pairs) >= degree:
+			n.pairs[i] = left.max()
+			return left.remove(n.pairs[i].key, less)
+		case len(right.pairs) >= degree:
+			n.pairs[i] = right.min()
+			return right.remove(n.pairs[i].key, less)
+		}
+		n.merge(i)
+		return n.children[i].remove(key, less)
+	}
+	if len(n.children[i].pairs) == degree-1 {
+		i = n.fill(i)
+	}
+	return n.children[i].remove(key, less)
+}
+
+func (n *bnode) max() pair {
+	for !n.leaf() {
+		n = n.children[len(n.children)-1]
+	}
+	return n.pairs[len(n.pairs)-1]
+}
+
+func (n *bnode) min() pair {
+	for !n.leaf() {
+		n = n.children[0]
+	}
+	return n.pairs[0]
+}
+
+// fill gives child i of n at least degree pairs, by moving a pair from a
+// sibling or merging with one, and returns the new position of the child.
+func (n *bnode) fill(i int) int {
+	c := n.children[i]
+	if i > 0 && len(n.children[i-1].pairs) >= degree {
+		l := n.children[i-1]
+		c.pairs = append([]pair{n.pairs[i-1]}, c.pairs...)
+		n.pairs[i-1] = l.pairs[len(l.pairs)-1]
+		l.pairs[len(l.pairs)-1] = pair{}
+		l.pairs = l.pairs[:len(l.pairs)-1]
+		if !l.leaf() {
+			c.children = append([]*bnode{l.children[len(l.children)-1]}, c.children...)
+			l.children[len(l.children)-1] = nil
+			l.children = l.children[:len(l.children)-1]
+		}
+		return i
+	}
+	if i < len(n.pairs) && len(n.children[i+1].pairs) >= degree {
+		r := n.children[i+1]
+		c.pairs = append(c.pairs, n.pairs[i])
+		n.pairs[i] = r.pairs[0]
+		r.pairs = append(r.pairs[:0], r.pairs[1:]...)
+		if !r.leaf() {
+			c.children = append(c.children, r.children[0])
+			r.children = append(r.children[:0], r.children[1:]...)
+		}
+		return i
+	}
+	if i == len(n.pairs) {
+		i--
+	}
+	n.merge(i)
+	return i
+}
+
+// merge merges child i+1 of n and pair i into child i.
+func (n *bnode) merge(i int) {
+	l, r := n.children[i], n.children[i+1]
+	l.pairs = append(append(l.pairs, n.pairs[i]), r.pairs...)
+	l.children = append(l.children, r.children...)
+	n.pairs = append(n.pairs[:i], n.pairs[i+1:]...)
+	n.children = append(n.children[:i+1], n.children[i+2:]...)
+}
+
+// Len returns the number of keys.
+func (s *SortedMap) Len() int {
+	return s.len
+}
+
+// All returns an iterator over the key-value pairs in order.  s must not be
+// modified during iteration.
+func (s *SortedMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		s.root.ascend("", nil, false, s.lessFunc(), yield)
+	}
+}
+
+// Range returns an iterator over the key-value pairs with keys from from,
+// inclusive, to to, exclusive, in order.  s must not be modified during
+// iteration.
+func (s *SortedMap) Range(from, to string) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		less := s.lessFunc()
+		s.root.ascend(from, func(k string) bool { return less(k, to) }, true, less, yield)
+	}
+}
+
+// ascend yields the pairs of the subtree of n not less than from, if bounded,
+// while below returns true, and reports whether to continue.
+func (n *bnode) ascend(from string, below func(string) bool, bounded bool, less func(a, b string) bool, yield func(string, any) bool) bool {
+	if n == nil {
+		return true
+	}
+	i := 0
+	if bounded {
+		i, _ = n.find(from, less)
+	}
+	for ; i <= len(n.pairs); i++ {
+		if !n.leaf() && !n.children[i].ascend(from, below, bounded, less, yield) {
+			return false
+		}
+		if i == len(n.pairs) {
+			break
+		}
+		p := n.pairs[i]
+		if below != nil && !below(p.key) || !yield(p.key, p.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Keys returns the keys in order.
+func (s *SortedMap) Keys() []string {
+	keys := make([]string, 0, s.len)
+	for k := range s.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// MarshalJSON encodes s as a JSON object in order.
+func (s SortedMap) MarshalJSON() ([]byte, error) {
+	e := getEncodeState()
+	defer putEncodeState(e)
+	buf, encoder := &e.buf, e.enc
+	buf.WriteByte('{')
+	var err error
+	i := 0
+	for k, v := range s.All() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		i++
+		if err = encoder.Encode(k); err != nil {
+			break
+		}
+		buf.Truncate(buf.Len() - 1) // Encode's trailing newline
+		buf.WriteByte(':')
+		if err = encoder.Encode(v); err != nil {
+			break
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// UnmarshalJSON replaces the contents of s with the JSON object b, rejecting
+// duplicates like OrderedMap.  Nested objects are decoded as OrderedMap.
+func (s *SortedMap) UnmarshalJSON(b []byte) error {
+	var o OrderedMap
+	if err := o.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return nil
+	}
+	s.root, s.len = nil, 0
+	for k, v := range o.All() {
+		s.Set(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestSortedMap(t *testing.T) {
+	s := NewSorted(nil)
+	if err := json.Unmarshal([]byte(`{"c":3,"a":1,"b":{"y":1,"x":2}}`), s); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"a":1,"b":{"y":1,"x":2},"c":3}` {
+		t.Error("MarshalJSON", string(b))
+	}
+	if err = json.Unmarshal([]byte(`{"a":1,"a":2}`), s); !errors.Is(err, ErrJSONDuplicate) {
+		t.Error("duplicate", err)
+	}
+
+	desc := NewSorted(func(a, b string) bool { return a > b })
+	for _, k := range []string{"a", "c", "b"} {
+		desc.Set(k, k)
+	}
+	if !reflect.DeepEqual(desc.Keys(), []string{"c", "b", "a"}) {
+		t.Error("comparator", desc.Keys())
+	}
+}
+
+// TestSortedMapRandom checks SortedMap against a Go map over enough random
+// operations to split and merge B-tree nodes.
+func TestSortedMapRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var s SortedMap
+	m := map[string]int{}
+	for i := 0; i < 20000; i++ {
+		k := strconv.Itoa(r.Intn(2000))
+		if r.Intn(2) == 0 {
+			s.Delete(k)
+			delete(m, k)
+		} else {
+			s.Set(k, i)
+			m[k] = i
+		}
+	}
+	want := make([]string, 0, len(m))
+	for k := range m {
+		want = append(want, k)
+	}
+	sort.Strings(want)
+	if s.Len() != len(m) || !slices.Equal(s.Keys(), want) {
+		t.Fatalf("keys differ: len %d, want %d", s.Len(), len(m))
+	}
+	for k, v := range m {
+		if s.Get(k) != v {
+			t.Fatalf("Get(%q) = %v, want %v", k, s.Get(k), v)
+		}
+	}
+
+	var got []string
+	for k := range s.Range("3", "5") {
+		got = append(got, k)
+	}
+	i, j := sort.SearchStrings(want, "3"), sort.SearchStrings(want, "5")
+	if !slices.Equal(got, want[i:j]) {
+		t.Errorf("Range: got %d keys, want %d", len(got), j-i)
+	}
+
+	for _, k := range want {
+		s.Delete(k)
+	}
+	if s.Len() != 0 || s.root != nil {
+		t.Error("not empty after deleting all keys")
+	}
+}
@@ -0,0 +1,132 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// Stats describes the size and shape of an OrderedMap and its nested values.
+type Stats struct {
+	// Len is the number of keys of the map.
+	Len int
+	// Keys is the number of keys at any depth.
+	Keys int
+	// Memory is the estimated memory in bytes of the map and its nested
+	// values, as for Limits.MaxMemory.
+	Memory int64
+	// Depth is the nesting depth of objects and arrays, as for
+	// Limits.MaxDepth, which is 1 for a map of no objects or arrays.
+	Depth int
+	// Largest is up to five keys of the map with the largest stats, by
+	// Memory, largest first.
+	Largest []KeyStats
+}
+
+// KeyStats is the stats of the value of a key.
+type KeyStats struct {
+	Key    string
+	Memory int64
+}
+
+// maxLargest is the number of Stats.Largest.
+const maxLargest = 5
+
+// Stats returns the stats of o, for monitoring payload complexity.  It walks
+// every nested value, so it is O(n) in the size of o.
+func (o *OrderedMap) Stats() Stats {
+	return o.stats(true)
+}
+
+// stats returns the stats of o, without Largest unless largest.
+func (o *OrderedMap) stats(largest bool) Stats {
+	s := Stats{Len: len(o.keys), Depth: 1, Memory: sizeofMap}
+	for i, k := range o.keys {
+		vs := valueStats(o.valueAt(i))
+		s.Keys += 1 + vs.Keys
+		s.Memory += keyMemory(k) + vs.Memory
+		s.Depth = max(s.Depth, 1+vs.Depth)
+		if largest {
+			s.Largest = append(s.Largest, KeyStats{k, vs.Memory})
+		}
+	}
+	slices.SortStableFunc(s.Largest, func(a, b KeyStats) int {
+		return int(min(max(b.Memory-a.Memory, -1), 1))
+	})
+	if len(s.Largest) > maxLargest {
+		s.Largest = slices.Clip(s.Largest[:maxLargest])
+	}
+	return s
+}
+
+// Depth returns the nesting depth of objects and arrays of o, as Stats.
+func (o *OrderedMap) Depth() int {
+	d := 0
+	for i := range o.keys {
+		d = max(d, valueDepth(o.valueAt(i)))
+	}
+	return 1 + d
+}
+
+func valueDepth(v any) int {
+	if m, ok := asMap(v); ok {
+		return m.Depth()
+	}
+	a, ok := asSlice(v)
+	if !ok {
+		return 0
+	}
+	d := 0
+	for _, e := range a {
+		d = max(d, valueDepth(e))
+	}
+	return 1 + d
+}
+
+// valueStats returns the Keys, Memory and Depth of v.
+func valueStats(v any) Stats {
+	if m, ok := asMap(v); ok {
+		return m.stats(false)
+	}
+	if a, ok := asSlice(v); ok {
+		s := Stats{Depth: 1, Memory: sizeofSlice}
+		for _, e := range a {
+			es := valueStats(e)
+			s.Keys += es.Keys
+			s.Memory += sizeofAny + es.Memory
+			s.Depth = max(s.Depth, 1+es.Depth)
+		}
+		return s
+	}
+	switch v := v.(type) {
+	case nil, bool:
+		return Stats{}
+	case string:
+		return Stats{Memory: sizeofString + int64(len(v))}
+	case json.Number:
+		return Stats{Memory: sizeofString + int64(len(v))}
+	}
+	return Stats{Memory: int64(reflect.TypeOf(v).Size())}
+}
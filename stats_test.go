@@ -0,0 +1,66 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"a":1,"b":{"c":[1,{"d":"x"}]},"e":"long string value","f":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	s := o.Stats()
+	if s.Len != 4 || s.Keys != 6 || s.Depth != 4 {
+		t.Errorf("Len %d, Keys %d, Depth %d", s.Len, s.Keys, s.Depth)
+	}
+	if d := o.Depth(); d != s.Depth {
+		t.Error("Depth", d)
+	}
+	if len(s.Largest) != 4 || s.Largest[0].Key != "b" || s.Largest[1].Key != "e" || s.Largest[3].Key != "f" || s.Largest[3].Memory != 0 {
+		t.Error("Largest", s.Largest)
+	}
+	var sum int64 = sizeofMap
+	for k, v := range o.All() {
+		sum += keyMemory(k) + valueStats(v).Memory
+	}
+	if s.Memory != sum || s.Memory <= s.Largest[0].Memory {
+		t.Error("Memory", s.Memory, sum)
+	}
+
+	// Memory estimates the limit of decoding.
+	m := New(WithLimits(Limits{MaxMemory: s.Memory - 100}))
+	if err := m.UnmarshalJSON([]byte(`{"a":1,"b":{"c":[1,{"d":"x"}]},"e":"long string value","f":null}`)); err == nil {
+		t.Error("no error decoding with MaxMemory below Stats.Memory")
+	}
+
+	e := New()
+	if s := e.Stats(); s.Depth != 1 || s.Keys != 0 || len(s.Largest) != 0 {
+		t.Error("empty", s)
+	}
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		e.Set(k, k)
+	}
+	if s := e.Stats(); len(s.Largest) != maxLargest || s.Largest[0].Key != "a" {
+		t.Error("Largest of equal values", s.Largest)
+	}
+}
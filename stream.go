@@ -0,0 +1,184 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Decoder reads a stream of JSON objects, such as JSON Lines (NDJSON) or
+// concatenated objects, decoding each as an OrderedMap.
+type Decoder struct {
+	dec  *json.Decoder
+	r    *limitReader
+	opts []Option
+	// maxSize is the MaxSize of the Limits of opts, or 0.
+	maxSize int64
+}
+
+// NewDecoder returns a Decoder reading from r, configuring each decoded map
+// with opts.  The MaxSize of WithLimits bounds the input read for each object,
+// so that an object exceeding it is not buffered whole.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	lr := &limitReader{r: r, n: -1}
+	d := &Decoder{dec: json.NewDecoder(lr), r: lr, opts: opts}
+	if o := New(opts...); o.opts != nil {
+		d.maxSize = o.opts.limits.MaxSize
+	}
+	return d
+}
+
+// limitReader reads from r at most n bytes, if n is not negative, and records
+// whether it stopped at the limit.
+type limitReader struct {
+	r       io.Reader
+	n       int64
+	limited bool
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return l.r.Read(p)
+	}
+	if l.n == 0 {
+		l.limited = true
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// Decode decodes the next object of the stream like UnmarshalJSON.  It returns
+// io.EOF at the end of the stream.  Error offsets, and key offsets recorded
+// with KeyOffsets, are offsets in the stream.  An object exceeding MaxSize
+// returns a *LimitError, after which the stream cannot be decoded further.
+func (d *Decoder) Decode() (*OrderedMap, error) {
+	start := d.dec.InputOffset()
+	if d.maxSize > 0 {
+		// The object may begin in the input buffered by d.dec.
+		var buffered int64
+		if b, ok := d.dec.Buffered().(interface{ Len() int }); ok {
+			buffered = int64(b.Len())
+		}
+		d.r.n, d.r.limited = max(d.maxSize+1-buffered, 0), false
+		defer func() { d.r.n = -1 }()
+	}
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		if d.r.limited {
+			return nil, &LimitError{Limit: "size", Max: d.maxSize, Offset: start}
+		}
+		return nil, err
+	}
+	o := New(d.opts...)
+	base := d.dec.InputOffset() - int64(len(raw))
+	if err := o.UnmarshalJSON(raw); err != nil {
+		return nil, rebaseOffset(err, base)
+	}
+	o.shiftOffsets(func(off int64) int64 { return off + base })
+	return o, nil
+}
+
+// More reports whether there is another object in the stream.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// UnmarshalAppend decodes the stream of JSON objects b, such as JSON Lines, and
+// merges their keys into o in arrival order.  A key that is repeated across
+// objects keeps its first position and takes its last value.  Duplicates
+// within an object are rejected like UnmarshalJSON, and values as by TrySet.
+// On error o is not modified.  OnChange reports the merge as one OpUnmarshal.
+func (o *OrderedMap) UnmarshalAppend(b []byte) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	var docs []*OrderedMap
+	for {
+		m := &OrderedMap{opts: o.opts}
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = rebaseOffset(m.UnmarshalJSON(raw), dec.InputOffset()-int64(len(raw)))
+		}
+		if err != nil {
+			return err
+		}
+		docs = append(docs, m)
+	}
+	// Merge into a snapshot so that a rejected value leaves o unmodified.
+	merged := o.Snapshot()
+	for _, m := range docs {
+		for i, k := range m.keys {
+			if err := merged.TrySet(k, m.valueAt(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return o.replace(func() error {
+		o.keys, o.vals, o.values, o.expires = merged.keys, merged.vals, merged.values, merged.expires
+		o.offsets, o.raws, o.layout, o.shared = merged.offsets, merged.raws, merged.layout, merged.shared
+		return nil
+	})
+}
+
+// UnmarshalStream decodes the stream of JSON objects b, such as JSON Lines,
+// returning a map for each object.
+func UnmarshalStream(b []byte, opts ...Option) ([]*OrderedMap, error) {
+	d := NewDecoder(bytes.NewReader(b), opts...)
+	var maps []*OrderedMap
+	for {
+		o, err := d.Decode()
+		if err == io.EOF {
+			return maps, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		maps = append(maps, o)
+	}
+}
+
+// rebaseOffset adds base to the offset of a *DuplicateError or *LimitError err
+// of a value at offset base in a larger input, and returns err.
+func rebaseOffset(err error, base int64) error {
+	var de *DuplicateError
+	var le *LimitError
+	if errors.As(err, &de) {
+		de.Offset += base
+	} else if errors.As(err, &le) {
+		le.Offset += base
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const jsonLines = `{"id":1,"msg":"a"}
+{"id":2,"lvl":"warn"}
+{"msg":"c","id":3}
+`
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader(jsonLines))
+	var keys [][]string
+	for d.More() {
+		o, err := d.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, o.Keys())
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Error("Decode at end", err)
+	}
+	if !reflect.DeepEqual(keys, [][]string{{"id", "msg"}, {"id", "lvl"}, {"msg", "id"}}) {
+		t.Error("keys", keys)
+	}
+
+	bad := `{"a":1} {"b":1,"b":2}`
+	_, err := UnmarshalStream([]byte(bad))
+	var de *DuplicateError
+	if !errors.As(err, &de) || de.Offset != int64(strings.LastIndex(bad, `"b"`)) {
+		t.Error("duplicate offset", err)
+	}
+}
+
+func TestOrderedMap_UnmarshalAppend(t *testing.T) {
+	o := New()
+	o.Set("first", true)
+	if err := o.UnmarshalAppend([]byte(jsonLines)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o.Keys(), []string{"first", "id", "msg", "lvl"}) || o.Get("id") != 3.0 || o.Get("msg") != "c" {
+		t.Error("UnmarshalAppend", o.Keys(), o.Values())
+	}
+	if err := o.UnmarshalAppend([]byte(`{"new":1} {"x":1,"x":2}`)); !errors.Is(err, ErrJSONDuplicate) || o.Get("new") != nil {
+		t.Error("failed UnmarshalAppend modified map", err)
+	}
+	// The validator accepts "bad" when it is decoded, but not when it is
+	// merged.
+	errReject := errors.New("reject")
+	seen := 0
+	o.SetValidator(func(key string, value any) error {
+		if key == "bad" {
+			if seen++; seen > 1 {
+				return errReject
+			}
+		}
+		return nil
+	})
+	want := o.String()
+	if err := o.UnmarshalAppend([]byte(`{"id":9,"new":1} {"bad":1}`)); err != errReject || o.String() != want {
+		t.Error("rejected UnmarshalAppend modified map", err, o.String())
+	}
+}
+
+// countReader counts the bytes read from r.
+type countReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestDecoder_MaxSize(t *testing.T) {
+	big := `{"b":"` + strings.Repeat("x", 1<<20) + `"}`
+	r := &countReader{r: strings.NewReader(`{"a":1}` + "\n" + big + "\n" + `{"c":1}`)}
+	d := NewDecoder(r, WithLimits(Limits{MaxSize: 100}))
+	if o, err := d.Decode(); err != nil || o.Get("a") != 1.0 {
+		t.Fatal(o, err)
+	}
+	_, err := d.Decode()
+	var le *LimitError
+	if !errors.As(err, &le) || le.Limit != "size" || le.Offset != 7 {
+		t.Fatal("got", err)
+	}
+	if r.n > 1<<16 {
+		t.Errorf("read %d bytes of an object exceeding MaxSize", r.n)
+	}
+}
@@ -0,0 +1,85 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Table is a slice of maps treated as the rows of a table, such as for
+// exporting a JSON report to a spreadsheet.  Its columns are the union of the
+// keys of the rows in first-seen order, and cells of missing keys are empty.
+type Table []*OrderedMap
+
+// Columns returns the keys of the rows in the order first seen.
+func (t Table) Columns() []string {
+	var cols []string
+	seen := make(map[string]bool)
+	for _, row := range t {
+		for _, k := range row.Keys() {
+			if !seen[k] {
+				seen[k] = true
+				cols = append(cols, k)
+			}
+		}
+	}
+	return cols
+}
+
+// Records returns the header, of Columns, and a record of cells for each row,
+// aligned to the columns and formatted as by ToCSVRecord.
+func (t Table) Records() ([][]string, error) {
+	cols := t.Columns()
+	recs := make([][]string, 1, len(t)+1)
+	recs[0] = cols
+	for _, row := range t {
+		rec, err := row.ToCSVRecord(cols)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// WriteTSV writes t to w as tab-separated values with a header, quoting cells
+// containing tabs, quotes or newlines as spreadsheets expect.
+func (t Table) WriteTSV(w io.Writer) error {
+	return t.write(w, '\t')
+}
+
+// WriteCSV writes t to w as CSV with a header.
+func (t Table) WriteCSV(w io.Writer) error {
+	return t.write(w, ',')
+}
+
+func (t Table) write(w io.Writer, comma rune) error {
+	recs, err := t.Records()
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return cw.WriteAll(recs)
+}
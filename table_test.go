@@ -0,0 +1,59 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	var rows Table
+	for _, s := range []string{`{"id":1,"name":"a"}`, `{"id":2,"note":"x\ty","name":"b"}`, `{"extra":[1,2]}`} {
+		o := New()
+		if err := o.UnmarshalJSON([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, o)
+	}
+	if cols := rows.Columns(); !slices.Equal(cols, []string{"id", "name", "note", "extra"}) {
+		t.Errorf("Columns = %v", cols)
+	}
+
+	var b strings.Builder
+	if err := rows.WriteTSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "id\tname\tnote\textra\n1\ta\t\t\n2\tb\t\"x\ty\"\t\n\t\t\t[1,2]\n"
+	if b.String() != want {
+		t.Errorf("got  %q\nwant %q", b.String(), want)
+	}
+	b.Reset()
+	if err := rows[:1].WriteCSV(&b); err != nil || b.String() != "id,name\n1,a\n" {
+		t.Errorf("got %q, %v", b.String(), err)
+	}
+	if recs, err := Table(nil).Records(); err != nil || len(recs) != 1 || recs[0] != nil {
+		t.Errorf("empty table: %q, %v", recs, err)
+	}
+}
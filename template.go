@@ -0,0 +1,48 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// Pair is a key-value pair of an OrderedMap.
+type Pair struct {
+	Key   string
+	Value any
+}
+
+// Iter returns the key-value pairs of o in order.  It is for templates, where
+// {{range $p := .Iter}}{{$p.Key}}: {{$p.Value}}{{end}} renders o in order;
+// Go code should prefer All, which does not allocate.  Iter has a value
+// receiver so that it can be called on nested maps, which templates cannot
+// address.
+func (o OrderedMap) Iter() []Pair {
+	pairs := make([]Pair, len(o.keys))
+	for i, k := range o.keys {
+		pairs[i] = Pair{k, o.valueAt(i)}
+	}
+	return pairs
+}
+
+// At returns the pair at position pos, for templates, e.g. {{(.At 0).Key}}.
+// It panics if pos is out of range.
+func (o OrderedMap) At(pos int) Pair {
+	return Pair{o.keys[pos], o.valueAt(pos)}
+}
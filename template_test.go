@@ -0,0 +1,54 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestOrderedMap_Iter(t *testing.T) {
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"z":"<b>","a":{"y":1,"x":2}}`)); err != nil {
+		t.Fatal(err)
+	}
+	const text = `{{range .Iter}}{{.Key}}={{.Value}};{{end}} {{(.At 1).Key}} {{.Get "z"}} {{with .Get "a"}}{{range .Iter}}{{.Key}}{{end}}{{end}}`
+
+	var b strings.Builder
+	if err := template.Must(template.New("").Parse(text)).Execute(&b, o); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); !strings.HasPrefix(got, "z=<b>;a=") || !strings.HasSuffix(got, " a <b> yx") {
+		t.Errorf("got %s", got)
+	}
+
+	b.Reset()
+	if err := htmltemplate.Must(htmltemplate.New("").Parse(`{{range .Iter}}{{.Key}}={{.Value}};{{end}}`)).Execute(&b, o.Pick("z")); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "z=&lt;b&gt;;" {
+		t.Errorf("html/template got %s", b.String())
+	}
+}
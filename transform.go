@@ -0,0 +1,132 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// empty returns a new empty OrderedMap with the options of o.
+func (o *OrderedMap) empty() *OrderedMap {
+	return &OrderedMap{opts: o.opts}
+}
+
+// Partition returns the key-value pairs of o for which pred returns true in
+// match and the others in rest, both in the order of o and with its options.
+// o is not modified and values are not copied.
+func (o *OrderedMap) Partition(pred func(k string, v any) bool) (match, rest *OrderedMap) {
+	match, rest = o.empty(), o.empty()
+	for i, k := range o.keys {
+		v := o.valueAt(i)
+		if pred(k, v) {
+			match.set(k, v)
+		} else {
+			rest.set(k, v)
+		}
+	}
+	return match, rest
+}
+
+// GroupBy groups the key-value pairs of o by the group returned by f.  It
+// returns a map of groups, in order of their first pair, whose values are
+// *OrderedMap of the group's pairs in the order of o.  The maps have the
+// options of o.  o is not modified and values are not copied.
+func (o *OrderedMap) GroupBy(f func(k string, v any) string) *OrderedMap {
+	groups := o.empty()
+	for i, k := range o.keys {
+		v := o.valueAt(i)
+		name := f(k, v)
+		g, ok := groups.get(name)
+		if !ok {
+			g = o.empty()
+			groups.set(name, g)
+		}
+		g.(*OrderedMap).set(k, v)
+	}
+	return groups
+}
+
+// MapValues returns a new OrderedMap, with the options of o, of the keys of o
+// in order with values replaced by the result of f.  o is not modified.
+func (o *OrderedMap) MapValues(f func(k string, v any) any) *OrderedMap {
+	m := o.empty()
+	for i, k := range o.keys {
+		m.set(k, f(k, o.valueAt(i)))
+	}
+	return m
+}
+
+// ErrKeyCollision is returned when renaming keys would duplicate a key.
+var ErrKeyCollision = errors.New("orderedmap: key collision")
+
+// MapKeys returns a new OrderedMap, with the options of o, of the values of o
+// in order with keys replaced by the result of f.  If f maps two keys to the
+// same key, MapKeys returns an error wrapping ErrKeyCollision.  o is not
+// modified.  Nested maps are not renamed.
+func (o *OrderedMap) MapKeys(f func(k string) string) (*OrderedMap, error) {
+	m := o.empty()
+	for i, k := range o.keys {
+		nk := f(k)
+		if _, ok := m.get(nk); ok {
+			return nil, fmt.Errorf("%w: %q maps to existing key %q", ErrKeyCollision, k, nk)
+		}
+		m.set(nk, o.valueAt(i))
+	}
+	return m, nil
+}
+
+// TransformValues replaces each value of o, in order, with the result of f.
+// Unlike Set, it does not validate the values.
+func (o *OrderedMap) TransformValues(f func(k string, v any) any) {
+	for i, k := range o.keys {
+		o.set(k, f(k, o.valueAt(i)))
+	}
+}
+
+// Pick returns a new OrderedMap, with the options of o, of the given keys that
+// exist in o, in the order of o.  o is not modified.
+func (o *OrderedMap) Pick(keys ...string) *OrderedMap {
+	return o.project(keys, true)
+}
+
+// Omit returns a new OrderedMap, with the options of o, of the keys of o other
+// than the given keys, in the order of o.  o is not modified.
+func (o *OrderedMap) Omit(keys ...string) *OrderedMap {
+	return o.project(keys, false)
+}
+
+// project returns the keys of o that are, if pick, or are not in keys.
+func (o *OrderedMap) project(keys []string, pick bool) *OrderedMap {
+	in := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		in[o.mapKey(k)] = true
+	}
+	m := o.empty()
+	for i, k := range o.keys {
+		if in[o.mapKey(k)] == pick {
+			m.set(k, o.valueAt(i))
+		}
+	}
+	return m
+}
@@ -0,0 +1,105 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrderedMap_Partition(t *testing.T) {
+	o := New()
+	for i, k := range []string{"a", "B", "c", "D"} {
+		o.Set(k, i)
+	}
+	upper := func(k string, v any) bool { return strings.ToUpper(k) == k }
+	match, rest := o.Partition(upper)
+	if !reflect.DeepEqual(match.Keys(), []string{"B", "D"}) || !reflect.DeepEqual(rest.Keys(), []string{"a", "c"}) {
+		t.Error("Partition", match.Keys(), rest.Keys())
+	}
+	if match.Get("D") != 3 || o.Len() != 4 {
+		t.Error("Partition values")
+	}
+}
+
+func TestOrderedMap_GroupBy(t *testing.T) {
+	o := New()
+	for i, k := range []string{"payload.a", "header.alg", "payload.b", "header.typ"} {
+		o.Set(k, i)
+	}
+	g := o.GroupBy(func(k string, v any) string { return k[:strings.IndexByte(k, '.')] })
+	if !reflect.DeepEqual(g.Keys(), []string{"payload", "header"}) {
+		t.Fatal("GroupBy groups", g.Keys())
+	}
+	h := g.Get("header").(*OrderedMap)
+	if !reflect.DeepEqual(h.Keys(), []string{"header.alg", "header.typ"}) || h.Get("header.typ") != 3 {
+		t.Error("GroupBy group", h.Keys())
+	}
+}
+
+func TestOrderedMap_MapValuesKeys(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("B", 2)
+
+	m := o.MapValues(func(k string, v any) any { return v.(int) * 10 })
+	if !reflect.DeepEqual(m.Values(), []any{10, 20}) || o.Get("a") != 1 {
+		t.Error("MapValues", m.Values())
+	}
+
+	m, err := o.MapKeys(strings.ToUpper)
+	if err != nil || !reflect.DeepEqual(m.Keys(), []string{"A", "B"}) || m.Get("A") != 1 {
+		t.Error("MapKeys", m, err)
+	}
+	o.Set("b", 3)
+	if _, err = o.MapKeys(strings.ToLower); !errors.Is(err, ErrKeyCollision) {
+		t.Error("MapKeys collision", err)
+	}
+
+	o.TransformValues(func(k string, v any) any { return k })
+	if !reflect.DeepEqual(o.Values(), []any{"a", "B", "b"}) {
+		t.Error("TransformValues", o.Values())
+	}
+}
+
+func TestOrderedMap_PickOmit(t *testing.T) {
+	o := New()
+	for i, k := range []string{"alg", "iat", "tmb", "sig"} {
+		o.Set(k, i)
+	}
+	if p := o.Pick("tmb", "alg", "missing"); !reflect.DeepEqual(p.Keys(), []string{"alg", "tmb"}) || p.Get("tmb") != 2 {
+		t.Error("Pick", p.Keys())
+	}
+	if p := o.Omit("sig", "iat"); !reflect.DeepEqual(p.Keys(), []string{"alg", "tmb"}) {
+		t.Error("Omit", p.Keys())
+	}
+
+	f := New(CaseInsensitive())
+	f.Set("Alg", 1)
+	f.Set("Sig", 2)
+	if p := f.Pick("alg"); !reflect.DeepEqual(p.Keys(), []string{"Alg"}) {
+		t.Error("case-insensitive Pick", p.Keys())
+	}
+}
@@ -0,0 +1,104 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import "time"
+
+// now is the clock of expiring entries, replaced by tests.
+var now = time.Now
+
+// SetWithTTL sets the value for key like Set, and deletes key once d has
+// elapsed.  Expired keys are deleted lazily, when looked up by Get or Entry,
+// or by DeleteExpired; until then they remain in the order and are visible to
+// other methods, including MarshalJSON.  Get and Lookup of a ReadOnly view
+// report expired keys missing without deleting them.  Setting key again with
+// Set removes its expiry.
+func (o *OrderedMap) SetWithTTL(key string, value any, d time.Duration) error {
+	if err := o.TrySet(key, value); err != nil {
+		return err
+	}
+	if o.expires == nil {
+		o.expires = make(map[string]time.Time)
+	}
+	o.expires[o.mapKey(key)] = now().Add(d)
+	return nil
+}
+
+// TTL returns the time remaining until key expires, and false if key has no
+// expiry.
+func (o *OrderedMap) TTL(key string) (time.Duration, bool) {
+	t, ok := o.expires[o.mapKey(key)]
+	if !ok {
+		return 0, false
+	}
+	return t.Sub(now()), true
+}
+
+// DeleteExpired deletes all expired keys and returns the number deleted.  Call
+// it periodically to release expired entries that are not looked up.
+func (o *OrderedMap) DeleteExpired() int {
+	if len(o.expires) == 0 || o.frozen {
+		return 0
+	}
+	t := now()
+	n := 0
+	for i := 0; i < len(o.keys); {
+		if e, ok := o.expires[o.mapKey(o.keys[i])]; ok && !t.Before(e) {
+			o.Delete(o.keys[i])
+			n++
+			continue
+		}
+		i++
+	}
+	return n
+}
+
+// forget deletes the expiry, raw input and cached encoding of key, which is
+// being set or deleted.
+func (o *OrderedMap) forget(key string) {
+	if o.expires != nil {
+		delete(o.expires, o.mapKey(key))
+	}
+	if o.raws != nil {
+		delete(o.raws, o.mapKey(key))
+	} else if o.opts != nil && o.opts.cache {
+		// Created here as MarshalJSON cannot set o.raws.
+		o.raws = make(map[string]span)
+	}
+}
+
+// expire deletes key if it has expired.
+func (o *OrderedMap) expire(key string) {
+	if !o.frozen && o.expired(key) {
+		o.Delete(key)
+	}
+}
+
+// expired reports whether key has expired.
+func (o *OrderedMap) expired(key string) bool {
+	if o.expires == nil {
+		return false
+	}
+	e, ok := o.expires[o.mapKey(key)]
+	return ok && !now().Before(e)
+}
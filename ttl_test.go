@@ -0,0 +1,62 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrderedMap_SetWithTTL(t *testing.T) {
+	clock := time.Unix(0, 0)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	o := New()
+	o.Set("a", 1)
+	o.SetWithTTL("b", 2, time.Second)
+	o.SetWithTTL("c", 3, time.Minute)
+	o.SetWithTTL("d", 4, time.Second)
+	o.Set("d", 40) // removes the expiry
+	if d, ok := o.TTL("b"); !ok || d != time.Second {
+		t.Error("TTL", d, ok)
+	}
+
+	clock = clock.Add(time.Second)
+	if o.Len() != 4 {
+		t.Error("expired before lookup", o.Keys())
+	}
+	if o.Get("b") != nil || !reflect.DeepEqual(o.Keys(), []string{"a", "c", "d"}) {
+		t.Error("Get of expired key", o.Keys())
+	}
+
+	o.SetWithTTL("e", 5, time.Second)
+	clock = clock.Add(time.Hour)
+	if n := o.DeleteExpired(); n != 2 || !reflect.DeepEqual(o.Keys(), []string{"a", "d"}) {
+		t.Error("DeleteExpired", n, o.Keys())
+	}
+	if _, ok := o.TTL("d"); ok {
+		t.Error("Set did not remove expiry")
+	}
+}
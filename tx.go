@@ -0,0 +1,89 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+// Tx modifies an OrderedMap within Batch.
+type Tx struct {
+	m *OrderedMap
+}
+
+// Get returns the value for key, including changes made by the Tx.
+func (tx *Tx) Get(key string) any {
+	return tx.m.Get(key)
+}
+
+// Set is OrderedMap.Set within the Tx.
+func (tx *Tx) Set(key string, value any) {
+	tx.m.Set(key, value)
+}
+
+// TrySet is OrderedMap.TrySet within the Tx.
+func (tx *Tx) TrySet(key string, value any) error {
+	return tx.m.TrySet(key, value)
+}
+
+// Delete is OrderedMap.Delete within the Tx.
+func (tx *Tx) Delete(key string) {
+	tx.m.Delete(key)
+}
+
+// Move is OrderedMap.Move within the Tx.
+func (tx *Tx) Move(key string, pos int) error {
+	return tx.m.Move(key, pos)
+}
+
+// Len returns the number of keys, including changes made by the Tx.
+func (tx *Tx) Len() int {
+	return tx.m.Len()
+}
+
+// Batch calls f with a Tx and applies the changes it makes to o only if f
+// returns nil, so that o is either fully updated or unchanged.  If f returns
+// an error or panics, o is not modified.  The Tx is a copy-on-write Snapshot
+// of o, so a batch copies o's storage once.  An OnChange func is called for
+// each change after they are applied, in order, and History records the batch
+// as a single change.  The Tx must not be used after f returns.
+func (o *OrderedMap) Batch(f func(tx *Tx) error) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	w := o.Snapshot()
+	var changes []Change
+	if o.onChange != nil {
+		w.onChange = func(op Op, key string, old, new any) {
+			changes = append(changes, Change{Op: op, Key: key, Old: old, New: new})
+		}
+	}
+	if err := f(&Tx{w}); err != nil {
+		return err
+	}
+	prev := &state{o.keys, o.vals, o.values, o.expires, o.raws, o.layout}
+	o.keys, o.vals, o.values, o.expires, o.raws, o.shared = w.keys, w.vals, w.values, w.expires, w.raws, w.shared
+	if o.history != nil {
+		o.history.record(Change{Op: OpBatch, prev: prev})
+	}
+	for _, c := range changes {
+		o.onChange(c.Op, c.Key, c.Old, c.New)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_Move(t *testing.T) {
+	for _, n := range []int{5, smallSize * 2} {
+		o := New()
+		var want []string
+		index := make(map[string]int)
+		for i := 0; i < n; i++ {
+			k := string(rune('a' + i))
+			o.Set(k, i)
+			want = append(want, k)
+			index[k] = i
+		}
+		o.Move("a", n-1)
+		o.Move("c", 0)
+		want = append(append([]string{"c", "b"}, want[3:]...), "a")
+		if !reflect.DeepEqual(o.Keys(), want) {
+			t.Errorf("n %d: Keys %v, want %v", n, o.Keys(), want)
+		}
+		for i, k := range o.Keys() {
+			if o.GetValueAt(i) != index[k] {
+				t.Errorf("n %d: value of %s misaligned", n, k)
+			}
+		}
+		if err := o.Move("missing", 0); !errors.Is(err, ErrKeyNotFound) {
+			t.Error("Move of missing key", err)
+		}
+	}
+}
+
+func TestOrderedMap_Batch(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	var ops []Op
+	o.OnChange(func(op Op, key string, old, new any) { ops = append(ops, op) })
+
+	errAbort := errors.New("abort")
+	err := o.Batch(func(tx *Tx) error {
+		tx.Set("c", 3)
+		tx.Delete("a")
+		if tx.Len() != 2 || tx.Get("c") != 3 {
+			t.Error("Tx does not see its changes")
+		}
+		return errAbort
+	})
+	if err != errAbort || !reflect.DeepEqual(o.Keys(), []string{"a", "b"}) || len(ops) != 0 {
+		t.Error("aborted Batch modified map", err, o.Keys(), ops)
+	}
+
+	err = o.Batch(func(tx *Tx) error {
+		tx.Set("c", 3)
+		tx.Delete("a")
+		return tx.Move("c", 0)
+	})
+	if err != nil || !reflect.DeepEqual(o.Keys(), []string{"c", "b"}) || o.Get("c") != 3 {
+		t.Error("Batch", err, o.Keys())
+	}
+	if !reflect.DeepEqual(ops, []Op{OpInsert, OpDelete, OpMove}) {
+		t.Error("Batch OnChange", ops)
+	}
+
+	func() {
+		defer func() { recover() }()
+		o.Batch(func(tx *Tx) error {
+			tx.Set("d", 4)
+			panic("boom")
+		})
+	}()
+	if o.Get("d") != nil {
+		t.Error("panicking Batch modified map")
+	}
+}
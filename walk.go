@@ -0,0 +1,132 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"strconv"
+)
+
+// SkipChildren may be returned by a Walk func to not visit the children of the
+// value.
+var SkipChildren = errors.New("orderedmap: skip children")
+
+// SkipAll may be returned by a Walk func to stop the walk, which returns nil.
+var SkipAll = errors.New("orderedmap: skip all")
+
+// replacement is the error returned by Replace.
+type replacement struct {
+	value any
+}
+
+func (r *replacement) Error() string {
+	return "orderedmap: replace value"
+}
+
+// Replace returns an error that, returned by a Walk func, replaces the value
+// with v.  The children of v are not visited.
+func Replace(v any) error {
+	return &replacement{v}
+}
+
+// Walk calls f for each value of o and, recursively, of nested OrderedMaps,
+// *OrderedMaps, []any and OrderedArrays, in order, parents before children.
+// path is the keys of the parents of the value, and key its key, which for
+// elements of a slice is the decimal index.  path must not be retained by f.
+//
+// If f returns SkipChildren, the children of the value are not visited.  If it
+// returns the result of Replace, the value is replaced.  If it returns SkipAll
+// Walk stops and returns nil, and if it returns any other error Walk stops and
+// returns the error.  A replacement is set as by TrySet, so that Walk stops and
+// returns the error of a validator rejecting it, or ErrFrozen.
+func (o *OrderedMap) Walk(f func(path []string, key string, value any) error) error {
+	_, err := walkMap(o, nil, f)
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkMap walks the values of o and reports whether any value of o was
+// replaced.
+func walkMap(o *OrderedMap, path []string, f func(path []string, key string, value any) error) (bool, error) {
+	changed := false
+	for i := 0; i < len(o.keys); i++ {
+		k := o.keys[i]
+		v, replaced, err := walkValue(path, k, o.valueAt(i), f)
+		if replaced {
+			if err := o.store(k, v); err != nil {
+				return changed, err
+			}
+			changed = true
+		}
+		if err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
+
+// walkValue calls f for v and walks its children, and returns the value with
+// which v is to be replaced, if replaced is true.
+func walkValue(path []string, key string, v any, f func(path []string, key string, value any) error) (_ any, replaced bool, _ error) {
+	err := f(path, key, v)
+	var r *replacement
+	switch {
+	case errors.As(err, &r):
+		return r.value, true, nil
+	case err == SkipChildren:
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+	path = append(path, key)
+	switch t := v.(type) {
+	case OrderedMap:
+		// t shares storage with the parent's value, but a replaced value is
+		// stored back in case t was copied on write.
+		changed, err := walkMap(&t, path, f)
+		return t, changed, err
+	case *OrderedMap:
+		if t != nil {
+			_, err = walkMap(t, path, f)
+		}
+	case []any, OrderedArray:
+		// Elements are replaced in place, but the slice is reported replaced
+		// so that the parent discards any KeepRaw input of it.
+		s, _ := asSlice(t)
+		changed := false
+		for i := range s {
+			nv, replaced, err := walkValue(path, strconv.Itoa(i), s[i], f)
+			if replaced {
+				s[i] = nv
+				changed = true
+			}
+			if err != nil {
+				return t, changed, err
+			}
+		}
+		return t, changed, nil
+	}
+	return nil, false, err
+}
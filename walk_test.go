@@ -0,0 +1,111 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrderedMap_Walk(t *testing.T) {
+	o := New()
+	err := o.UnmarshalJSON([]byte(`{"a":1,"secret":"x","b":{"c":[{"secret":"y"},2],"skip":{"secret":"z"}},"d":4}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var visited []string
+	err = o.Walk(func(path []string, key string, value any) error {
+		visited = append(visited, strings.Join(append(path, key), "."))
+		switch {
+		case key == "secret":
+			return Replace("REDACTED")
+		case key == "skip":
+			return SkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "secret", "b", "b.c", "b.c.0", "b.c.0.secret", "b.c.1", "b.skip", "d"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited %v\nwant %v", visited, want)
+	}
+	b, _ := o.MarshalJSON()
+	if string(b) != `{"a":1,"secret":"REDACTED","b":{"c":[{"secret":"REDACTED"},2],"skip":{"secret":"z"}},"d":4}` {
+		t.Error("Replace", string(b))
+	}
+
+	n := 0
+	err = o.Walk(func(path []string, key string, value any) error {
+		if n++; key == "b" {
+			return SkipAll
+		}
+		return nil
+	})
+	if err != nil || n != 3 {
+		t.Error("SkipAll", err, n)
+	}
+	errStop := errors.New("stop")
+	if err = o.Walk(func([]string, string, any) error { return errStop }); err != errStop {
+		t.Error("error", err)
+	}
+}
+
+func TestOrderedMap_Walk_Rejected(t *testing.T) {
+	replace := func(path []string, key string, value any) error {
+		if key == "b" {
+			return Replace("x")
+		}
+		return nil
+	}
+	o := New()
+	if err := o.UnmarshalJSON([]byte(`{"a":{"b":1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	o.Freeze()
+	if err := o.Walk(replace); !errors.Is(err, ErrFrozen) {
+		t.Errorf("got %v, want ErrFrozen", err)
+	}
+
+	errInvalid := errors.New("invalid")
+	o = New()
+	o.SetValidator(func(key string, value any) error {
+		if value == "x" {
+			return errInvalid
+		}
+		return nil
+	})
+	if err := o.UnmarshalJSON([]byte(`{"a":{"b":1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Walk(replace); !errors.Is(err, errInvalid) {
+		t.Errorf("got %v, want validator error", err)
+	}
+	if s := o.String(); s != `{"a":{"b":1}}` {
+		t.Errorf("got %s", s)
+	}
+}
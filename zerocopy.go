@@ -0,0 +1,200 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// ZeroCopy makes UnmarshalJSON return keys and string values, at any depth,
+// that reference the input instead of copies of it, where the JSON string has
+// no escapes.  This saves allocating and copying them, for transient read-only
+// processing such as of a request body.
+//
+// The caller owns the input and must not modify it while o, its nested maps,
+// or any key or string obtained from them is in use, since that would change
+// strings Go otherwise guarantees are immutable.  Any such string keeps all
+// of the input in memory.  json.Unmarshal passes its own input, and the
+// Decoder and json/v2 methods pass copies.
+//
+// ZeroCopy does not apply with RegisterType, Lazy, KeyOffsets, KeepRaw or
+// Faithful, nor to UnmarshalJSONKeys.
+func ZeroCopy() Option {
+	return func(opts *options) { opts.zeroCopy = true }
+}
+
+// decodesZeroCopy reports whether o decodes with ZeroCopy.
+func (o *OrderedMap) decodesZeroCopy(only map[string]bool) bool {
+	return only == nil && o.opts != nil && o.opts.zeroCopy && o.opts.types == nil &&
+		!o.opts.lazy && !o.opts.offsets && !o.opts.raw
+}
+
+// unmarshalZeroCopy is unmarshalStrict of valid JSON b with ZeroCopy.  Unlike
+// encoding/json it decodes in a single pass, as scanner checks duplicates and
+// limits through a treeBuilder.
+func (o *OrderedMap) unmarshalZeroCopy(b []byte) error {
+	s := &scanner{b: b}
+	s.skip()
+	switch b[s.i] {
+	case 'n':
+		// By convention, null is a no-op.
+		s.i += len("null")
+	case '{':
+		t := &treeBuilder{root: o, limits: o.opts.limits}
+		t.offset = func() int64 { return int64(s.tok) }
+		if err := o.replace(func() error { return s.value(t) }); err != nil {
+			return err
+		}
+	default:
+		return errExpectedObject
+	}
+	if s.skip(); s.i < len(b) {
+		return fmt.Errorf("orderedmap: invalid data after top-level JSON object")
+	}
+	return nil
+}
+
+// scanner decodes valid JSON, referencing the strings of b.  Since b is valid,
+// it only looks at the first byte of most tokens.
+type scanner struct {
+	b []byte
+	i int
+	// tok is the offset of the last token.
+	tok int
+}
+
+func (s *scanner) skip() {
+	for s.i < len(s.b) && isSpace(s.b[s.i]) {
+		s.i++
+	}
+}
+
+// value decodes the value at s.i to t.
+func (s *scanner) value(t *treeBuilder) error {
+	s.skip()
+	s.tok = s.i
+	switch s.b[s.i] {
+	case '{':
+		s.i++
+		if err := t.BeginObject(); err != nil {
+			return err
+		}
+		for {
+			s.skip()
+			s.tok = s.i
+			switch s.b[s.i] {
+			case '}':
+				s.i++
+				return t.EndObject()
+			case ',':
+				s.i++
+				s.skip()
+				s.tok = s.i
+			}
+			key, err := s.string()
+			if err != nil {
+				return err
+			}
+			s.skip()
+			s.i++ // ':'
+			if err := t.Key(key); err != nil {
+				return err
+			}
+			if err := s.value(t); err != nil {
+				return err
+			}
+		}
+	case '[':
+		s.i++
+		if err := t.BeginArray(); err != nil {
+			return err
+		}
+		for {
+			s.skip()
+			s.tok = s.i
+			switch s.b[s.i] {
+			case ']':
+				s.i++
+				return t.EndArray()
+			case ',':
+				s.i++
+			}
+			if err := s.value(t); err != nil {
+				return err
+			}
+		}
+	case '"':
+		v, err := s.string()
+		if err != nil {
+			return err
+		}
+		return t.Value(v)
+	case 't':
+		s.i += len("true")
+		return t.Value(true)
+	case 'f':
+		s.i += len("false")
+		return t.Value(false)
+	case 'n':
+		s.i += len("null")
+		return t.Value(nil)
+	}
+	j := s.i
+	for j < len(s.b) && (s.b[j] >= '0' && s.b[j] <= '9' || s.b[j] == '-' || s.b[j] == '+' || s.b[j] == '.' || s.b[j] == 'e' || s.b[j] == 'E') {
+		j++
+	}
+	f, err := strconv.ParseFloat(unsafe.String(&s.b[s.i], j-s.i), 64)
+	if err != nil {
+		return err
+	}
+	s.i = j
+	return t.Value(f)
+}
+
+// string decodes the string at s.i.  A string without escapes that is valid
+// UTF-8 references s.b, and others are decoded like encoding/json.
+func (s *scanner) string() (string, error) {
+	start := s.i
+	escaped := false
+	for s.i++; s.b[s.i] != '"'; s.i++ {
+		if s.b[s.i] == '\\' {
+			escaped = true
+			s.i++
+		}
+	}
+	s.i++
+	str := s.b[start+1 : s.i-1]
+	if !escaped && utf8.Valid(str) {
+		if len(str) == 0 {
+			return "", nil
+		}
+		return unsafe.String(&str[0], len(str)), nil
+	}
+	var v string
+	err := json.Unmarshal(s.b[start:s.i], &v)
+	return v, err
+}
@@ -0,0 +1,86 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2023 Cypherpunk LLC and contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, Subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or Substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package orderedmap
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+)
+
+var zeroCopyInput = []byte(`{"id":"0123456789","name":"éé","tags":["a","b\n",""],"n":-1.5e3,"ok":true,"nil":null,"m":{"k":"v","l":[{}]}}`)
+
+func TestZeroCopy(t *testing.T) {
+	want := New()
+	if err := want.UnmarshalJSON(zeroCopyInput); err != nil {
+		t.Fatal(err)
+	}
+	in := append([]byte(nil), zeroCopyInput...)
+	o := New(ZeroCopy())
+	if err := o.UnmarshalJSON(in); err != nil {
+		t.Fatal(err)
+	}
+	if !o.EqualOrdered(want) {
+		t.Fatalf("got %v, want %v", o, want)
+	}
+	if p := unsafe.StringData(o.Get("id").(string)); p != &in[7] {
+		t.Error("string value was copied")
+	}
+	if p := unsafe.StringData(o.Keys()[0]); p != &in[2] {
+		t.Error("key was copied")
+	}
+	in[7] = 'x'
+	if o.Get("id") != "x123456789" {
+		t.Errorf("got %v, want value referencing input", o.Get("id"))
+	}
+
+	var de *DuplicateError
+	if err := o.UnmarshalJSON([]byte(`{"a":1, "a":2}`)); !errors.As(err, &de) || de.Offset != 8 {
+		t.Errorf("got %v", err)
+	}
+	for _, in := range []string{`{"a":}`, `[1]`, `{} {}`} {
+		if err := o.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("%s: no error", in)
+		}
+	}
+	if err := o.UnmarshalJSON([]byte(` null `)); err != nil {
+		t.Error(err)
+	}
+	m := New(ZeroCopy(), WithLimits(Limits{MaxMemory: 100}))
+	if err := m.UnmarshalJSON(zeroCopyInput); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func BenchmarkZeroCopy(b *testing.B) {
+	for name, opts := range map[string][]Option{"copy": nil, "zerocopy": {ZeroCopy()}} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if err := New(opts...).UnmarshalJSON(zeroCopyInput); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}